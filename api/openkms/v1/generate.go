@@ -0,0 +1,4 @@
+// Package openkmsv1 holds the gRPC API of the KMS, generated from key_management.proto.
+package openkmsv1
+
+//go:generate protoc --proto_path=../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative openkms/v1/key_management.proto
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+// commands - command tree of the CLI.
+func commands() []*command {
+	return []*command{
+		{
+			name:    "daemon",
+			summary: "inspect the daemon",
+			subcommands: []*command{
+				{name: "status", summary: "show the daemon's status", flags: call0(cliapi.METHOD_DAEMON_STATUS, &cliapi.StatusResult{})},
+			},
+		},
+		{
+			name:    "operator",
+			summary: "seal and unseal the key material",
+			subcommands: []*command{
+				{name: "init", summary: "generate the root key and its unseal shares", flags: operatorInit},
+				{name: "unseal", summary: "submit an unseal share, - reads it from standard input", flags: operatorUnseal},
+				{name: "seal", summary: "forget the root key until the next unseal", flags: call0(cliapi.METHOD_OPERATOR_SEAL, &cliapi.SealStatusResult{})},
+				{name: "status", summary: "show the seal status", flags: call0(cliapi.METHOD_OPERATOR_SEAL_STATUS, &cliapi.SealStatusResult{})},
+			},
+		},
+		{
+			name:    "keys",
+			summary: "manage keys",
+			subcommands: []*command{
+				{name: "create", summary: "create a key", flags: keysCreate},
+				{name: "list", summary: "list keys", flags: call0(cliapi.METHOD_KEYS_LIST, &cliapi.KeyListResult{})},
+				{name: "describe", summary: "describe a key", flags: callKeyID(cliapi.METHOD_KEYS_DESCRIBE)},
+				{name: "rotate", summary: "rotate a key to a new version", flags: callKeyID(cliapi.METHOD_KEYS_ROTATE)},
+				{name: "enable", summary: "enable a key", flags: callKeyID(cliapi.METHOD_KEYS_ENABLE)},
+				{name: "disable", summary: "disable a key", flags: callKeyID(cliapi.METHOD_KEYS_DISABLE)},
+				{name: "schedule-deletion", summary: "schedule the destruction of a key", flags: keysScheduleDeletion},
+				{name: "cancel-deletion", summary: "cancel the scheduled destruction of a key", flags: callKeyID(cliapi.METHOD_KEYS_CANCEL_DELETION)},
+			},
+		},
+		{
+			name:    "aliases",
+			summary: "manage key aliases",
+			subcommands: []*command{
+				{name: "list", summary: "list aliases", flags: call0(cliapi.METHOD_ALIASES_LIST, &cliapi.AliasListResult{})},
+				{name: "create", summary: "point a new alias at a key", flags: aliasesSet(cliapi.METHOD_ALIASES_CREATE)},
+				{name: "update", summary: "point an alias at another key", flags: aliasesSet(cliapi.METHOD_ALIASES_UPDATE)},
+				{name: "delete", summary: "delete an alias", flags: aliasesDelete},
+			},
+		},
+		{name: "encrypt", summary: "encrypt data with a key", flags: encrypt},
+		{name: "decrypt", summary: "decrypt data encrypted by the KMS", flags: decrypt},
+		{name: "encrypt-file", summary: "encrypt a file locally under a data key", flags: encryptFile},
+		{name: "decrypt-file", summary: "decrypt a file produced by encrypt-file", flags: decryptFile},
+		{
+			name:    "audit",
+			summary: "read the audit log",
+			subcommands: []*command{
+				{name: "tail", summary: "show the latest audit events", flags: auditTail},
+			},
+		},
+	}
+}
+
+// call0 - command calling a method without parameters.
+func call0(method string, result any) func(*flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	return func(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+		return func(client *cliapi.Client, arguments []string) (any, error) {
+			if err := requireArguments(arguments); err != nil {
+				return nil, err
+			}
+			return result, client.Call(method, nil, result)
+		}
+	}
+}
+
+// callKeyID - command calling a method on the key given as its only argument.
+func callKeyID(method string) func(*flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	return func(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+		return func(client *cliapi.Client, arguments []string) (any, error) {
+			if err := requireArguments(arguments, "key-id"); err != nil {
+				return nil, err
+			}
+			result := &cliapi.KeyDescription{}
+			return result, client.Call(method, cliapi.KeyIDParams{KeyID: arguments[0]}, result)
+		}
+	}
+}
+
+func operatorInit(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.OperatorInitParams{}
+	flagSet.IntVar(&params.Shares, "shares", 5, "number of unseal shares to generate")
+	flagSet.IntVar(&params.Threshold, "threshold", 3, "number of unseal shares required to unseal")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments); err != nil {
+			return nil, err
+		}
+		result := &cliapi.OperatorInitResult{}
+		return result, client.Call(cliapi.METHOD_OPERATOR_INIT, params, result)
+	}
+}
+
+func operatorUnseal(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments, "share"); err != nil {
+			return nil, err
+		}
+
+		// Reading the share from standard input keeps it out of the shell history.
+		//
+		encoded := arguments[0]
+		if encoded == "-" {
+			content, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return nil, err
+			}
+			encoded = strings.TrimSpace(string(content))
+		}
+		share, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: the share must be base64 encoded", errUsage)
+		}
+
+		result := &cliapi.SealStatusResult{}
+		return result, client.Call(cliapi.METHOD_OPERATOR_UNSEAL, cliapi.OperatorUnsealParams{Share: share}, result)
+	}
+}
+
+func keysCreate(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.KeyCreateParams{Tags: map[string]string{}}
+	flagSet.StringVar(&params.Spec, "spec", kms.KEY_SPEC_SYMMETRIC_DEFAULT, "key spec")
+	flagSet.StringVar(&params.Usage, "usage", "", "key usage, defaults to the spec's default usage")
+	flagSet.StringVar(&params.Provider, "provider", "", "key provider holding the key material, software or pkcs11, defaults to the daemon's default provider")
+	flagSet.StringVar(&params.Description, "description", "", "description of the key")
+	flagSet.Var(keyValues(params.Tags), "tag", "tag as key=value, repeatable")
+	flagSet.IntVar(&params.RotationPeriodDays, "rotation-period-days", 0, "rotate the key automatically every given number of days")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments); err != nil {
+			return nil, err
+		}
+		result := &cliapi.KeyDescription{}
+		return result, client.Call(cliapi.METHOD_KEYS_CREATE, params, result)
+	}
+}
+
+func keysScheduleDeletion(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.KeyScheduleDeletionParams{}
+	flagSet.IntVar(&params.PendingWindowDays, "pending-window-days", int(kms.DEFAULT_PENDING_WINDOW.Hours()/24), "days before the key is destroyed")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments, "key-id"); err != nil {
+			return nil, err
+		}
+		params.KeyID = arguments[0]
+		result := &cliapi.KeyDescription{}
+		return result, client.Call(cliapi.METHOD_KEYS_SCHEDULE_DELETION, params, result)
+	}
+}
+
+// aliasesSet - command pointing an alias at a key.
+func aliasesSet(method string) func(*flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	return func(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+		return func(client *cliapi.Client, arguments []string) (any, error) {
+			if err := requireArguments(arguments, "alias", "key-id"); err != nil {
+				return nil, err
+			}
+			result := &cliapi.AliasDescription{}
+			return result, client.Call(method, cliapi.AliasParams{Name: arguments[0], KeyID: arguments[1]}, result)
+		}
+	}
+}
+
+func aliasesDelete(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments, "alias"); err != nil {
+			return nil, err
+		}
+		return nil, client.Call(cliapi.METHOD_ALIASES_DELETE, cliapi.AliasParams{Name: arguments[0]}, nil)
+	}
+}
+
+func encrypt(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.EncryptParams{EncryptionContext: map[string]string{}}
+	plaintext := flagSet.String("plaintext", "", "plaintext to encrypt")
+	in := flagSet.String("in", "", "file to encrypt, - for standard input")
+	out := flagSet.String("out", "", "file to write the binary ciphertext to instead of printing it")
+	flagSet.Var(keyValues(params.EncryptionContext), "context", "encryption context entry as key=value, repeatable")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments, "key-id"); err != nil {
+			return nil, err
+		}
+		params.KeyID = arguments[0]
+
+		var err error
+		params.Plaintext, err = readInput(*plaintext, *in, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &cliapi.EncryptResult{}
+		if err := client.Call(cliapi.METHOD_ENCRYPT, params, result); err != nil {
+			return nil, err
+		}
+
+		if *out != "" {
+			if err := os.WriteFile(*out, result.Ciphertext, 0600); err != nil {
+				return nil, err
+			}
+			result.Ciphertext = nil
+		}
+		return result, nil
+	}
+}
+
+func decrypt(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.DecryptParams{EncryptionContext: map[string]string{}}
+	ciphertext := flagSet.String("ciphertext", "", "base64 encoded ciphertext to decrypt")
+	in := flagSet.String("in", "", "file holding the binary ciphertext, - for standard input")
+	out := flagSet.String("out", "", "file to write the plaintext to instead of printing it")
+	flagSet.Var(keyValues(params.EncryptionContext), "context", "encryption context entry as key=value, repeatable")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments); err != nil {
+			return nil, err
+		}
+
+		var err error
+		params.Ciphertext, err = readInput(*ciphertext, *in, base64.StdEncoding.DecodeString)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &cliapi.DecryptResult{}
+		if err := client.Call(cliapi.METHOD_DECRYPT, params, result); err != nil {
+			return nil, err
+		}
+
+		if *out != "" {
+			if err := os.WriteFile(*out, result.Plaintext, 0600); err != nil {
+				return nil, err
+			}
+			result.Plaintext = nil
+		}
+		return result, nil
+	}
+}
+
+func auditTail(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.AuditTailParams{}
+	flagSet.IntVar(&params.Lines, "lines", 20, "number of events to show")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments); err != nil {
+			return nil, err
+		}
+		result := &cliapi.AuditTailResult{}
+		return result, client.Call(cliapi.METHOD_AUDIT_TAIL, params, result)
+	}
+}
+
+// requireArguments - makes sure exactly the named positional arguments were given.
+func requireArguments(arguments []string, names ...string) error {
+	if len(arguments) != len(names) {
+		placeholders := make([]string, 0, len(names))
+		for _, name := range names {
+			placeholders = append(placeholders, "<"+name+">")
+		}
+		return fmt.Errorf("%w: expected arguments %s, got %d", errUsage, strings.Join(placeholders, " "), len(arguments))
+	}
+	return nil
+}
+
+// readInput - reads the input of a command from a flag value or from a file, exactly one of which must be set. The
+// flag value is decoded when a decoder is given.
+func readInput(value, path string, decode func(string) ([]byte, error)) ([]byte, error) {
+	switch {
+	case value != "" && path != "":
+		return nil, fmt.Errorf("%w: the input must be given either inline or with --in, not both", errUsage)
+	case path == "-":
+		return io.ReadAll(os.Stdin)
+	case path != "":
+		return os.ReadFile(path)
+	case value == "":
+		return nil, fmt.Errorf("%w: no input given", errUsage)
+	case decode != nil:
+		return decode(value)
+	}
+	return []byte(value), nil
+}
+
+// keyValues - repeatable flag collecting key=value pairs.
+type keyValues map[string]string
+
+func (kV keyValues) String() string {
+	pairs := make([]string, 0, len(kV))
+	for key, value := range kV {
+		pairs = append(pairs, key+"="+value)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (kV keyValues) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", pair)
+	}
+	kV[key] = value
+	return nil
+}
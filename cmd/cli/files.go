@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/stream"
+)
+
+// fileResult - outcome of a file encryption or decryption.
+type fileResult struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Input      string `json:"input"`
+	Output     string `json:"output"`
+	Bytes      int64  `json:"bytes"` // plaintext bytes processed.
+}
+
+// encryptFile - encrypts a file locally with a data key generated once by the daemon, so the file itself never goes
+// over the socket.
+func encryptFile(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.GenerateDataKeyParams{Spec: kms.DATA_KEY_SPEC_AES_256, EncryptionContext: map[string]string{}}
+	flagSet.StringVar(&params.KeyID, "key", "", "key ID or alias protecting the file's data key")
+	flagSet.Var(keyValues(params.EncryptionContext), "context", "encryption context entry as key=value, repeatable")
+	chunkSize := flagSet.Int("chunk-size", stream.DEFAULT_CHUNK_SIZE, "plaintext bytes per authenticated chunk")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments, "in", "out"); err != nil {
+			return nil, err
+		}
+		if params.KeyID == "" {
+			return nil, fmt.Errorf("%w: --key is required", errUsage)
+		}
+
+		dataKey := &cliapi.GenerateDataKeyResult{}
+		if err := client.Call(cliapi.METHOD_GENERATE_DATA_KEY, params, dataKey); err != nil {
+			return nil, err
+		}
+		defer clear(dataKey.Plaintext)
+
+		var written int64
+		err := transformFile(arguments[0], arguments[1], func(in io.Reader, out io.Writer) error {
+			writer, err := stream.NewWriter(out, dataKey.Plaintext, dataKey.Ciphertext, *chunkSize)
+			if err != nil {
+				return err
+			}
+			if written, err = io.Copy(writer, in); err != nil {
+				return err
+			}
+			return writer.Close()
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return fileOutput(arguments, fileResult{KeyID: dataKey.KeyID, KeyVersion: dataKey.KeyVersion, Bytes: written}), nil
+	}
+}
+
+// decryptFile - decrypts a file produced by encrypt-file, asking the daemon only for its data key.
+func decryptFile(flagSet *flag.FlagSet) func(*cliapi.Client, []string) (any, error) {
+	params := cliapi.DecryptParams{EncryptionContext: map[string]string{}}
+	flagSet.Var(keyValues(params.EncryptionContext), "context", "encryption context entry as key=value, repeatable")
+
+	return func(client *cliapi.Client, arguments []string) (any, error) {
+		if err := requireArguments(arguments, "in", "out"); err != nil {
+			return nil, err
+		}
+
+		dataKey := &cliapi.DecryptResult{}
+		var written int64
+		err := transformFile(arguments[0], arguments[1], func(in io.Reader, out io.Writer) error {
+			header, err := stream.ReadHeader(in)
+			if err != nil {
+				return err
+			}
+
+			params.Ciphertext = header.EncryptedDataKey
+			if err := client.Call(cliapi.METHOD_DECRYPT, params, dataKey); err != nil {
+				return err
+			}
+			defer clear(dataKey.Plaintext)
+
+			reader, err := stream.NewReader(in, header, dataKey.Plaintext)
+			if err != nil {
+				return err
+			}
+			written, err = io.Copy(out, reader)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return fileOutput(arguments, fileResult{KeyID: dataKey.KeyID, KeyVersion: dataKey.KeyVersion, Bytes: written}), nil
+	}
+}
+
+// transformFile - streams the input file through the transformation into the output file, - standing for standard
+// input and output. A file output is written next to its destination and only renamed into place once the
+// transformation succeeded, so a failure never leaves partial output behind.
+func transformFile(inPath, outPath string, transform func(in io.Reader, out io.Writer) error) error {
+	in := io.Reader(os.Stdin)
+	if inPath != "-" {
+		file, err := os.Open(inPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	if outPath == "-" {
+		return transform(in, os.Stdout)
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(outPath), "."+filepath.Base(outPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	if err := transform(in, out); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), outPath)
+}
+
+// fileOutput - result to render once a file was processed. Nothing is rendered when the file went to standard output.
+func fileOutput(arguments []string, result fileResult) any {
+	if arguments[1] == "-" {
+		return nil
+	}
+	result.Input = arguments[0]
+	result.Output = arguments[1]
+	return &result
+}
+
+// isStreamError - reports whether the error comes from a malformed or altered encrypted file.
+func isStreamError(err error) bool {
+	return errors.Is(err, stream.ErrInvalidHeader) || errors.Is(err, stream.ErrAuthentication) ||
+		errors.Is(err, stream.ErrTruncated) || errors.Is(err, stream.ErrTooManyChunks)
+}
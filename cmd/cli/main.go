@@ -1,5 +1,224 @@
 package main
 
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+)
+
+const (
+	DEFAULT_SOCKET_PATH = "/etc/hyperplane/openkms/openkms.sock"
+
+	EXIT_OK                  = 0
+	EXIT_FAILURE             = 1 // unexpected failure, either in the CLI or in the daemon.
+	EXIT_USAGE               = 2 // the command line could not be parsed.
+	EXIT_UNAVAILABLE         = 3 // the daemon could not be reached.
+	EXIT_NOT_FOUND           = 4 // the key, version or alias does not exist.
+	EXIT_PERMISSION_DENIED   = 5 // the daemon refused the caller.
+	EXIT_REJECTED            = 6 // the daemon rejected the request as invalid.
+	EXIT_FAILED_PRECONDITION = 7 // the key is not in a state allowing the operation.
+	EXIT_INVALID_INPUT       = 8 // the input is not a valid encrypted file or was altered.
+)
+
+var (
+	errUsage = errors.New("usage")
+)
+
+// options - flags accepted by every command.
+type options struct {
+	socket string
+	output string
+}
+
+// command - node of the command tree. Leaves have a run function, other nodes have subcommands.
+type command struct {
+	name        string
+	summary     string
+	subcommands []*command
+
+	// flags - registers the command's own flags, returning the function that runs it with the remaining arguments.
+	flags func(flagSet *flag.FlagSet) func(client *cliapi.Client, arguments []string) (any, error)
+}
+
 func main() {
-	println("OpenKMS CLI")
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run - runs the command line and returns its exit code.
+func run(arguments []string, stdout, stderr io.Writer) int {
+	root := &command{name: "openkms", subcommands: commands()}
+
+	// Walk down the tree until the arguments stop naming subcommands, setting aside the global flags given on the way
+	// so they are parsed together with the flags of the command.
+	//
+	path := []*command{root}
+	var globalFlags []string
+	for len(arguments) > 0 && len(path[len(path)-1].subcommands) > 0 {
+		if name, hasValue := globalFlag(arguments[0]); name != "" {
+			taken := 1
+			if !hasValue && len(arguments) > 1 {
+				taken = 2
+			}
+			globalFlags = append(globalFlags, arguments[:taken]...)
+			arguments = arguments[taken:]
+			continue
+		}
+
+		next := findCommand(path[len(path)-1], arguments[0])
+		if next == nil {
+			break
+		}
+		path = append(path, next)
+		arguments = arguments[1:]
+	}
+	current := path[len(path)-1]
+	arguments = append(globalFlags, arguments...)
+
+	if current.flags == nil {
+		arguments = arguments[len(globalFlags):]
+		printUsage(stderr, path)
+		if len(arguments) > 0 && (arguments[0] == "help" || arguments[0] == "-h" || arguments[0] == "--help") {
+			return EXIT_OK
+		}
+		if len(arguments) > 0 {
+			fmt.Fprintf(stderr, "\nunknown command %q\n", arguments[0])
+		}
+		return EXIT_USAGE
+	}
+
+	opts := options{}
+	flagSet := flag.NewFlagSet(commandPath(path), flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.StringVar(&opts.socket, "socket", getEnv("OPENKMS_SOCKET", DEFAULT_SOCKET_PATH), "path of the daemon's CLI API socket")
+	flagSet.StringVar(&opts.output, "output", OUTPUT_TABLE, "output format: table, json or yaml")
+	runCommand := current.flags(flagSet)
+
+	positional, err := parseInterspersed(flagSet, arguments)
+	if errors.Is(err, flag.ErrHelp) {
+		return EXIT_OK
+	}
+	if err != nil {
+		return EXIT_USAGE
+	}
+	if !isOutputFormat(opts.output) {
+		fmt.Fprintf(stderr, "unsupported output format %q\n", opts.output)
+		return EXIT_USAGE
+	}
+
+	result, err := runCommand(cliapi.NewClient(opts.socket), positional)
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "%v\n", err)
+			flagSet.Usage()
+			return EXIT_USAGE
+		}
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitCode(err)
+	}
+
+	if err := render(stdout, opts.output, result); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return EXIT_FAILURE
+	}
+	return EXIT_OK
+}
+
+// exitCode - maps an error returned by a command to the exit code of the CLI.
+func exitCode(err error) int {
+	var apiError *cliapi.Error
+	if errors.As(err, &apiError) {
+		switch apiError.Code {
+		case cliapi.ERROR_CODE_NOT_FOUND:
+			return EXIT_NOT_FOUND
+		case cliapi.ERROR_CODE_PERMISSION_DENIED:
+			return EXIT_PERMISSION_DENIED
+		case cliapi.ERROR_CODE_FAILED_PRECONDITION:
+			return EXIT_FAILED_PRECONDITION
+		case cliapi.ERROR_CODE_INVALID_REQUEST, cliapi.ERROR_CODE_INVALID_ARGUMENT, cliapi.ERROR_CODE_ALREADY_EXISTS,
+			cliapi.ERROR_CODE_UNKNOWN_METHOD, cliapi.ERROR_CODE_UNSUPPORTED_VERSION:
+			return EXIT_REJECTED
+		}
+		return EXIT_FAILURE
+	}
+
+	if isStreamError(err) {
+		return EXIT_INVALID_INPUT
+	}
+
+	var opError *net.OpError
+	if errors.As(err, &opError) && opError.Op == "dial" {
+		return EXIT_UNAVAILABLE
+	}
+	return EXIT_FAILURE
+}
+
+// parseInterspersed - parses flags placed before, between or after positional arguments.
+func parseInterspersed(flagSet *flag.FlagSet, arguments []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flagSet.Parse(arguments); err != nil {
+			return nil, err
+		}
+		arguments = flagSet.Args()
+		if len(arguments) == 0 {
+			return positional, nil
+		}
+		if arguments[0] == "--" {
+			return append(positional, arguments[1:]...), nil
+		}
+		positional = append(positional, arguments[0])
+		arguments = arguments[1:]
+	}
+}
+
+// globalFlag - returns the name of the global flag given by the argument, if any, and whether its value is attached.
+func globalFlag(argument string) (string, bool) {
+	name, _, hasValue := strings.Cut(strings.TrimLeft(argument, "-"), "=")
+	if !strings.HasPrefix(argument, "-") || (name != "socket" && name != "output") {
+		return "", false
+	}
+	return name, hasValue
+}
+
+// findCommand - returns the subcommand with the given name, if any.
+func findCommand(parent *command, name string) *command {
+	for _, subcommand := range parent.subcommands {
+		if subcommand.name == name {
+			return subcommand
+		}
+	}
+	return nil
+}
+
+// commandPath - full name of the command at the end of the path.
+func commandPath(path []*command) string {
+	names := make([]string, 0, len(path))
+	for _, c := range path {
+		names = append(names, c.name)
+	}
+	return strings.Join(names, " ")
+}
+
+// printUsage - lists the subcommands of the command at the end of the path.
+func printUsage(w io.Writer, path []*command) {
+	current := path[len(path)-1]
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", commandPath(path))
+	for _, subcommand := range current.subcommands {
+		fmt.Fprintf(w, "  %-20s %s\n", subcommand.name, subcommand.summary)
+	}
+}
+
+// getEnv - retrieves the value of the environment variable named by the key.
+func getEnv(key, def string) string {
+	if val, ok := syscall.Getenv(key); ok {
+		return val
+	}
+	return def
 }
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/stream"
+)
+
+// startDaemon - serves a fake CLI API answering the methods used by the tests, returning the path of its socket.
+func startDaemon(t *testing.T) string {
+	t.Helper()
+
+	// Unix socket paths are limited in length, so avoid the long test temp directory.
+	//
+	directory, err := os.MkdirTemp("", "cli")
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(directory) })
+	socketPath := filepath.Join(directory, "openkms.sock")
+
+	server := cliapi.NewServer(socketPath, nil, "TEST", cliapi.Allowlist{})
+	server.Handle(cliapi.METHOD_DAEMON_STATUS, func(ctx context.Context, params json.RawMessage) (any, error) {
+		return cliapi.StatusResult{ProtocolVersion: cliapi.PROTOCOL_VERSION, Keys: 2, Aliases: 1}, nil
+	})
+	server.Handle(cliapi.METHOD_KEYS_DESCRIBE, func(ctx context.Context, params json.RawMessage) (any, error) {
+		var keyID cliapi.KeyIDParams
+		if err := json.Unmarshal(params, &keyID); err != nil {
+			return nil, err
+		}
+		if keyID.KeyID == "missing" {
+			return nil, kms.ErrKeyNotFound
+		}
+		return cliapi.KeyDescription{ID: keyID.KeyID, State: kms.KEY_STATE_ENABLED, PrimaryVersion: 1, Versions: []int{1}}, nil
+	})
+	server.Handle(cliapi.METHOD_KEYS_ENABLE, func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, cliapi.NewError(cliapi.ERROR_CODE_FAILED_PRECONDITION, "key is pending deletion")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- server.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Expected daemon to stop cleanly, got %v", err)
+		}
+	})
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(socketPath); err == nil {
+			return socketPath
+		}
+	}
+	t.Fatalf("Daemon did not start listening")
+	return ""
+}
+
+func TestRun(t *testing.T) {
+	socketPath := startDaemon(t)
+	unavailable := filepath.Join(t.TempDir(), "missing.sock")
+
+	scenarios := []struct {
+		name      string
+		arguments []string
+		exitCode  int
+		stdout    string // expected within the standard output.
+		stderr    string // expected within the standard error.
+	}{
+		{
+			name:      "No command",
+			arguments: []string{},
+			exitCode:  EXIT_USAGE,
+			stderr:    "Usage: openkms <command>",
+		},
+		{
+			name:      "Help",
+			arguments: []string{"keys", "help"},
+			exitCode:  EXIT_OK,
+			stderr:    "Usage: openkms keys <command>",
+		},
+		{
+			name:      "Unknown command",
+			arguments: []string{"keys", "shred"},
+			exitCode:  EXIT_USAGE,
+			stderr:    `unknown command "shred"`,
+		},
+		{
+			name:      "Unknown flag",
+			arguments: []string{"keys", "list", "--verbose"},
+			exitCode:  EXIT_USAGE,
+			stderr:    "flag provided but not defined: -verbose",
+		},
+		{
+			name:      "Unsupported output format",
+			arguments: []string{"daemon", "status", "--socket", socketPath, "--output", "xml"},
+			exitCode:  EXIT_USAGE,
+			stderr:    `unsupported output format "xml"`,
+		},
+		{
+			name:      "Missing argument",
+			arguments: []string{"keys", "describe", "--socket", socketPath},
+			exitCode:  EXIT_USAGE,
+			stderr:    "expected arguments <key-id>, got 0",
+		},
+		{
+			name:      "Table output",
+			arguments: []string{"daemon", "status", "--socket", socketPath},
+			exitCode:  EXIT_OK,
+			stdout:    "PROTOCOL VERSION  1",
+		},
+		{
+			name:      "Global flags before the command",
+			arguments: []string{"--socket", socketPath, "--output=json", "keys", "describe", "key-1"},
+			exitCode:  EXIT_OK,
+			stdout:    `"id": "key-1"`,
+		},
+		{
+			name:      "Flags after the argument",
+			arguments: []string{"keys", "describe", "key-1", "--socket=" + socketPath, "--output", "yaml"},
+			exitCode:  EXIT_OK,
+			stdout:    "id: key-1",
+		},
+		{
+			name:      "Key not found",
+			arguments: []string{"keys", "describe", "missing", "--socket", socketPath},
+			exitCode:  EXIT_NOT_FOUND,
+			stderr:    "error: ",
+		},
+		{
+			name:      "Failed precondition",
+			arguments: []string{"keys", "enable", "key-1", "--socket", socketPath},
+			exitCode:  EXIT_FAILED_PRECONDITION,
+			stderr:    "key is pending deletion",
+		},
+		{
+			name:      "Daemon unavailable",
+			arguments: []string{"daemon", "status", "--socket", unavailable},
+			exitCode:  EXIT_UNAVAILABLE,
+			stderr:    "error: ",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			if exitCode := run(scenario.arguments, stdout, stderr); exitCode != scenario.exitCode {
+				t.Errorf("Expected exit code %d, got %d: %s", scenario.exitCode, exitCode, stderr)
+			}
+			if !strings.Contains(stdout.String(), scenario.stdout) {
+				t.Errorf("Expected standard output to contain %q, got %q", scenario.stdout, stdout)
+			}
+			if !strings.Contains(stderr.String(), scenario.stderr) {
+				t.Errorf("Expected standard error to contain %q, got %q", scenario.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	scenarios := []struct {
+		name       string
+		arguments  []string
+		positional []string
+		tags       string
+		err        bool
+	}{
+		{
+			name:      "No arguments",
+			arguments: []string{},
+		},
+		{
+			name:       "Flags before arguments",
+			arguments:  []string{"--tag", "team=payments", "first", "second"},
+			positional: []string{"first", "second"},
+			tags:       "team=payments",
+		},
+		{
+			name:       "Flags between and after arguments",
+			arguments:  []string{"first", "--tag=team=payments", "second", "--tag", "env=prod"},
+			positional: []string{"first", "second"},
+			tags:       "env=prod,team=payments",
+		},
+		{
+			name:       "Arguments after the terminator",
+			arguments:  []string{"first", "--", "--tag", "-"},
+			positional: []string{"first", "--tag", "-"},
+		},
+		{
+			name:      "Unknown flag",
+			arguments: []string{"first", "--verbose"},
+			err:       true,
+		},
+		{
+			name:      "Invalid flag value",
+			arguments: []string{"--tag", "payments"},
+			err:       true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			tags := keyValues{}
+			flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+			flagSet.SetOutput(io.Discard)
+			flagSet.Var(tags, "tag", "tag as key=value")
+
+			positional, err := parseInterspersed(flagSet, scenario.arguments)
+			if (err != nil) != scenario.err {
+				t.Fatalf("Expected error %v, got %v", scenario.err, err)
+			}
+			if !reflect.DeepEqual(positional, scenario.positional) {
+				t.Errorf("Expected positional arguments %q, got %q", scenario.positional, positional)
+			}
+			if !scenario.err && tags.String() != scenario.tags {
+				t.Errorf("Expected tags %q, got %q", scenario.tags, tags.String())
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		err      error
+		exitCode int
+	}{
+		{"Not found", cliapi.NewError(cliapi.ERROR_CODE_NOT_FOUND, "key not found"), EXIT_NOT_FOUND},
+		{"Permission denied", cliapi.NewError(cliapi.ERROR_CODE_PERMISSION_DENIED, "denied"), EXIT_PERMISSION_DENIED},
+		{"Failed precondition", cliapi.NewError(cliapi.ERROR_CODE_FAILED_PRECONDITION, "disabled"), EXIT_FAILED_PRECONDITION},
+		{"Invalid argument", cliapi.NewError(cliapi.ERROR_CODE_INVALID_ARGUMENT, "invalid"), EXIT_REJECTED},
+		{"Already exists", cliapi.NewError(cliapi.ERROR_CODE_ALREADY_EXISTS, "exists"), EXIT_REJECTED},
+		{"Unknown method", cliapi.NewError(cliapi.ERROR_CODE_UNKNOWN_METHOD, "unknown"), EXIT_REJECTED},
+		{"Internal", cliapi.NewError(cliapi.ERROR_CODE_INTERNAL, "internal"), EXIT_FAILURE},
+		{"Wrapped API error", fmt.Errorf("describe: %w", cliapi.NewError(cliapi.ERROR_CODE_NOT_FOUND, "key not found")), EXIT_NOT_FOUND},
+		{"Altered file", fmt.Errorf("decrypt: %w", stream.ErrAuthentication), EXIT_INVALID_INPUT},
+		{"Truncated file", stream.ErrTruncated, EXIT_INVALID_INPUT},
+		{"Dial failure", &net.OpError{Op: "dial", Net: "unix", Err: os.ErrNotExist}, EXIT_UNAVAILABLE},
+		{"Read failure", &net.OpError{Op: "read", Net: "unix", Err: os.ErrDeadlineExceeded}, EXIT_FAILURE},
+		{"Other failure", errors.New("disk full"), EXIT_FAILURE},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if exitCode := exitCode(scenario.err); exitCode != scenario.exitCode {
+				t.Errorf("Expected exit code %d, got %d", scenario.exitCode, exitCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+	OUTPUT_YAML  = "yaml"
+)
+
+// isOutputFormat - reports whether the output format is supported.
+func isOutputFormat(format string) bool {
+	return format == OUTPUT_TABLE || format == OUTPUT_JSON || format == OUTPUT_YAML
+}
+
+// render - writes the result of a command in the given output format.
+func render(w io.Writer, format string, result any) error {
+	if result == nil {
+		return nil
+	}
+
+	switch format {
+	case OUTPUT_JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case OUTPUT_YAML:
+		return renderYAML(w, result)
+	}
+	return renderTable(w, result)
+}
+
+// renderYAML - writes the result as YAML, going through its JSON encoding so both formats share field names, field
+// order and the base64 encoding of binary values.
+func renderYAML(w io.Writer, result any) error {
+	document, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML, so it decodes into a node tree which only needs its flow style removed.
+	//
+	var node yaml.Node
+	if err := yaml.Unmarshal(document, &node); err != nil {
+		return err
+	}
+	var blockStyle func(node *yaml.Node)
+	blockStyle = func(node *yaml.Node) {
+		node.Style = 0
+		for _, child := range node.Content {
+			blockStyle(child)
+		}
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// renderTable - writes the result in a human readable layout.
+func renderTable(w io.Writer, result any) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	switch result := result.(type) {
+	case *cliapi.StatusResult:
+		fmt.Fprintf(table, "PROTOCOL VERSION\t%d\n", result.ProtocolVersion)
+		fmt.Fprintf(table, "STARTED AT\t%s\n", formatTime(&result.StartedAt))
+		fmt.Fprintf(table, "SEALED\t%t\n", result.Sealed)
+		if !result.Sealed {
+			fmt.Fprintf(table, "KEYS\t%d\n", result.Keys)
+			fmt.Fprintf(table, "ALIASES\t%d\n", result.Aliases)
+		}
+	case *cliapi.OperatorInitResult:
+		for i, share := range result.Shares {
+			fmt.Fprintf(table, "UNSEAL SHARE %d\t%s\n", i+1, base64.StdEncoding.EncodeToString(share))
+		}
+		fmt.Fprintf(table, "THRESHOLD\t%d\n", result.Threshold)
+	case *cliapi.SealStatusResult:
+		fmt.Fprintf(table, "TYPE\t%s\n", result.Type)
+		fmt.Fprintf(table, "INITIALIZED\t%t\n", result.Initialized)
+		fmt.Fprintf(table, "SEALED\t%t\n", result.Sealed)
+		if result.Type == kms.SEAL_TYPE_SHAMIR {
+			fmt.Fprintf(table, "SHARES\t%d\n", result.Shares)
+			fmt.Fprintf(table, "THRESHOLD\t%d\n", result.Threshold)
+			fmt.Fprintf(table, "UNSEAL PROGRESS\t%d/%d\n", result.Progress, result.Threshold)
+		}
+	case *cliapi.KeyListResult:
+		fmt.Fprintln(table, "ID\tSPEC\tUSAGE\tSTATE\tPRIMARY VERSION\tDESCRIPTION")
+		for _, key := range result.Keys {
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%s\n", key.ID, key.Spec, key.Usage, key.State, key.PrimaryVersion, key.Description)
+		}
+	case *cliapi.KeyDescription:
+		versions := make([]string, 0, len(result.Versions))
+		for _, version := range result.Versions {
+			versions = append(versions, strconv.Itoa(version))
+		}
+		fmt.Fprintf(table, "ID\t%s\n", result.ID)
+		fmt.Fprintf(table, "SPEC\t%s\n", result.Spec)
+		fmt.Fprintf(table, "USAGE\t%s\n", result.Usage)
+		fmt.Fprintf(table, "PROVIDER\t%s\n", result.Provider)
+		fmt.Fprintf(table, "STATE\t%s\n", result.State)
+		fmt.Fprintf(table, "DESCRIPTION\t%s\n", result.Description)
+		fmt.Fprintf(table, "TAGS\t%s\n", formatTags(result.Tags))
+		fmt.Fprintf(table, "PRIMARY VERSION\t%d\n", result.PrimaryVersion)
+		fmt.Fprintf(table, "VERSIONS\t%s\n", strings.Join(versions, ", "))
+		fmt.Fprintf(table, "ROTATION PERIOD (DAYS)\t%d\n", result.RotationPeriodDays)
+		fmt.Fprintf(table, "NEXT ROTATION AT\t%s\n", formatTime(result.NextRotationAt))
+		fmt.Fprintf(table, "DELETION DATE\t%s\n", formatTime(result.DeletionDate))
+		fmt.Fprintf(table, "CREATED AT\t%s\n", formatTime(&result.CreatedAt))
+		fmt.Fprintf(table, "UPDATED AT\t%s\n", formatTime(&result.UpdatedAt))
+	case *cliapi.AliasListResult:
+		fmt.Fprintln(table, "NAME\tKEY ID\tUPDATED AT")
+		for _, alias := range result.Aliases {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", alias.Name, alias.KeyID, formatTime(&alias.UpdatedAt))
+		}
+	case *cliapi.AliasDescription:
+		fmt.Fprintln(table, "NAME\tKEY ID\tUPDATED AT")
+		fmt.Fprintf(table, "%s\t%s\t%s\n", result.Name, result.KeyID, formatTime(&result.UpdatedAt))
+	case *cliapi.EncryptResult:
+		fmt.Fprintf(table, "KEY ID\t%s\n", result.KeyID)
+		fmt.Fprintf(table, "KEY VERSION\t%d\n", result.KeyVersion)
+		if result.Ciphertext != nil {
+			fmt.Fprintf(table, "CIPHERTEXT\t%s\n", base64.StdEncoding.EncodeToString(result.Ciphertext))
+		}
+	case *cliapi.DecryptResult:
+
+		// The plaintext is written as is so it can be piped into other programs.
+		//
+		if result.Plaintext != nil {
+			_, err := w.Write(result.Plaintext)
+			return err
+		}
+		fmt.Fprintf(table, "KEY ID\t%s\n", result.KeyID)
+		fmt.Fprintf(table, "KEY VERSION\t%d\n", result.KeyVersion)
+	case *fileResult:
+		fmt.Fprintf(table, "KEY ID\t%s\n", result.KeyID)
+		fmt.Fprintf(table, "KEY VERSION\t%d\n", result.KeyVersion)
+		fmt.Fprintf(table, "INPUT\t%s\n", result.Input)
+		fmt.Fprintf(table, "OUTPUT\t%s\n", result.Output)
+		fmt.Fprintf(table, "BYTES\t%d\n", result.Bytes)
+	case *cliapi.AuditTailResult:
+		_, err := io.WriteString(w, strings.Join(append(result.Events, ""), "\n"))
+		return err
+	default:
+		return fmt.Errorf("no table layout for %T", result)
+	}
+
+	return table.Flush()
+}
+
+// formatTime - formats an optional timestamp.
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// formatTags - formats tags as sorted key=value pairs.
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	return keyValues(tags).String()
+}
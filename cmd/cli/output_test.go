@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+)
+
+func TestRender(t *testing.T) {
+	scenarios := []struct {
+		name   string
+		format string
+		result any
+		output string
+		err    bool
+	}{
+		{
+			name:   "No result",
+			format: OUTPUT_TABLE,
+			result: nil,
+			output: "",
+		},
+		{
+			name:   "Table",
+			format: OUTPUT_TABLE,
+			result: &cliapi.AliasDescription{Name: "alias/payments", KeyID: "key-1"},
+			output: "NAME            KEY ID  UPDATED AT\nalias/payments  key-1   -\n",
+		},
+		{
+			name:   "Table hiding the counts of a sealed daemon",
+			format: OUTPUT_TABLE,
+			result: &cliapi.SealStatusResult{Type: "transit", Initialized: true, Sealed: true},
+			output: "TYPE         transit\nINITIALIZED  true\nSEALED       true\n",
+		},
+		{
+			name:   "Raw plaintext",
+			format: OUTPUT_TABLE,
+			result: &cliapi.DecryptResult{KeyID: "key-1", KeyVersion: 1, Plaintext: []byte("secret\x00")},
+			output: "secret\x00",
+		},
+		{
+			name:   "JSON with base64 binary values",
+			format: OUTPUT_JSON,
+			result: &cliapi.EncryptResult{KeyID: "key-1", KeyVersion: 2, Ciphertext: []byte{0xde, 0xad}},
+			output: "{\n  \"keyId\": \"key-1\",\n  \"keyVersion\": 2,\n  \"ciphertext\": \"3q0=\"\n}\n",
+		},
+		{
+			name:   "YAML of an empty list",
+			format: OUTPUT_YAML,
+			result: &cliapi.AliasListResult{Aliases: []cliapi.AliasDescription{}},
+			output: "aliases: []\n",
+		},
+		{
+			name:   "YAML with base64 binary values",
+			format: OUTPUT_YAML,
+			result: &cliapi.EncryptResult{KeyID: "key-1", KeyVersion: 2, Ciphertext: []byte{0xde, 0xad}},
+			output: "keyId: key-1\nkeyVersion: 2\nciphertext: 3q0=\n",
+		},
+		{
+			name:   "No table layout",
+			format: OUTPUT_TABLE,
+			result: map[string]string{"unexpected": "result"},
+			err:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := render(output, scenario.format, scenario.result)
+			if (err != nil) != scenario.err {
+				t.Fatalf("Expected error %v, got %v", scenario.err, err)
+			}
+			if !scenario.err && output.String() != scenario.output {
+				t.Errorf("Expected output %q, got %q", scenario.output, output)
+			}
+		})
+	}
+}
@@ -2,8 +2,16 @@ package main
 
 type DaemonConfiguration struct {
 	CLI struct {
-		Enabled bool `yaml:"enabled"`
+		Enabled     bool   `yaml:"enabled"`
+		Socket      string `yaml:"socket"`
+		AllowedUIDs []int  `yaml:"allowedUids"` // when both lists are empty, only the daemon's own user is allowed.
+		AllowedGIDs []int  `yaml:"allowedGids"`
 	} `yaml:"CLI"`
+	KubernetesKMS struct {
+		Enabled bool   `yaml:"enabled"`
+		Socket  string `yaml:"socket"`
+		KeyID   string `yaml:"keyId"` // key ID or alias encrypting the data encryption keys of kube-apiserver.
+	} `yaml:"KubernetesKMS"`
 	Auditing AuditingConfiguration `yaml:"Auditing"`
 	KMS      KMSConfiguration      `yaml:"KMS"`
 }
@@ -15,4 +23,44 @@ type AuditingConfiguration struct {
 		Directory string `yaml:"directory"`
 	} `yaml:"storage"`
 }
-type KMSConfiguration struct{}
+
+type KMSConfiguration struct {
+	Storage struct {
+		Type      string `yaml:"type"`      // file, bolt or postgres. bolt keeps every record inside a single database file.
+		Directory string `yaml:"directory"` // holds the files, or the openkms.db database file of the bolt storage.
+		DSN       string `yaml:"dsn"`       // connection string of the postgres storage, overridden by OPENKMS_POSTGRES_DSN.
+	} `yaml:"storage"`
+	Seal struct {
+		Type    string `yaml:"type"` // shamir or transit, defaults to shamir.
+		Transit struct {
+			Address string `yaml:"address"` // base URL of the REST API of the daemon holding the unseal key.
+			KeyID   string `yaml:"keyId"`   // key ID or alias wrapping the root key.
+			TLS     struct {
+				Enabled   bool   `yaml:"enabled"`
+				Directory string `yaml:"directory"` // ca.crt verifies the daemon, tls.crt and tls.key authenticate this one.
+			} `yaml:"tls"`
+		} `yaml:"transit"`
+	} `yaml:"seal"`
+	KeyProviders struct {
+		Default string `yaml:"default"` // provider of keys created without one, software or pkcs11, defaults to software.
+		PKCS11  struct {
+			Enabled    bool   `yaml:"enabled"`
+			Module     string `yaml:"module"`     // path of the PKCS #11 library of the HSM.
+			TokenLabel string `yaml:"tokenLabel"` // label of the token holding the keys.
+			Pin        string `yaml:"pin"`        // user PIN, overridden by the OPENKMS_PKCS11_PIN environment variable.
+		} `yaml:"pkcs11"`
+	} `yaml:"keyProviders"`
+	API struct {
+		Listen      string `yaml:"listen"`      // the REST API is disabled when empty, it is only served when the TLS settings below require client certificates.
+		GRPCListen  string `yaml:"grpcListen"`  // the gRPC API is disabled when empty, it shares the TLS settings below and is only served when they require client certificates.
+		AWSListen   string `yaml:"awsListen"`   // the AWS KMS compatible API is disabled when empty, it shares the TLS settings below and is only served when they require client certificates.
+		AWSRegion   string `yaml:"awsRegion"`   // region reported in the key ARNs of the AWS KMS compatible API.
+		VaultListen string `yaml:"vaultListen"` // the Vault Transit compatible API is disabled when empty, it shares the TLS settings below and is only served when they require client certificates.
+		TLS         struct {
+			Enabled    bool              `yaml:"enabled"`
+			Directory  string            `yaml:"directory"`  // holds tls.crt, tls.key and, to verify clients, ca.crt.
+			ClientAuth string            `yaml:"clientAuth"` // none, optional or require.
+			Identities map[string]string `yaml:"identities"` // client certificate subject to caller identity.
+		} `yaml:"tls"`
+	} `yaml:"api"`
+}
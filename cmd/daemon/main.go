@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 
 	"github.com/hyperplane-sh/openkms/cmd/daemon/supervisors"
 	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/hsm"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+	"github.com/hyperplane-sh/openkms/internal/transitseal"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,6 +30,8 @@ var (
 
 const (
 	DAEMON_AUDIT_GROUP = "DAEMON"
+
+	BOLT_DATABASE_FILE = "openkms.db" // database file of the bolt storage, inside the KMS storage directory.
 )
 
 type Daemon struct {
@@ -33,6 +42,17 @@ type Daemon struct {
 	//
 	auditor audit.Auditor
 
+	// KMS related fields.
+	//
+	keyStore     kms.KeyStore
+	keyProviders *kms.KeyProviders
+	kmsService   *kms.Service
+
+	// KMS API related fields.
+	//
+	apiTLS        *tlsconfig.Reloader
+	apiIdentities *tlsconfig.IdentityMapper
+
 	// Root context and wait group for the daemon.
 	//
 	waitGroup sync.WaitGroup
@@ -41,8 +61,12 @@ type Daemon struct {
 
 	// Supervisors
 	//
-	cliAPISupervisor supervisors.CliAPISupervisor
-	kmsSupervisor    supervisors.KmsSupervisor
+	cliAPISupervisor        supervisors.CliAPISupervisor
+	kmsSupervisor           supervisors.KmsSupervisor
+	grpcAPISupervisor       supervisors.GrpcAPISupervisor
+	awsAPISupervisor        supervisors.AWSAPISupervisor
+	vaultAPISupervisor      supervisors.VaultAPISupervisor
+	kubernetesKMSSupervisor supervisors.KubernetesKMSSupervisor
 }
 
 func handleSignalTermination() {
@@ -114,6 +138,76 @@ func init() {
 		}()
 	}
 
+	// Build the barrier sealing the key material.
+	//
+	var barrier *kms.Barrier
+	switch daemon.configuration.KMS.Seal.Type {
+	default:
+		slog.Error("Unsupported KMS seal type", "type", daemon.configuration.KMS.Seal.Type)
+		os.Exit(1)
+	case "", kms.SEAL_TYPE_SHAMIR:
+		barrier = kms.NewBarrier()
+	case kms.SEAL_TYPE_TRANSIT:
+		transit := daemon.configuration.KMS.Seal.Transit
+		var transitTLS *tls.Config
+		if transit.TLS.Enabled == true {
+			transitTLS, err = tlsconfig.NewClientConfig(transit.TLS.Directory)
+			if err != nil {
+				slog.Error("Failed to load transit seal certificates", "directory", transit.TLS.Directory, "error", err)
+				os.Exit(1)
+			}
+		}
+		barrier = kms.NewTransitBarrier(transitseal.NewKeyWrapper(transit.Address, transit.KeyID, transitTLS))
+	}
+
+	// Load the key providers keeping key material outside of the key store.
+	//
+	daemon.keyProviders = &kms.KeyProviders{
+		Default:   daemon.configuration.KMS.KeyProviders.Default,
+		Providers: map[string]kms.KeyProvider{},
+	}
+	if daemon.configuration.KMS.KeyProviders.PKCS11.Enabled == true {
+		pkcs11 := daemon.configuration.KMS.KeyProviders.PKCS11
+		provider, err := hsm.NewKeyProvider(pkcs11.Module, pkcs11.TokenLabel, getEnv("OPENKMS_PKCS11_PIN", pkcs11.Pin))
+		if err != nil {
+			slog.Error("Failed to load PKCS #11 key provider", "module", pkcs11.Module, "token", pkcs11.TokenLabel, "error", err)
+			os.Exit(1)
+		}
+		daemon.keyProviders.Providers[kms.KEY_PROVIDER_PKCS11] = provider
+	}
+	if _, exists := daemon.keyProviders.Providers[daemon.keyProviders.Default]; !exists && daemon.keyProviders.Default != "" && daemon.keyProviders.Default != kms.KEY_PROVIDER_SOFTWARE {
+		slog.Error("Default key provider is not enabled", "provider", daemon.keyProviders.Default)
+		os.Exit(1)
+	}
+
+	// Load the key store.
+	//
+	switch daemon.configuration.KMS.Storage.Type {
+	default:
+		slog.Error("Unsupported KMS storage type", "type", daemon.configuration.KMS.Storage.Type)
+		os.Exit(1)
+	case kms.STORAGE_TYPE_FILE:
+		daemon.keyStore = kms.NewKeyStoreStorage(kms.NewStorageBackendFile(daemon.configuration.KMS.Storage.Directory), barrier, daemon.keyProviders, daemon.auditor)
+	case kms.STORAGE_TYPE_BOLT:
+		backend := kms.NewStorageBackendBolt(filepath.Join(daemon.configuration.KMS.Storage.Directory, BOLT_DATABASE_FILE))
+		daemon.keyStore = kms.NewKeyStoreStorage(backend, barrier, daemon.keyProviders, daemon.auditor)
+	case kms.STORAGE_TYPE_POSTGRES:
+		backend := kms.NewStorageBackendPostgres(getEnv("OPENKMS_POSTGRES_DSN", daemon.configuration.KMS.Storage.DSN))
+		daemon.keyStore = kms.NewKeyStoreStorage(backend, barrier, daemon.keyProviders, daemon.auditor)
+	}
+	daemon.kmsService = kms.NewService(daemon.keyStore, daemon.auditor)
+
+	// Load the KMS API certificates if TLS is enabled.
+	//
+	if daemon.configuration.KMS.API.TLS.Enabled == true {
+		daemon.apiTLS, err = tlsconfig.NewReloader(daemon.configuration.KMS.API.TLS.Directory, daemon.configuration.KMS.API.TLS.ClientAuth)
+		if err != nil {
+			slog.Error("Failed to load KMS API certificates", "directory", daemon.configuration.KMS.API.TLS.Directory, "error", err)
+			os.Exit(1)
+		}
+		daemon.apiIdentities = tlsconfig.NewIdentityMapper(daemon.configuration.KMS.API.TLS.Identities)
+	}
+
 	go handleSignalTermination()
 }
 
@@ -130,17 +224,52 @@ func main() {
 	// Start supervisor for KMS API.
 	//
 	daemon.waitGroup.Add(1)
-	daemon.kmsSupervisor = supervisors.KmsSupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor)
+	daemon.kmsSupervisor = supervisors.KmsSupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor, daemon.kmsService, daemon.keyProviders, daemon.configuration.KMS.API.Listen, daemon.apiTLS, daemon.apiIdentities)
 	go daemon.kmsSupervisor.Start()
 
+	// Start supervisor for the gRPC API if enabled in configuration.
+	//
+	if daemon.configuration.KMS.API.GRPCListen != "" {
+		daemon.waitGroup.Add(1)
+		daemon.grpcAPISupervisor = supervisors.GrpcAPISupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor, daemon.configuration.KMS.API.GRPCListen, daemon.kmsService, daemon.apiTLS, daemon.apiIdentities)
+		go daemon.grpcAPISupervisor.Start()
+	}
+
+	// Start supervisor for the AWS KMS compatible API if enabled in configuration.
+	//
+	if daemon.configuration.KMS.API.AWSListen != "" {
+		daemon.waitGroup.Add(1)
+		daemon.awsAPISupervisor = supervisors.AWSAPISupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor, daemon.configuration.KMS.API.AWSListen, daemon.configuration.KMS.API.AWSRegion, daemon.kmsService, daemon.apiTLS, daemon.apiIdentities)
+		go daemon.awsAPISupervisor.Start()
+	}
+
+	// Start supervisor for the Vault Transit compatible API if enabled in configuration.
+	//
+	if daemon.configuration.KMS.API.VaultListen != "" {
+		daemon.waitGroup.Add(1)
+		daemon.vaultAPISupervisor = supervisors.VaultAPISupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor, daemon.configuration.KMS.API.VaultListen, daemon.kmsService, daemon.apiTLS, daemon.apiIdentities)
+		go daemon.vaultAPISupervisor.Start()
+	}
+
 	// Enable CLI API if enabled in configuration.
 	//
 	if daemon.configuration.CLI.Enabled == true {
 		daemon.waitGroup.Add(1)
-		daemon.cliAPISupervisor = supervisors.CliAPISupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor)
+		daemon.cliAPISupervisor = supervisors.CliAPISupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor, daemon.configuration.CLI.Socket, cliapi.Allowlist{
+			UIDs: daemon.configuration.CLI.AllowedUIDs,
+			GIDs: daemon.configuration.CLI.AllowedGIDs,
+		}, daemon.kmsService)
 		go daemon.cliAPISupervisor.Start()
 	}
 
+	// Serve the Kubernetes KMS v2 provider if enabled in configuration.
+	//
+	if daemon.configuration.KubernetesKMS.Enabled == true {
+		daemon.waitGroup.Add(1)
+		daemon.kubernetesKMSSupervisor = supervisors.KubernetesKMSSupervisorNew(daemon.ctx, &daemon.waitGroup, daemon.auditor, daemon.configuration.KubernetesKMS.Socket, daemon.kmsService, daemon.configuration.KubernetesKMS.KeyID)
+		go daemon.kubernetesKMSSupervisor.Start()
+	}
+
 	daemon.auditor.RecordEvent(audit.NewEvent(
 		audit.LEVEL_INFO,
 		DAEMON_AUDIT_GROUP,
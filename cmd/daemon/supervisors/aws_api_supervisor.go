@@ -0,0 +1,129 @@
+package supervisors
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/awskms"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+const (
+	AWS_API_SUPERVISOR_AUDIT_GROUP = "AWS-API-SUPERVISOR"
+	AWS_API_AUDIT_GROUP            = "AWS-API"
+)
+
+// AWSAPISupervisor - supervises the AWS KMS API, served next to the REST API by the same KMS service.
+type AWSAPISupervisor struct {
+	Supervisor
+
+	// Reference to the daemon's context and wait group.
+	//
+	daemonWaitGroup *sync.WaitGroup
+	daemonCtx       context.Context
+
+	// Auditor
+	//
+	auditor audit.Auditor
+
+	// Address the AWS KMS API listens on, the region reported in key ARNs, the KMS service backing its actions and
+	// the TLS certificates and client identities it shares with the REST API, not served when the reloader is nil.
+	//
+	listenAddress string
+	region        string
+	kmsService    *kms.Service
+	apiTLS        *tlsconfig.Reloader
+	apiIdentities *tlsconfig.IdentityMapper
+
+	// Internal context and wait group for the AWS KMS API supervisor.
+	//
+	internalCtx       context.Context
+	internalCancel    context.CancelFunc
+	internalWaitGroup *sync.WaitGroup
+}
+
+// AWSAPISupervisorNew - constructor for AWSAPISupervisor.
+func AWSAPISupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor, listenAddress, region string, kmsService *kms.Service, apiTLS *tlsconfig.Reloader, apiIdentities *tlsconfig.IdentityMapper) AWSAPISupervisor {
+	internalCtx, internalCancel := context.WithCancel(context.Background())
+	return AWSAPISupervisor{
+		daemonWaitGroup:   daemonWaitGroup,
+		daemonCtx:         daemonCtx,
+		auditor:           auditor,
+		listenAddress:     listenAddress,
+		region:            region,
+		kmsService:        kmsService,
+		apiTLS:            apiTLS,
+		apiIdentities:     apiIdentities,
+		internalCtx:       internalCtx,
+		internalCancel:    internalCancel,
+		internalWaitGroup: &sync.WaitGroup{},
+	}
+}
+
+// Start - starts the AWS KMS API.
+func (aA AWSAPISupervisor) Start() {
+	defer aA.daemonWaitGroup.Done()
+
+	aA.internalWaitGroup.Add(1)
+	go awsAPISupervisorMain(aA)
+
+	<-aA.daemonCtx.Done()
+	aA.Stop()
+	aA.internalWaitGroup.Wait()
+}
+
+// Stop - stops the AWS KMS API by cancelling its context.
+func (aA AWSAPISupervisor) Stop() {
+	aA.internalCancel()
+}
+
+// Restart - restarts the AWS KMS API by cancelling its current context and creating a new one.
+func (aA AWSAPISupervisor) Restart() {
+	aA.internalCancel()
+	time.Sleep(400 * time.Millisecond)
+
+	aA.internalCtx, aA.internalCancel = context.WithCancel(context.Background())
+	aA.daemonWaitGroup.Add(1)
+	go aA.Start()
+}
+
+// awsAPISupervisorMain - serves the AWS KMS API until the internal context is cancelled.
+func awsAPISupervisorMain(aA AWSAPISupervisor) {
+	defer aA.internalWaitGroup.Done()
+
+	aA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		AWS_API_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"AWS KMS API Supervisor starting",
+		map[string]string{"listen": aA.listenAddress, "region": aA.region, "tls": strconv.FormatBool(aA.apiTLS != nil)},
+	))
+
+	// AWS signatures are not verified, clients are only authenticated by their certificates.
+	//
+	if requireClientCertificates(aA.auditor, AWS_API_SUPERVISOR_AUDIT_GROUP, "AWS KMS API", aA.listenAddress, aA.apiTLS) &&
+		waitUnsealed(aA.internalCtx, aA.kmsService, aA.auditor, AWS_API_SUPERVISOR_AUDIT_GROUP) {
+		server := awskms.NewServer(aA.kmsService, aA.auditor, AWS_API_AUDIT_GROUP, aA.region, aA.apiIdentities)
+		if err := server.Serve(aA.internalCtx, aA.listenAddress, aA.apiTLS.Config()); err != nil {
+			aA.auditor.RecordEvent(audit.NewEvent(
+				audit.LEVEL_ERROR,
+				AWS_API_SUPERVISOR_AUDIT_GROUP,
+				audit.TOPIC_LIFECYCLE,
+				"AWS KMS API failed to serve",
+				map[string]string{"listen": aA.listenAddress, "error": err.Error()},
+			))
+		}
+	}
+
+	aA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		AWS_API_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"AWS KMS API Supervisor stopping",
+		map[string]string{},
+	))
+}
@@ -0,0 +1,271 @@
+package supervisors
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+const (
+	CLI_API_DEFAULT_AUDIT_TAIL_LINES = 20
+)
+
+// cliAPIHandler - decodes the parameters of a request before passing them to the handler.
+func cliAPIHandler[P any](handle func(ctx context.Context, params P) (any, error)) cliapi.HandlerFunc {
+	return func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params P
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, cliapi.NewError(cliapi.ERROR_CODE_INVALID_REQUEST, "malformed parameters: %v", err)
+			}
+		}
+		return handle(ctx, params)
+	}
+}
+
+// registerCliAPIHandlers - registers every CLI API method against the KMS service.
+func registerCliAPIHandlers(server *cliapi.Server, kmsService *kms.Service, auditor audit.Auditor, startedAt time.Time) {
+	keyStore := kmsService.KeyStore()
+
+	server.Handle(cliapi.METHOD_DAEMON_STATUS, cliAPIHandler(func(ctx context.Context, _ struct{}) (any, error) {
+		if kmsService.Sealed() {
+			return cliapi.StatusResult{ProtocolVersion: cliapi.PROTOCOL_VERSION, StartedAt: startedAt, Sealed: true}, nil
+		}
+		keys, err := keyStore.ListKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		aliases, err := keyStore.ListAliases(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.StatusResult{
+			ProtocolVersion: cliapi.PROTOCOL_VERSION,
+			StartedAt:       startedAt,
+			Keys:            len(keys),
+			Aliases:         len(aliases),
+		}, nil
+	}))
+
+	server.Handle(cliapi.METHOD_AUDIT_TAIL, cliAPIHandler(func(ctx context.Context, params cliapi.AuditTailParams) (any, error) {
+		tailer, ok := auditor.(audit.Tailer)
+		if !ok {
+			return nil, cliapi.NewError(cliapi.ERROR_CODE_FAILED_PRECONDITION, "auditing is disabled or cannot be read back")
+		}
+		if params.Lines <= 0 {
+			params.Lines = CLI_API_DEFAULT_AUDIT_TAIL_LINES
+		}
+		events, err := tailer.Tail(params.Lines)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.AuditTailResult{Events: events}, nil
+	}))
+
+	// Keys
+	//
+	server.Handle(cliapi.METHOD_OPERATOR_INIT, cliAPIHandler(func(ctx context.Context, params cliapi.OperatorInitParams) (any, error) {
+		shares, err := kmsService.Initialize(ctx, params.Shares, params.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.OperatorInitResult{Shares: shares, Threshold: params.Threshold}, nil
+	}))
+	server.Handle(cliapi.METHOD_OPERATOR_UNSEAL, cliAPIHandler(func(ctx context.Context, params cliapi.OperatorUnsealParams) (any, error) {
+		status, err := kmsService.Unseal(ctx, params.Share)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.DescribeSealStatus(status), nil
+	}))
+	server.Handle(cliapi.METHOD_OPERATOR_SEAL, cliAPIHandler(func(ctx context.Context, _ struct{}) (any, error) {
+		if err := kmsService.Seal(ctx); err != nil {
+			return nil, err
+		}
+		status, err := kmsService.SealStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.DescribeSealStatus(status), nil
+	}))
+	server.Handle(cliapi.METHOD_OPERATOR_SEAL_STATUS, cliAPIHandler(func(ctx context.Context, _ struct{}) (any, error) {
+		status, err := kmsService.SealStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.DescribeSealStatus(status), nil
+	}))
+	server.Handle(cliapi.METHOD_KEYS_CREATE, cliAPIHandler(func(ctx context.Context, params cliapi.KeyCreateParams) (any, error) {
+		key, err := keyStore.CreateKey(ctx, kms.CreateKeyOptions{
+			Spec:           params.Spec,
+			Usage:          params.Usage,
+			Provider:       params.Provider,
+			Metadata:       kms.KeyMetadata{Description: params.Description, Tags: params.Tags},
+			RotationPeriod: days(params.RotationPeriodDays),
+		})
+		return describeKey(key, err)
+	}))
+	server.Handle(cliapi.METHOD_KEYS_LIST, cliAPIHandler(func(ctx context.Context, _ struct{}) (any, error) {
+		keys, err := keyStore.ListKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result := cliapi.KeyListResult{Keys: make([]cliapi.KeyDescription, 0, len(keys))}
+		for _, key := range keys {
+			result.Keys = append(result.Keys, cliapi.DescribeKey(key))
+		}
+		return result, nil
+	}))
+	server.Handle(cliapi.METHOD_KEYS_DESCRIBE, cliAPIHandler(func(ctx context.Context, params cliapi.KeyIDParams) (any, error) {
+		return describeKey(keyStore.GetKey(ctx, params.KeyID))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_UPDATE_METADATA, cliAPIHandler(func(ctx context.Context, params cliapi.KeyUpdateMetadataParams) (any, error) {
+		return describeKey(keyStore.UpdateKeyMetadata(ctx, params.KeyID, kms.KeyMetadata{Description: params.Description, Tags: params.Tags}))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_ROTATE, cliAPIHandler(func(ctx context.Context, params cliapi.KeyIDParams) (any, error) {
+		return describeKey(keyStore.RotateKey(ctx, params.KeyID))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_SET_ROTATION_PERIOD, cliAPIHandler(func(ctx context.Context, params cliapi.KeySetRotationPeriodParams) (any, error) {
+		return describeKey(keyStore.UpdateKeyRotationPeriod(ctx, params.KeyID, days(params.RotationPeriodDays)))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_ENABLE, cliAPIHandler(func(ctx context.Context, params cliapi.KeyIDParams) (any, error) {
+		return describeKey(keyStore.EnableKey(ctx, params.KeyID))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_DISABLE, cliAPIHandler(func(ctx context.Context, params cliapi.KeyIDParams) (any, error) {
+		return describeKey(keyStore.DisableKey(ctx, params.KeyID))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_SCHEDULE_DELETION, cliAPIHandler(func(ctx context.Context, params cliapi.KeyScheduleDeletionParams) (any, error) {
+		return describeKey(kmsService.ScheduleKeyDeletion(ctx, params.KeyID, days(params.PendingWindowDays)))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_CANCEL_DELETION, cliAPIHandler(func(ctx context.Context, params cliapi.KeyIDParams) (any, error) {
+		return describeKey(keyStore.CancelKeyDeletion(ctx, params.KeyID))
+	}))
+	server.Handle(cliapi.METHOD_KEYS_GET_PUBLIC_KEY, cliAPIHandler(func(ctx context.Context, params cliapi.KeyIDParams) (any, error) {
+		publicKey, err := kmsService.GetPublicKey(ctx, params.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.PublicKeyResult{
+			KeyID:      publicKey.KeyID,
+			KeyVersion: publicKey.KeyVersion,
+			Spec:       publicKey.Spec,
+			Usage:      publicKey.Usage,
+			PEM:        string(publicKey.PEM),
+			JWK:        publicKey.JWK,
+		}, nil
+	}))
+
+	// Aliases
+	//
+	server.Handle(cliapi.METHOD_ALIASES_CREATE, cliAPIHandler(func(ctx context.Context, params cliapi.AliasParams) (any, error) {
+		return describeAlias(keyStore.CreateAlias(ctx, params.Name, params.KeyID))
+	}))
+	server.Handle(cliapi.METHOD_ALIASES_UPDATE, cliAPIHandler(func(ctx context.Context, params cliapi.AliasParams) (any, error) {
+		return describeAlias(keyStore.UpdateAlias(ctx, params.Name, params.KeyID))
+	}))
+	server.Handle(cliapi.METHOD_ALIASES_DELETE, cliAPIHandler(func(ctx context.Context, params cliapi.AliasParams) (any, error) {
+		return struct{}{}, keyStore.DeleteAlias(ctx, params.Name)
+	}))
+	server.Handle(cliapi.METHOD_ALIASES_LIST, cliAPIHandler(func(ctx context.Context, _ struct{}) (any, error) {
+		aliases, err := keyStore.ListAliases(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result := cliapi.AliasListResult{Aliases: make([]cliapi.AliasDescription, 0, len(aliases))}
+		for _, alias := range aliases {
+			result.Aliases = append(result.Aliases, cliapi.DescribeAlias(alias))
+		}
+		return result, nil
+	}))
+
+	// Cryptographic operations
+	//
+	server.Handle(cliapi.METHOD_ENCRYPT, cliAPIHandler(func(ctx context.Context, params cliapi.EncryptParams) (any, error) {
+		ciphertext, err := kmsService.EncryptPlaintext(ctx, params.KeyID, params.Plaintext, params.EncryptionContext)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.EncryptResult{KeyID: ciphertext.KeyID, KeyVersion: ciphertext.KeyVersion, Ciphertext: ciphertext.Ciphertext}, nil
+	}))
+	server.Handle(cliapi.METHOD_DECRYPT, cliAPIHandler(func(ctx context.Context, params cliapi.DecryptParams) (any, error) {
+		plaintext, err := kmsService.DecryptCiphertext(ctx, params.Ciphertext, params.EncryptionContext)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.DecryptResult{KeyID: plaintext.KeyID, KeyVersion: plaintext.KeyVersion, Plaintext: plaintext.Plaintext}, nil
+	}))
+	server.Handle(cliapi.METHOD_GENERATE_DATA_KEY, cliAPIHandler(func(ctx context.Context, params cliapi.GenerateDataKeyParams) (any, error) {
+		generate := kmsService.GenerateDataKey
+		if params.WithoutPlaintext {
+			generate = kmsService.GenerateDataKeyWithoutPlaintext
+		}
+		dataKey, err := generate(ctx, params.KeyID, params.Spec, params.EncryptionContext)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.GenerateDataKeyResult{
+			KeyID:      dataKey.KeyID,
+			KeyVersion: dataKey.KeyVersion,
+			Plaintext:  dataKey.Plaintext,
+			Ciphertext: dataKey.Ciphertext,
+		}, nil
+	}))
+	server.Handle(cliapi.METHOD_SIGN, cliAPIHandler(func(ctx context.Context, params cliapi.SignParams) (any, error) {
+		signature, err := kmsService.Sign(ctx, params.KeyID, params.Digest, params.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.SignResult{
+			KeyID:      signature.KeyID,
+			KeyVersion: signature.KeyVersion,
+			Algorithm:  signature.Algorithm,
+			Signature:  signature.Signature,
+		}, nil
+	}))
+	server.Handle(cliapi.METHOD_VERIFY, cliAPIHandler(func(ctx context.Context, params cliapi.VerifyParams) (any, error) {
+		valid, err := kmsService.Verify(ctx, params.KeyID, params.Digest, params.Signature, params.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.ValidityResult{Valid: valid}, nil
+	}))
+	server.Handle(cliapi.METHOD_GENERATE_MAC, cliAPIHandler(func(ctx context.Context, params cliapi.MacParams) (any, error) {
+		mac, err := kmsService.GenerateMac(ctx, params.KeyID, params.Message, params.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.MacResult{KeyID: mac.KeyID, KeyVersion: mac.KeyVersion, Algorithm: mac.Algorithm, Mac: mac.Mac}, nil
+	}))
+	server.Handle(cliapi.METHOD_VERIFY_MAC, cliAPIHandler(func(ctx context.Context, params cliapi.MacParams) (any, error) {
+		valid, err := kmsService.VerifyMac(ctx, params.KeyID, params.Message, params.Mac, params.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return cliapi.ValidityResult{Valid: valid}, nil
+	}))
+}
+
+// describeKey - converts the outcome of a key operation into its CLI API result.
+func describeKey(key kms.Key, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return cliapi.DescribeKey(key), nil
+}
+
+// describeAlias - converts the outcome of an alias operation into its CLI API result.
+func describeAlias(alias kms.Alias, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return cliapi.DescribeAlias(alias), nil
+}
+
+// days - converts a number of days into a duration.
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
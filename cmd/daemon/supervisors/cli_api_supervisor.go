@@ -7,6 +7,12 @@ import (
 	"time"
 
 	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+const (
+	CLI_API_SUPERVISOR_AUDIT_GROUP = "CLI-API-SUPERVISOR"
 )
 
 type CliAPISupervisor struct {
@@ -21,6 +27,13 @@ type CliAPISupervisor struct {
 	//
 	auditor audit.Auditor
 
+	// Unix domain socket the CLI API listens on, the peers allowed to connect to it and the KMS service backing
+	// its methods.
+	//
+	socketPath string
+	allowlist  cliapi.Allowlist
+	kmsService *kms.Service
+
 	// Internal context and wait group for the CLI API supervisor.
 	//
 	internalCtx       context.Context
@@ -29,12 +42,15 @@ type CliAPISupervisor struct {
 }
 
 // CliAPISupervisorNew - constructor for CliAPISupervisor.
-func CliAPISupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor) CliAPISupervisor {
+func CliAPISupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor, socketPath string, allowlist cliapi.Allowlist, kmsService *kms.Service) CliAPISupervisor {
 	internalCtx, internalCancel := context.WithCancel(context.Background())
 	return CliAPISupervisor{
 		daemonWaitGroup:   daemonWaitGroup,
 		daemonCtx:         daemonCtx,
 		auditor:           auditor,
+		socketPath:        socketPath,
+		allowlist:         allowlist,
+		kmsService:        kmsService,
 		internalCtx:       internalCtx,
 		internalCancel:    internalCancel,
 		internalWaitGroup: &sync.WaitGroup{},
@@ -76,26 +92,32 @@ func cliAPISupervisorMain(cA CliAPISupervisor) {
 
 	cA.auditor.RecordEvent(audit.NewEvent(
 		audit.LEVEL_INFO,
-		"CLI-API-SUPERVISOR",
+		CLI_API_SUPERVISOR_AUDIT_GROUP,
 		audit.TOPIC_LIFECYCLE,
 		"CLI API Supervisor starting",
-		map[string]string{},
+		map[string]string{"socket": cA.socketPath},
 	))
 
-	for {
-		select {
-		case <-cA.internalCtx.Done():
-			cA.auditor.RecordEvent(audit.NewEvent(
-				audit.LEVEL_INFO,
-				"CLI-API-SUPERVISOR",
-				audit.TOPIC_LIFECYCLE,
-				"CLI API Supervisor stopping",
-				map[string]string{},
-			))
-
-			return
-		default:
-			time.Sleep(1 * time.Second)
-		}
+	server := cliapi.NewServer(cA.socketPath, cA.auditor, CLI_API_SUPERVISOR_AUDIT_GROUP, cA.allowlist)
+	registerCliAPIHandlers(server, cA.kmsService, cA.auditor, time.Now().UTC())
+
+	// Serve until the internal context is cancelled.
+	//
+	if err := server.Serve(cA.internalCtx); err != nil {
+		cA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_ERROR,
+			CLI_API_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"CLI API failed to serve",
+			map[string]string{"socket": cA.socketPath, "error": err.Error()},
+		))
 	}
+
+	cA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		CLI_API_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"CLI API Supervisor stopping",
+		map[string]string{},
+	))
 }
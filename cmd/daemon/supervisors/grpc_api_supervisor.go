@@ -0,0 +1,125 @@
+package supervisors
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/grpcapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+const (
+	GRPC_API_SUPERVISOR_AUDIT_GROUP = "GRPC-API-SUPERVISOR"
+	GRPC_API_AUDIT_GROUP            = "GRPC-API"
+)
+
+// GrpcAPISupervisor - supervises the gRPC API, served next to the REST API by the same KMS service.
+type GrpcAPISupervisor struct {
+	Supervisor
+
+	// Reference to the daemon's context and wait group.
+	//
+	daemonWaitGroup *sync.WaitGroup
+	daemonCtx       context.Context
+
+	// Auditor
+	//
+	auditor audit.Auditor
+
+	// Address the gRPC API listens on, the KMS service backing its methods and the TLS certificates and client
+	// identities it shares with the REST API, not served when the reloader is nil.
+	//
+	listenAddress string
+	kmsService    *kms.Service
+	apiTLS        *tlsconfig.Reloader
+	apiIdentities *tlsconfig.IdentityMapper
+
+	// Internal context and wait group for the gRPC API supervisor.
+	//
+	internalCtx       context.Context
+	internalCancel    context.CancelFunc
+	internalWaitGroup *sync.WaitGroup
+}
+
+// GrpcAPISupervisorNew - constructor for GrpcAPISupervisor.
+func GrpcAPISupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor, listenAddress string, kmsService *kms.Service, apiTLS *tlsconfig.Reloader, apiIdentities *tlsconfig.IdentityMapper) GrpcAPISupervisor {
+	internalCtx, internalCancel := context.WithCancel(context.Background())
+	return GrpcAPISupervisor{
+		daemonWaitGroup:   daemonWaitGroup,
+		daemonCtx:         daemonCtx,
+		auditor:           auditor,
+		listenAddress:     listenAddress,
+		kmsService:        kmsService,
+		apiTLS:            apiTLS,
+		apiIdentities:     apiIdentities,
+		internalCtx:       internalCtx,
+		internalCancel:    internalCancel,
+		internalWaitGroup: &sync.WaitGroup{},
+	}
+}
+
+// Start - starts the gRPC API.
+func (gA GrpcAPISupervisor) Start() {
+	defer gA.daemonWaitGroup.Done()
+
+	gA.internalWaitGroup.Add(1)
+	go grpcAPISupervisorMain(gA)
+
+	<-gA.daemonCtx.Done()
+	gA.Stop()
+	gA.internalWaitGroup.Wait()
+}
+
+// Stop - stops the gRPC API by cancelling its context.
+func (gA GrpcAPISupervisor) Stop() {
+	gA.internalCancel()
+}
+
+// Restart - restarts the gRPC API by cancelling its current context and creating a new one.
+func (gA GrpcAPISupervisor) Restart() {
+	gA.internalCancel()
+	time.Sleep(400 * time.Millisecond)
+
+	gA.internalCtx, gA.internalCancel = context.WithCancel(context.Background())
+	gA.daemonWaitGroup.Add(1)
+	go gA.Start()
+}
+
+// grpcAPISupervisorMain - serves the gRPC API until the internal context is cancelled.
+func grpcAPISupervisorMain(gA GrpcAPISupervisor) {
+	defer gA.internalWaitGroup.Done()
+
+	gA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		GRPC_API_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"gRPC API Supervisor starting",
+		map[string]string{"listen": gA.listenAddress, "tls": strconv.FormatBool(gA.apiTLS != nil)},
+	))
+
+	if requireClientCertificates(gA.auditor, GRPC_API_SUPERVISOR_AUDIT_GROUP, "gRPC API", gA.listenAddress, gA.apiTLS) &&
+		waitUnsealed(gA.internalCtx, gA.kmsService, gA.auditor, GRPC_API_SUPERVISOR_AUDIT_GROUP) {
+		server := grpcapi.NewServer(gA.kmsService, gA.auditor, GRPC_API_AUDIT_GROUP, gA.apiIdentities, gA.apiTLS.Config())
+		if err := server.Serve(gA.internalCtx, gA.listenAddress); err != nil {
+			gA.auditor.RecordEvent(audit.NewEvent(
+				audit.LEVEL_ERROR,
+				GRPC_API_SUPERVISOR_AUDIT_GROUP,
+				audit.TOPIC_LIFECYCLE,
+				"gRPC API failed to serve",
+				map[string]string{"listen": gA.listenAddress, "error": err.Error()},
+			))
+		}
+	}
+
+	gA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		GRPC_API_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"gRPC API Supervisor stopping",
+		map[string]string{},
+	))
+}
@@ -2,14 +2,25 @@ package supervisors
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/restapi"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
 )
 
 const (
 	KMS_SUPERVISOR_AUDIT_GROUP = "KMS-SUPERVISOR"
+	KMS_API_AUDIT_GROUP        = "KMS-API"
+
+	KMS_ROTATION_CHECK_INTERVAL = 1 * time.Minute
+	KMS_DELETION_SWEEP_INTERVAL = 1 * time.Minute
+	KMS_AUTO_UNSEAL_RETRY       = 10 * time.Second
+
+	KMS_SUPERVISOR_CALLER_IDENTITY = "system:kms-supervisor"
 )
 
 // KmsSupervisor - supervises internal KMS processes.
@@ -25,6 +36,23 @@ type KmsSupervisor struct {
 	//
 	auditor audit.Auditor
 
+	// KMS service performing operations with the managed keys.
+	//
+	kmsService *kms.Service
+
+	// Key providers of the key store, closed once the key store is.
+	//
+	keyProviders *kms.KeyProviders
+
+	// Address the REST API listens on, the REST API is disabled when empty.
+	//
+	apiListenAddress string
+
+	// TLS certificates and client identities of the REST API, not served when the reloader is nil.
+	//
+	apiTLS        *tlsconfig.Reloader
+	apiIdentities *tlsconfig.IdentityMapper
+
 	// Internal context and wait group for the KMS supervisor.
 	//
 	internalWaitGroup *sync.WaitGroup
@@ -33,7 +61,7 @@ type KmsSupervisor struct {
 }
 
 // KmsSupervisorNew - constructor for KmsSupervisor.
-func KmsSupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor) KmsSupervisor {
+func KmsSupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor, kmsService *kms.Service, keyProviders *kms.KeyProviders, apiListenAddress string, apiTLS *tlsconfig.Reloader, apiIdentities *tlsconfig.IdentityMapper) KmsSupervisor {
 
 	internalCtx, internalCancel := context.WithCancel(context.Background())
 
@@ -41,6 +69,11 @@ func KmsSupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup
 		daemonWaitGroup:   daemonWaitGroup,
 		daemonCtx:         daemonCtx,
 		auditor:           auditor,
+		kmsService:        kmsService,
+		keyProviders:      keyProviders,
+		apiListenAddress:  apiListenAddress,
+		apiTLS:            apiTLS,
+		apiIdentities:     apiIdentities,
 		internalWaitGroup: &sync.WaitGroup{},
 		internalCtx:       internalCtx,
 		internalCancel:    internalCancel,
@@ -59,10 +92,57 @@ func (kA KmsSupervisor) Start() {
 		map[string]string{},
 	))
 
-	// Enter KMS supervisor main loop.
+	// Open the key store before serving any KMS operation.
 	//
-	kA.internalWaitGroup.Add(1)
-	go kmsSupervisorMain(kA)
+	err := kA.kmsService.Open()
+	if err != nil {
+		kA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_ERROR,
+			KMS_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"Failed to open key store",
+			map[string]string{"error": err.Error()},
+		))
+		return
+	}
+
+	// Key material cannot be read until operators submit a threshold of unseal shares, or until the transit seal
+	// unwraps the root key.
+	//
+	switch {
+	case !kA.kmsService.Sealed():
+	case kA.kmsService.AutoUnsealing():
+		kA.internalWaitGroup.Add(1)
+		go kmsAutoUnsealMain(kA)
+	default:
+		kA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_WARN,
+			KMS_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"KMS is sealed, waiting for unseal shares",
+			map[string]string{},
+		))
+	}
+	if kA.kmsService.WaitUnsealed(kA.daemonCtx) == nil {
+		// Enter KMS supervisor main loop.
+		//
+		kA.internalWaitGroup.Add(1)
+		go kmsSupervisorMain(kA)
+
+		// Pick up renewed API certificates without restarting the listeners.
+		//
+		if kA.apiTLS != nil {
+			kA.internalWaitGroup.Add(1)
+			go kmsCertificatesMain(kA)
+		}
+
+		// Serve the REST API if enabled.
+		//
+		if kA.apiListenAddress != "" {
+			kA.internalWaitGroup.Add(1)
+			go kmsAPIMain(kA)
+		}
+	}
 
 	// When the root context is done, stop the KMS supervisor.
 	//
@@ -77,6 +157,28 @@ func (kA KmsSupervisor) Start() {
 
 	kA.Stop()
 	kA.internalWaitGroup.Wait()
+
+	err = kA.kmsService.Close()
+	if err != nil {
+		kA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_ERROR,
+			KMS_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"Failed to close key store",
+			map[string]string{"error": err.Error()},
+		))
+	}
+
+	err = kA.keyProviders.Close()
+	if err != nil {
+		kA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_ERROR,
+			KMS_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"Failed to close key providers",
+			map[string]string{"error": err.Error()},
+		))
+	}
 }
 
 // Stop - stops the KMS API by cancelling its context.
@@ -98,12 +200,134 @@ func (kA KmsSupervisor) Restart() {
 // kmsSupervisorMain - main function for the KMS daemon's internal process.
 func kmsSupervisorMain(kA KmsSupervisor) {
 	defer kA.internalWaitGroup.Done()
+
+	ctx := kms.WithCallerIdentity(kA.internalCtx, KMS_SUPERVISOR_CALLER_IDENTITY)
+
+	rotationTicker := time.NewTicker(KMS_ROTATION_CHECK_INTERVAL)
+	defer rotationTicker.Stop()
+
+	deletionTicker := time.NewTicker(KMS_DELETION_SWEEP_INTERVAL)
+	defer deletionTicker.Stop()
+
+	for {
+		select {
+		case <-kA.internalCtx.Done():
+			return
+		case <-rotationTicker.C:
+			// Rotate keys whose automatic rotation period elapsed, unless the KMS was sealed again.
+			//
+			if kA.kmsService.Sealed() {
+				continue
+			}
+			_, err := kA.kmsService.RotateDueKeys(ctx)
+			if err != nil {
+				kA.auditor.RecordEvent(audit.NewEvent(
+					audit.LEVEL_ERROR,
+					KMS_SUPERVISOR_AUDIT_GROUP,
+					audit.TOPIC_KEY_ROTATED,
+					"Automatic key rotation failed",
+					map[string]string{"error": err.Error()},
+				))
+			}
+		case <-deletionTicker.C:
+			// Destroy keys whose pending deletion window elapsed, unless the KMS was sealed again.
+			//
+			if kA.kmsService.Sealed() {
+				continue
+			}
+			_, err := kA.kmsService.DestroyDueKeys(ctx)
+			if err != nil {
+				kA.auditor.RecordEvent(audit.NewEvent(
+					audit.LEVEL_ERROR,
+					KMS_SUPERVISOR_AUDIT_GROUP,
+					audit.TOPIC_KEY_MANAGEMENT,
+					"Destruction of keys pending deletion failed",
+					map[string]string{"error": err.Error()},
+				))
+			}
+		}
+	}
+}
+
+// kmsAutoUnsealMain - unseals the KMS through the transit seal, retrying until it succeeds or the internal context is
+// cancelled, as the daemon holding the unseal key may not be reachable yet.
+func kmsAutoUnsealMain(kA KmsSupervisor) {
+	defer kA.internalWaitGroup.Done()
+
+	ctx := kms.WithCallerIdentity(kA.internalCtx, KMS_SUPERVISOR_CALLER_IDENTITY)
+
 	for {
+		err := kA.kmsService.AutoUnseal(ctx)
+		if err == nil {
+			return
+		}
+		kA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_WARN,
+			KMS_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"KMS auto-unseal failed, retrying",
+			map[string]string{"error": err.Error(), "retryIn": KMS_AUTO_UNSEAL_RETRY.String()},
+		))
+
 		select {
 		case <-kA.internalCtx.Done():
 			return
-		default:
-			time.Sleep(1 * time.Second)
+		case <-time.After(KMS_AUTO_UNSEAL_RETRY):
+		}
+	}
+}
+
+// kmsCertificatesMain - reloads the certificates of the KMS APIs when they change, until the internal context is
+// cancelled. Listeners using the reloader's configuration serve the new certificates to new connections.
+func kmsCertificatesMain(kA KmsSupervisor) {
+	defer kA.internalWaitGroup.Done()
+
+	kA.apiTLS.Watch(kA.internalCtx, tlsconfig.RELOAD_INTERVAL, func(err error) {
+		if err != nil {
+			kA.auditor.RecordEvent(audit.NewEvent(
+				audit.LEVEL_WARN,
+				KMS_SUPERVISOR_AUDIT_GROUP,
+				audit.TOPIC_LIFECYCLE,
+				"Failed to reload KMS API certificates, keeping the current ones",
+				map[string]string{"error": err.Error()},
+			))
+			return
 		}
+		kA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_INFO,
+			KMS_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"KMS API certificates reloaded",
+			map[string]string{},
+		))
+	})
+}
+
+// kmsAPIMain - serves the KMS REST API until the internal context is cancelled.
+func kmsAPIMain(kA KmsSupervisor) {
+	defer kA.internalWaitGroup.Done()
+
+	kA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		KMS_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"KMS REST API starting",
+		map[string]string{"listen": kA.apiListenAddress, "tls": strconv.FormatBool(kA.apiTLS != nil)},
+	))
+
+	if !requireClientCertificates(kA.auditor, KMS_SUPERVISOR_AUDIT_GROUP, "KMS REST API", kA.apiListenAddress, kA.apiTLS) {
+		return
+	}
+
+	server := restapi.NewServer(kA.kmsService, kA.auditor, KMS_API_AUDIT_GROUP, kA.apiIdentities)
+	err := server.Serve(kA.internalCtx, kA.apiListenAddress, kA.apiTLS.Config())
+	if err != nil {
+		kA.auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_ERROR,
+			KMS_SUPERVISOR_AUDIT_GROUP,
+			audit.TOPIC_LIFECYCLE,
+			"KMS REST API failed to serve",
+			map[string]string{"listen": kA.apiListenAddress, "error": err.Error()},
+		))
 	}
 }
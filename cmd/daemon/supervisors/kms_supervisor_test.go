@@ -0,0 +1,24 @@
+package supervisors_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/cmd/daemon/supervisors"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+func TestKmsSupervisor_RequiresClientCertificates(t *testing.T) {
+	auditor := &kmstest.Auditor{}
+	service := kms.NewService(kms.NewKeyStoreFile(t.TempDir(), auditor), auditor)
+	address := freeAddress(t)
+
+	// Without TLS requiring client certificates the REST API is refused, not served in plain HTTP.
+	//
+	start(t, func(ctx context.Context, waitGroup *sync.WaitGroup) supervisors.Supervisor {
+		return supervisors.KmsSupervisorNew(ctx, waitGroup, auditor, service, nil, address, nil, nil)
+	})
+	expectRefused(t, auditor, "KMS REST API", address)
+}
@@ -0,0 +1,119 @@
+package supervisors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kubekms"
+)
+
+const (
+	KUBERNETES_KMS_SUPERVISOR_AUDIT_GROUP = "KUBERNETES-KMS-SUPERVISOR"
+)
+
+// KubernetesKMSSupervisor - supervises the Kubernetes KMS v2 provider served to kube-apiserver.
+type KubernetesKMSSupervisor struct {
+	Supervisor
+
+	// Reference to the daemon's context and wait group.
+	//
+	daemonWaitGroup *sync.WaitGroup
+	daemonCtx       context.Context
+
+	// Auditor
+	//
+	auditor audit.Auditor
+
+	// Unix domain socket the provider listens on, the KMS service backing it and the key encrypting the data
+	// encryption keys of kube-apiserver.
+	//
+	socketPath string
+	kmsService *kms.Service
+	keyID      string
+
+	// Internal context and wait group for the Kubernetes KMS supervisor.
+	//
+	internalCtx       context.Context
+	internalCancel    context.CancelFunc
+	internalWaitGroup *sync.WaitGroup
+}
+
+// KubernetesKMSSupervisorNew - constructor for KubernetesKMSSupervisor.
+func KubernetesKMSSupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor, socketPath string, kmsService *kms.Service, keyID string) KubernetesKMSSupervisor {
+	internalCtx, internalCancel := context.WithCancel(context.Background())
+	return KubernetesKMSSupervisor{
+		daemonWaitGroup:   daemonWaitGroup,
+		daemonCtx:         daemonCtx,
+		auditor:           auditor,
+		socketPath:        socketPath,
+		kmsService:        kmsService,
+		keyID:             keyID,
+		internalCtx:       internalCtx,
+		internalCancel:    internalCancel,
+		internalWaitGroup: &sync.WaitGroup{},
+	}
+}
+
+// Start - starts the Kubernetes KMS provider.
+func (kK KubernetesKMSSupervisor) Start() {
+	defer kK.daemonWaitGroup.Done()
+
+	kK.internalWaitGroup.Add(1)
+	go kubernetesKMSSupervisorMain(kK)
+
+	<-kK.daemonCtx.Done()
+	kK.Stop()
+	kK.internalWaitGroup.Wait()
+}
+
+// Stop - stops the Kubernetes KMS provider by cancelling its context.
+func (kK KubernetesKMSSupervisor) Stop() {
+	kK.internalCancel()
+}
+
+// Restart - restarts the Kubernetes KMS provider by cancelling its current context and creating a new one.
+func (kK KubernetesKMSSupervisor) Restart() {
+	kK.internalCancel()
+	time.Sleep(400 * time.Millisecond)
+
+	kK.internalCtx, kK.internalCancel = context.WithCancel(context.Background())
+	kK.daemonWaitGroup.Add(1)
+	go kK.Start()
+}
+
+// kubernetesKMSSupervisorMain - serves the Kubernetes KMS provider until the internal context is cancelled.
+func kubernetesKMSSupervisorMain(kK KubernetesKMSSupervisor) {
+	defer kK.internalWaitGroup.Done()
+
+	kK.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		KUBERNETES_KMS_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"Kubernetes KMS Supervisor starting",
+		map[string]string{"socket": kK.socketPath, "keyId": kK.keyID},
+	))
+
+	if waitUnsealed(kK.internalCtx, kK.kmsService, kK.auditor, KUBERNETES_KMS_SUPERVISOR_AUDIT_GROUP) {
+		server := kubekms.NewServer(kK.socketPath, kubekms.NewPlugin(kK.kmsService, kK.keyID), kK.auditor, KUBERNETES_KMS_SUPERVISOR_AUDIT_GROUP)
+		if err := server.Serve(kK.internalCtx); err != nil {
+			kK.auditor.RecordEvent(audit.NewEvent(
+				audit.LEVEL_ERROR,
+				KUBERNETES_KMS_SUPERVISOR_AUDIT_GROUP,
+				audit.TOPIC_LIFECYCLE,
+				"Kubernetes KMS provider failed to serve",
+				map[string]string{"socket": kK.socketPath, "error": err.Error()},
+			))
+		}
+	}
+
+	kK.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		KUBERNETES_KMS_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"Kubernetes KMS Supervisor stopping",
+		map[string]string{},
+	))
+}
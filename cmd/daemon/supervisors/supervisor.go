@@ -1,6 +1,12 @@
 package supervisors
 
-import "github.com/hyperplane-sh/openkms/internal/audit"
+import (
+	"context"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
 
 type Supervisor interface {
 	// Start - starts the supervised service.
@@ -12,3 +18,37 @@ type Supervisor interface {
 	// Auditor  - returns the auditor used by the supervised service.
 	Auditor() audit.Auditor
 }
+
+// waitUnsealed - blocks until the KMS is unsealed, reporting false when the context is done first. The APIs serving
+// KMS operations only listen once the KMS is unsealed; the CLI API does not wait, as operators unseal through it.
+func waitUnsealed(ctx context.Context, kmsService *kms.Service, auditor audit.Auditor, auditGroup string) bool {
+	if kmsService.Sealed() {
+		auditor.RecordEvent(audit.NewEvent(
+			audit.LEVEL_INFO,
+			auditGroup,
+			audit.TOPIC_LIFECYCLE,
+			"KMS is sealed, listening once it is unsealed",
+			map[string]string{},
+		))
+	}
+
+	return kmsService.WaitUnsealed(ctx) == nil
+}
+
+// requireClientCertificates - reports whether the API may be served, recording an error event when it may not. The
+// network APIs only authenticate their callers by their TLS certificates, so they are never served on a listener
+// letting clients in without one.
+func requireClientCertificates(auditor audit.Auditor, auditGroup, apiName, listenAddress string, apiTLS *tlsconfig.Reloader) bool {
+	if apiTLS != nil && apiTLS.RequiresClientCertificates() {
+		return true
+	}
+
+	auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_ERROR,
+		auditGroup,
+		audit.TOPIC_LIFECYCLE,
+		apiName+" not served, it requires TLS with client certificates required",
+		map[string]string{"listen": listenAddress},
+	))
+	return false
+}
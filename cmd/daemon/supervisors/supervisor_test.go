@@ -0,0 +1,71 @@
+package supervisors_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/cmd/daemon/supervisors"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+// freeAddress - loopback address nothing listens on.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// start - starts the supervisor as the daemon would, stopping it at the end of the test.
+func start(t *testing.T, newSupervisor func(ctx context.Context, waitGroup *sync.WaitGroup) supervisors.Supervisor) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waitGroup := &sync.WaitGroup{}
+	waitGroup.Add(1)
+	go newSupervisor(ctx, waitGroup).Start()
+	t.Cleanup(func() {
+		cancel()
+		waitGroup.Wait()
+	})
+}
+
+// waitEvent - waits for the auditor to record an event with the message, failing the test when it does not in time.
+func waitEvent(t *testing.T, auditor *kmstest.Auditor, message string) audit.Event {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, event := range auditor.Events() {
+			if event.Message == message {
+				return event
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected a %q event, got %v", message, auditor.Events())
+	return audit.Event{}
+}
+
+// expectRefused - checks the API recorded that it was refused, without listening on the address.
+func expectRefused(t *testing.T, auditor *kmstest.Auditor, apiName, address string) {
+	t.Helper()
+
+	event := waitEvent(t, auditor, apiName+" not served, it requires TLS with client certificates required")
+	if event.Level != audit.LEVEL_ERROR || event.Labels["listen"] != address {
+		t.Errorf("Expected an error event for %s, got %+v", address, event)
+	}
+
+	if conn, err := net.Dial("tcp", address); err == nil {
+		conn.Close()
+		t.Errorf("Expected nothing to listen on %s", address)
+	}
+}
@@ -0,0 +1,127 @@
+package supervisors
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+	"github.com/hyperplane-sh/openkms/internal/vaulttransit"
+)
+
+const (
+	VAULT_API_SUPERVISOR_AUDIT_GROUP = "VAULT-API-SUPERVISOR"
+	VAULT_API_AUDIT_GROUP            = "VAULT-API"
+)
+
+// VaultAPISupervisor - supervises the Vault Transit API, served next to the REST API by the same KMS service.
+type VaultAPISupervisor struct {
+	Supervisor
+
+	// Reference to the daemon's context and wait group.
+	//
+	daemonWaitGroup *sync.WaitGroup
+	daemonCtx       context.Context
+
+	// Auditor
+	//
+	auditor audit.Auditor
+
+	// Address the Vault Transit API listens on, the KMS service backing its routes and the TLS certificates and client
+	// identities it shares with the REST API, not served when the reloader is nil.
+	//
+	listenAddress string
+	kmsService    *kms.Service
+	apiTLS        *tlsconfig.Reloader
+	apiIdentities *tlsconfig.IdentityMapper
+
+	// Internal context and wait group for the Vault Transit API supervisor.
+	//
+	internalCtx       context.Context
+	internalCancel    context.CancelFunc
+	internalWaitGroup *sync.WaitGroup
+}
+
+// VaultAPISupervisorNew - constructor for VaultAPISupervisor.
+func VaultAPISupervisorNew(daemonCtx context.Context, daemonWaitGroup *sync.WaitGroup, auditor audit.Auditor, listenAddress string, kmsService *kms.Service, apiTLS *tlsconfig.Reloader, apiIdentities *tlsconfig.IdentityMapper) VaultAPISupervisor {
+	internalCtx, internalCancel := context.WithCancel(context.Background())
+	return VaultAPISupervisor{
+		daemonWaitGroup:   daemonWaitGroup,
+		daemonCtx:         daemonCtx,
+		auditor:           auditor,
+		listenAddress:     listenAddress,
+		kmsService:        kmsService,
+		apiTLS:            apiTLS,
+		apiIdentities:     apiIdentities,
+		internalCtx:       internalCtx,
+		internalCancel:    internalCancel,
+		internalWaitGroup: &sync.WaitGroup{},
+	}
+}
+
+// Start - starts the Vault Transit API.
+func (vA VaultAPISupervisor) Start() {
+	defer vA.daemonWaitGroup.Done()
+
+	vA.internalWaitGroup.Add(1)
+	go vaultAPISupervisorMain(vA)
+
+	<-vA.daemonCtx.Done()
+	vA.Stop()
+	vA.internalWaitGroup.Wait()
+}
+
+// Stop - stops the Vault Transit API by cancelling its context.
+func (vA VaultAPISupervisor) Stop() {
+	vA.internalCancel()
+}
+
+// Restart - restarts the Vault Transit API by cancelling its current context and creating a new one.
+func (vA VaultAPISupervisor) Restart() {
+	vA.internalCancel()
+	time.Sleep(400 * time.Millisecond)
+
+	vA.internalCtx, vA.internalCancel = context.WithCancel(context.Background())
+	vA.daemonWaitGroup.Add(1)
+	go vA.Start()
+}
+
+// vaultAPISupervisorMain - serves the Vault Transit API until the internal context is cancelled.
+func vaultAPISupervisorMain(vA VaultAPISupervisor) {
+	defer vA.internalWaitGroup.Done()
+
+	vA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		VAULT_API_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"Vault Transit API Supervisor starting",
+		map[string]string{"listen": vA.listenAddress, "tls": strconv.FormatBool(vA.apiTLS != nil)},
+	))
+
+	// Vault tokens are ignored, clients are only authenticated by their certificates.
+	//
+	if requireClientCertificates(vA.auditor, VAULT_API_SUPERVISOR_AUDIT_GROUP, "Vault Transit API", vA.listenAddress, vA.apiTLS) &&
+		waitUnsealed(vA.internalCtx, vA.kmsService, vA.auditor, VAULT_API_SUPERVISOR_AUDIT_GROUP) {
+		server := vaulttransit.NewServer(vA.kmsService, vA.auditor, VAULT_API_AUDIT_GROUP, vA.apiIdentities)
+		if err := server.Serve(vA.internalCtx, vA.listenAddress, vA.apiTLS.Config()); err != nil {
+			vA.auditor.RecordEvent(audit.NewEvent(
+				audit.LEVEL_ERROR,
+				VAULT_API_SUPERVISOR_AUDIT_GROUP,
+				audit.TOPIC_LIFECYCLE,
+				"Vault Transit API failed to serve",
+				map[string]string{"listen": vA.listenAddress, "error": err.Error()},
+			))
+		}
+	}
+
+	vA.auditor.RecordEvent(audit.NewEvent(
+		audit.LEVEL_INFO,
+		VAULT_API_SUPERVISOR_AUDIT_GROUP,
+		audit.TOPIC_LIFECYCLE,
+		"Vault Transit API Supervisor stopping",
+		map[string]string{},
+	))
+}
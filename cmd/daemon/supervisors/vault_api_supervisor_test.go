@@ -0,0 +1,23 @@
+package supervisors_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/cmd/daemon/supervisors"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+func TestVaultAPISupervisor_RequiresClientCertificates(t *testing.T) {
+	auditor := &kmstest.Auditor{}
+	service := kmstest.NewService(t, auditor)
+	address := freeAddress(t)
+
+	// Without TLS requiring client certificates the API is refused, not served in plain HTTP.
+	//
+	start(t, func(ctx context.Context, waitGroup *sync.WaitGroup) supervisors.Supervisor {
+		return supervisors.VaultAPISupervisorNew(ctx, waitGroup, auditor, address, service, nil, nil)
+	})
+	expectRefused(t, auditor, "Vault Transit API", address)
+}
@@ -13,7 +13,16 @@ const (
 	LEVEL_WARN  = "WARN"
 	LEVEL_ERROR = "ERROR"
 
-	TOPIC_LIFECYCLE = "LIFECYCLE"
+	TOPIC_LIFECYCLE      = "LIFECYCLE"
+	TOPIC_KEY_MANAGEMENT = "KEY_MANAGEMENT"
+	TOPIC_CRYPTOGRAPHY   = "CRYPTOGRAPHY"
+	TOPIC_KEY_ROTATED    = "KEY_ROTATED"
+	TOPIC_CLI_API        = "CLI_API"
+	TOPIC_REST_API       = "REST_API"
+	TOPIC_GRPC_API       = "GRPC_API"
+	TOPIC_KUBERNETES_KMS = "KUBERNETES_KMS"
+	TOPIC_AWS_API        = "AWS_API"
+	TOPIC_VAULT_API      = "VAULT_API"
 )
 
 type Auditor interface {
@@ -25,6 +34,12 @@ type Auditor interface {
 	Close() error
 }
 
+// Tailer - implemented by auditors able to return their most recently persisted events.
+type Tailer interface {
+	// Tail - returns up to the given number of the last persisted events, oldest first, as they were persisted.
+	Tail(lines int) ([]string, error)
+}
+
 type Event struct {
 	Timestamp time.Time         `json:"timestamp"`
 	Level     string            `json:"level"`
@@ -3,10 +3,17 @@ package audit
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	FILE_SUFFIX = "_audit.log"
+)
+
 type AuditorFile struct {
 	Auditor
 	daemonCtx        context.Context
@@ -96,7 +103,38 @@ func (aF *AuditorFile) Close() error {
 	return nil
 }
 
+// Tail - returns the last persisted events, reading back through older log files when the newest one is too short.
+func (aF *AuditorFile) Tail(lines int) ([]string, error) {
+	logFiles, err := filepath.Glob(filepath.Join(aF.storageDirectory, "*"+FILE_SUFFIX))
+	if err != nil {
+		return nil, err
+	}
+
+	// File names start with their creation time, so sorting them by name sorts them by age.
+	//
+	slices.Sort(logFiles)
+
+	var tail []string
+	for i := len(logFiles) - 1; i >= 0 && len(tail) < lines; i-- {
+		content, err := os.ReadFile(logFiles[i])
+		if err != nil {
+			return nil, err
+		}
+
+		var fileLines []string
+		if trimmed := strings.TrimRight(string(content), "\n"); trimmed != "" {
+			fileLines = strings.Split(trimmed, "\n")
+		}
+		if missing := lines - len(tail); len(fileLines) > missing {
+			fileLines = fileLines[len(fileLines)-missing:]
+		}
+		tail = append(fileLines, tail...)
+	}
+
+	return tail, nil
+}
+
 // getFileName - generates a file name based on the current date.
 func (aF *AuditorFile) getFileName() string {
-	return time.Now().Format(time.RFC3339) + "_audit.log"
+	return time.Now().Format(time.RFC3339) + FILE_SUFFIX
 }
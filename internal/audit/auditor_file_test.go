@@ -0,0 +1,61 @@
+package audit_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+func TestAuditorFile_Tail(t *testing.T) {
+	directory := t.TempDir()
+	logFiles := map[string]string{
+		"2026-01-01T00:00:00Z" + audit.FILE_SUFFIX: "first\nsecond\n",
+		"2026-01-02T00:00:00Z" + audit.FILE_SUFFIX: "third\nfourth\nfifth\n",
+		"2026-01-03T00:00:00Z" + audit.FILE_SUFFIX: "",
+		"unrelated.log": "ignored\n",
+	}
+	for name, content := range logFiles {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	auditor := audit.NewAuditorFile(directory, context.Background())
+
+	scenarios := []struct {
+		name     string
+		lines    int
+		expected []string
+	}{
+		{
+			name:     "Within the newest non-empty file",
+			lines:    2,
+			expected: []string{"fourth", "fifth"},
+		},
+		{
+			name:     "Across files",
+			lines:    4,
+			expected: []string{"second", "third", "fourth", "fifth"},
+		},
+		{
+			name:     "More than persisted",
+			lines:    10,
+			expected: []string{"first", "second", "third", "fourth", "fifth"},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			events, err := auditor.Tail(scenario.lines)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(events, scenario.expected) {
+				t.Errorf("Expected %v, got %v", scenario.expected, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package awskms
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+const (
+	ENCRYPTION_ALGORITHM_SYMMETRIC_DEFAULT = "SYMMETRIC_DEFAULT"
+
+	MESSAGE_TYPE_RAW    = "RAW"
+	MESSAGE_TYPE_DIGEST = "DIGEST"
+
+	ERROR_TYPE_INCORRECT_KEY = "IncorrectKeyException"
+)
+
+var (
+	// keyStates - AWS name of every key state. Destroyed keys no longer exist in AWS, they are reported unavailable.
+	keyStates = map[string]string{
+		kms.KEY_STATE_ENABLED:          "Enabled",
+		kms.KEY_STATE_DISABLED:         "Disabled",
+		kms.KEY_STATE_PENDING_DELETION: "PendingDeletion",
+		kms.KEY_STATE_DESTROYED:        "Unavailable",
+	}
+
+	// dataKeySizes - data key spec generated for every number of bytes AWS clients may ask for.
+	dataKeySizes = map[int]string{
+		16: kms.DATA_KEY_SPEC_AES_128,
+		32: kms.DATA_KEY_SPEC_AES_256,
+	}
+)
+
+func (s *Server) createKey(ctx context.Context, request CreateKeyRequest) (any, error) {
+	spec := request.KeySpec
+	if spec == "" {
+		spec = request.CustomerMasterKeySpec
+	}
+
+	tags := make(map[string]string, len(request.Tags))
+	for _, tag := range request.Tags {
+		tags[tag.TagKey] = tag.TagValue
+	}
+
+	key, err := s.kmsService.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{
+		Spec:     spec,
+		Usage:    request.KeyUsage,
+		Metadata: kms.KeyMetadata{Description: request.Description, Tags: tags},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return KeyMetadataResponse{KeyMetadata: s.keyMetadata(key)}, nil
+}
+
+func (s *Server) describeKey(ctx context.Context, request DescribeKeyRequest) (any, error) {
+	key, err := s.kmsService.KeyStore().GetKey(ctx, keyID(request.KeyId))
+	if err != nil {
+		return nil, err
+	}
+	return KeyMetadataResponse{KeyMetadata: s.keyMetadata(key)}, nil
+}
+
+// listKeys - lists the keys ordered by ID, a page at a time. The marker is the ID of the last key of the previous page.
+func (s *Server) listKeys(ctx context.Context, request ListKeysRequest) (any, error) {
+	limit := request.Limit
+	if limit == 0 {
+		limit = LIST_KEYS_DEFAULT_SIZE
+	}
+	if limit < 1 || limit > LIST_KEYS_MAXIMUM_SIZE {
+		return nil, &Error{Type: ERROR_TYPE_VALIDATION, Message: fmt.Sprintf("limit must be between 1 and %d", LIST_KEYS_MAXIMUM_SIZE)}
+	}
+
+	keys, err := s.kmsService.KeyStore().ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(keys, func(a, b kms.Key) int { return strings.Compare(a.ID, b.ID) })
+
+	response := ListKeysResponse{Keys: []KeyListEntry{}}
+	for _, key := range keys {
+		if request.Marker != "" && key.ID <= request.Marker {
+			continue
+		}
+		if len(response.Keys) == limit {
+			response.Truncated = true
+			response.NextMarker = response.Keys[limit-1].KeyId
+			break
+		}
+		response.Keys = append(response.Keys, KeyListEntry{KeyId: key.ID, KeyArn: s.keyARN(key.ID)})
+	}
+	return response, nil
+}
+
+func (s *Server) createAlias(ctx context.Context, request CreateAliasRequest) (any, error) {
+	if _, err := s.kmsService.KeyStore().CreateAlias(ctx, request.AliasName, keyID(request.TargetKeyId)); err != nil {
+		return nil, err
+	}
+	return struct{}{}, nil
+}
+
+// encrypt - encrypts with a symmetric key by default, or with the public key of an RSA key when an asymmetric
+// algorithm is requested.
+func (s *Server) encrypt(ctx context.Context, request EncryptRequest) (any, error) {
+	if isSymmetric(request.EncryptionAlgorithm) {
+		ciphertext, err := s.kmsService.EncryptPlaintext(ctx, keyID(request.KeyId), request.Plaintext, request.EncryptionContext)
+		if err != nil {
+			return nil, err
+		}
+		return EncryptResponse{
+			KeyId:               s.keyARN(ciphertext.KeyID),
+			CiphertextBlob:      ciphertext.Ciphertext,
+			EncryptionAlgorithm: ENCRYPTION_ALGORITHM_SYMMETRIC_DEFAULT,
+		}, nil
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(ctx, keyID(request.KeyId))
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := s.kmsService.AsymmetricEncrypt(ctx, key.ID, request.Plaintext, request.EncryptionAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptResponse{
+		KeyId:               s.keyARN(key.ID),
+		CiphertextBlob:      ciphertext,
+		EncryptionAlgorithm: request.EncryptionAlgorithm,
+	}, nil
+}
+
+// decrypt - decrypts a symmetric ciphertext, which names its key, or an asymmetric one with the key of the request.
+func (s *Server) decrypt(ctx context.Context, request DecryptRequest) (any, error) {
+	if !isSymmetric(request.EncryptionAlgorithm) {
+		key, err := s.kmsService.KeyStore().GetKey(ctx, keyID(request.KeyId))
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := s.kmsService.AsymmetricDecrypt(ctx, key.ID, request.CiphertextBlob, request.EncryptionAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		return DecryptResponse{KeyId: s.keyARN(key.ID), Plaintext: plaintext, EncryptionAlgorithm: request.EncryptionAlgorithm}, nil
+	}
+
+	sourceKeyID, err := s.ciphertextKeyID(ctx, request.CiphertextBlob, request.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.kmsService.Decrypt(ctx, request.CiphertextBlob, request.EncryptionContext)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptResponse{
+		KeyId:               s.keyARN(sourceKeyID),
+		Plaintext:           plaintext,
+		EncryptionAlgorithm: ENCRYPTION_ALGORITHM_SYMMETRIC_DEFAULT,
+	}, nil
+}
+
+func (s *Server) generateDataKey(ctx context.Context, request GenerateDataKeyRequest) (any, error) {
+	spec := request.KeySpec
+	if spec == "" {
+		var ok bool
+		if spec, ok = dataKeySizes[request.NumberOfBytes]; !ok {
+			return nil, &Error{Type: ERROR_TYPE_VALIDATION, Message: "KeySpec or a NumberOfBytes of 16 or 32 is required"}
+		}
+	}
+
+	dataKey, err := s.kmsService.GenerateDataKey(ctx, keyID(request.KeyId), spec, request.EncryptionContext)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateDataKeyResponse{
+		KeyId:          s.keyARN(dataKey.KeyID),
+		Plaintext:      dataKey.Plaintext,
+		CiphertextBlob: dataKey.Ciphertext,
+	}, nil
+}
+
+// reEncrypt - decrypts a symmetric ciphertext and encrypts its plaintext under another key, without the plaintext
+// leaving the server.
+func (s *Server) reEncrypt(ctx context.Context, request ReEncryptRequest) (any, error) {
+	sourceKeyID, err := s.ciphertextKeyID(ctx, request.CiphertextBlob, request.SourceKeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := s.kmsService.Decrypt(ctx, request.CiphertextBlob, request.SourceEncryptionContext)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	ciphertext, err := s.kmsService.EncryptPlaintext(ctx, keyID(request.DestinationKeyId), plaintext, request.DestinationEncryptionContext)
+	if err != nil {
+		return nil, err
+	}
+	return ReEncryptResponse{
+		SourceKeyId:                    s.keyARN(sourceKeyID),
+		KeyId:                          s.keyARN(ciphertext.KeyID),
+		CiphertextBlob:                 ciphertext.Ciphertext,
+		SourceEncryptionAlgorithm:      ENCRYPTION_ALGORITHM_SYMMETRIC_DEFAULT,
+		DestinationEncryptionAlgorithm: ENCRYPTION_ALGORITHM_SYMMETRIC_DEFAULT,
+	}, nil
+}
+
+func (s *Server) sign(ctx context.Context, request SignRequest) (any, error) {
+	digest, err := messageDigest(request.SigningAlgorithm, request.MessageType, request.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := s.kmsService.Sign(ctx, keyID(request.KeyId), digest, request.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	return SignResponse{
+		KeyId:            s.keyARN(signature.KeyID),
+		Signature:        signature.Signature,
+		SigningAlgorithm: signature.Algorithm,
+	}, nil
+}
+
+// verify - as AWS KMS, answers an invalid signature with an error rather than a negative result.
+func (s *Server) verify(ctx context.Context, request VerifyRequest) (any, error) {
+	digest, err := messageDigest(request.SigningAlgorithm, request.MessageType, request.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(ctx, keyID(request.KeyId))
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := s.kmsService.Verify(ctx, key.ID, digest, request.Signature, request.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, &Error{Type: ERROR_TYPE_INVALID_SIGNATURE, Message: "signature is not valid"}
+	}
+	return VerifyResponse{KeyId: s.keyARN(key.ID), SignatureValid: true, SigningAlgorithm: request.SigningAlgorithm}, nil
+}
+
+// ciphertextKeyID - ID of the key a symmetric ciphertext was encrypted under, which must be the expected key when one
+// is given.
+func (s *Server) ciphertextKeyID(ctx context.Context, ciphertext []byte, expectedKeyID string) (string, error) {
+	header, _, _, err := kms.ParseCiphertext(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if expectedKeyID == "" {
+		return header.KeyID, nil
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(ctx, keyID(expectedKeyID))
+	if err != nil {
+		return "", err
+	}
+	if key.ID != header.KeyID {
+		return "", &Error{Type: ERROR_TYPE_INCORRECT_KEY, Message: "ciphertext was not encrypted under the given key"}
+	}
+	return header.KeyID, nil
+}
+
+// keyMetadata - converts a key into its AWS representation.
+func (s *Server) keyMetadata(key kms.Key) KeyMetadata {
+	metadata := KeyMetadata{
+		AWSAccountId:          ACCOUNT_ID,
+		KeyId:                 key.ID,
+		Arn:                   s.keyARN(key.ID),
+		CreationDate:          epochTime(key.CreatedAt),
+		Enabled:               key.State == kms.KEY_STATE_ENABLED,
+		Description:           key.Metadata.Description,
+		KeyUsage:              key.Usage,
+		KeyState:              keyStates[key.State],
+		Origin:                "AWS_KMS",
+		KeyManager:            "CUSTOMER",
+		KeySpec:               key.Spec,
+		CustomerMasterKeySpec: key.Spec,
+	}
+	if key.DeletionDate != nil {
+		deletionDate := epochTime(*key.DeletionDate)
+		metadata.DeletionDate = &deletionDate
+	}
+
+	switch key.Usage {
+	case kms.KEY_USAGE_ENCRYPT_DECRYPT:
+		metadata.EncryptionAlgorithms = []string{ENCRYPTION_ALGORITHM_SYMMETRIC_DEFAULT}
+		if key.Spec != kms.KEY_SPEC_SYMMETRIC_DEFAULT {
+			metadata.EncryptionAlgorithms = []string{kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256}
+		}
+	case kms.KEY_USAGE_SIGN_VERIFY:
+		metadata.SigningAlgorithms = kms.SigningAlgorithms(key.Spec)
+	case kms.KEY_USAGE_GENERATE_VERIFY_MAC:
+		metadata.MacAlgorithms = []string{strings.Replace(key.Spec, "HMAC_", "HMAC_SHA_", 1)}
+	}
+	return metadata
+}
+
+// keyARN - ARN reported for a key.
+func (s *Server) keyARN(keyID string) string {
+	return fmt.Sprintf("%s%s:%s:key/%s", ARN_PREFIX, s.region, ACCOUNT_ID, keyID)
+}
+
+// keyID - key ID or alias named by an AWS key identifier, which may be the ARN of a key or of an alias.
+func keyID(identifier string) string {
+	if !strings.HasPrefix(identifier, ARN_PREFIX) {
+		return identifier
+	}
+
+	resource := identifier[strings.LastIndex(identifier, ":")+1:]
+	return strings.TrimPrefix(resource, "key/")
+}
+
+// isSymmetric - reports whether the encryption algorithm names symmetric encryption, the default.
+func isSymmetric(algorithm string) bool {
+	return algorithm == "" || algorithm == ENCRYPTION_ALGORITHM_SYMMETRIC_DEFAULT
+}
+
+// messageDigest - digest to sign for a message, hashing raw messages with the algorithm's hash function. Algorithms
+// without one sign the message itself.
+func messageDigest(algorithm, messageType string, message []byte) ([]byte, error) {
+	switch messageType {
+	case "", MESSAGE_TYPE_RAW:
+		hash := kms.SigningHash(algorithm)
+		if hash == 0 {
+			return message, nil
+		}
+		digest := hash.New()
+		digest.Write(message)
+		return digest.Sum(nil), nil
+	case MESSAGE_TYPE_DIGEST:
+		return message, nil
+	default:
+		return nil, &Error{Type: ERROR_TYPE_VALIDATION, Message: "unsupported message type " + messageType}
+	}
+}
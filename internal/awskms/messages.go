@@ -0,0 +1,174 @@
+package awskms
+
+import (
+	"strconv"
+	"time"
+)
+
+// Request and response shapes of the AWS KMS JSON protocol. Field names follow AWS, blobs are base64 encoded and
+// timestamps are seconds since the epoch.
+
+// epochTime - timestamp encoded as seconds since the epoch.
+type epochTime time.Time
+
+func (e epochTime) MarshalJSON() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(time.Time(e).UnixMilli())/1000, 'f', -1, 64), nil
+}
+
+func (e *epochTime) UnmarshalJSON(b []byte) error {
+	seconds, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return err
+	}
+	*e = epochTime(time.UnixMilli(int64(seconds * 1000)).UTC())
+	return nil
+}
+
+// ErrorResponse - body of every failed request.
+type ErrorResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+type Tag struct {
+	TagKey   string `json:"TagKey"`
+	TagValue string `json:"TagValue"`
+}
+
+type KeyMetadata struct {
+	AWSAccountId          string     `json:"AWSAccountId"`
+	KeyId                 string     `json:"KeyId"`
+	Arn                   string     `json:"Arn"`
+	CreationDate          epochTime  `json:"CreationDate"`
+	Enabled               bool       `json:"Enabled"`
+	Description           string     `json:"Description"`
+	KeyUsage              string     `json:"KeyUsage"`
+	KeyState              string     `json:"KeyState"`
+	DeletionDate          *epochTime `json:"DeletionDate,omitempty"`
+	Origin                string     `json:"Origin"`
+	KeyManager            string     `json:"KeyManager"`
+	KeySpec               string     `json:"KeySpec"`
+	CustomerMasterKeySpec string     `json:"CustomerMasterKeySpec"`
+	EncryptionAlgorithms  []string   `json:"EncryptionAlgorithms,omitempty"`
+	SigningAlgorithms     []string   `json:"SigningAlgorithms,omitempty"`
+	MacAlgorithms         []string   `json:"MacAlgorithms,omitempty"`
+}
+
+type CreateKeyRequest struct {
+	Description           string `json:"Description"`
+	KeySpec               string `json:"KeySpec"`
+	CustomerMasterKeySpec string `json:"CustomerMasterKeySpec"` // deprecated name of KeySpec.
+	KeyUsage              string `json:"KeyUsage"`
+	Tags                  []Tag  `json:"Tags"`
+}
+
+type DescribeKeyRequest struct {
+	KeyId string `json:"KeyId"`
+}
+
+type KeyMetadataResponse struct {
+	KeyMetadata KeyMetadata `json:"KeyMetadata"`
+}
+
+type ListKeysRequest struct {
+	Limit  int    `json:"Limit"`
+	Marker string `json:"Marker"`
+}
+
+type KeyListEntry struct {
+	KeyId  string `json:"KeyId"`
+	KeyArn string `json:"KeyArn"`
+}
+
+type ListKeysResponse struct {
+	Keys       []KeyListEntry `json:"Keys"`
+	Truncated  bool           `json:"Truncated"`
+	NextMarker string         `json:"NextMarker,omitempty"`
+}
+
+type CreateAliasRequest struct {
+	AliasName   string `json:"AliasName"`
+	TargetKeyId string `json:"TargetKeyId"`
+}
+
+type EncryptRequest struct {
+	KeyId               string            `json:"KeyId"`
+	Plaintext           []byte            `json:"Plaintext"`
+	EncryptionContext   map[string]string `json:"EncryptionContext"`
+	EncryptionAlgorithm string            `json:"EncryptionAlgorithm"`
+}
+
+type EncryptResponse struct {
+	KeyId               string `json:"KeyId"`
+	CiphertextBlob      []byte `json:"CiphertextBlob"`
+	EncryptionAlgorithm string `json:"EncryptionAlgorithm"`
+}
+
+type DecryptRequest struct {
+	KeyId               string            `json:"KeyId"`
+	CiphertextBlob      []byte            `json:"CiphertextBlob"`
+	EncryptionContext   map[string]string `json:"EncryptionContext"`
+	EncryptionAlgorithm string            `json:"EncryptionAlgorithm"`
+}
+
+type DecryptResponse struct {
+	KeyId               string `json:"KeyId"`
+	Plaintext           []byte `json:"Plaintext"`
+	EncryptionAlgorithm string `json:"EncryptionAlgorithm"`
+}
+
+type GenerateDataKeyRequest struct {
+	KeyId             string            `json:"KeyId"`
+	KeySpec           string            `json:"KeySpec"`
+	NumberOfBytes     int               `json:"NumberOfBytes"`
+	EncryptionContext map[string]string `json:"EncryptionContext"`
+}
+
+type GenerateDataKeyResponse struct {
+	KeyId          string `json:"KeyId"`
+	Plaintext      []byte `json:"Plaintext"`
+	CiphertextBlob []byte `json:"CiphertextBlob"`
+}
+
+type ReEncryptRequest struct {
+	CiphertextBlob               []byte            `json:"CiphertextBlob"`
+	SourceKeyId                  string            `json:"SourceKeyId"`
+	SourceEncryptionContext      map[string]string `json:"SourceEncryptionContext"`
+	DestinationKeyId             string            `json:"DestinationKeyId"`
+	DestinationEncryptionContext map[string]string `json:"DestinationEncryptionContext"`
+}
+
+type ReEncryptResponse struct {
+	SourceKeyId                    string `json:"SourceKeyId"`
+	KeyId                          string `json:"KeyId"`
+	CiphertextBlob                 []byte `json:"CiphertextBlob"`
+	SourceEncryptionAlgorithm      string `json:"SourceEncryptionAlgorithm"`
+	DestinationEncryptionAlgorithm string `json:"DestinationEncryptionAlgorithm"`
+}
+
+type SignRequest struct {
+	KeyId            string `json:"KeyId"`
+	Message          []byte `json:"Message"`
+	MessageType      string `json:"MessageType"`
+	SigningAlgorithm string `json:"SigningAlgorithm"`
+}
+
+type SignResponse struct {
+	KeyId            string `json:"KeyId"`
+	Signature        []byte `json:"Signature"`
+	SigningAlgorithm string `json:"SigningAlgorithm"`
+}
+
+type VerifyRequest struct {
+	KeyId            string `json:"KeyId"`
+	Message          []byte `json:"Message"`
+	MessageType      string `json:"MessageType"`
+	Signature        []byte `json:"Signature"`
+	SigningAlgorithm string `json:"SigningAlgorithm"`
+}
+
+type VerifyResponse struct {
+	KeyId            string `json:"KeyId"`
+	SignatureValid   bool   `json:"SignatureValid"`
+	SigningAlgorithm string `json:"SigningAlgorithm"`
+}
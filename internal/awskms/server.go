@@ -0,0 +1,222 @@
+// Package awskms serves the KMS over the AWS KMS JSON protocol, so AWS SDKs and tools can use OpenKMS by overriding
+// their endpoint. AWS signatures are never verified, any client reaching the listener can sign with made up
+// credentials: clients are only authenticated by their TLS certificates, so the daemon refuses to serve the API unless
+// the listener requires them.
+package awskms
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/httpapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+const (
+	HEADER_TARGET          = "X-Amz-Target"
+	HEADER_REQUEST_ID      = "X-Amzn-Requestid"
+	HEADER_ERROR_TYPE      = "X-Amzn-Errortype"
+	HEADER_INVOCATION_ID   = "Amz-Sdk-Invocation-Id"
+	TARGET_PREFIX          = "TrentService."
+	CONTENT_TYPE_AMZ_JSON  = "application/x-amz-json-1.1"
+	ARN_PREFIX             = "arn:aws:kms:"
+	DEFAULT_REGION         = "us-east-1"
+	ACCOUNT_ID             = "000000000000"
+	LIST_KEYS_DEFAULT_SIZE = 100
+	LIST_KEYS_MAXIMUM_SIZE = 1000
+
+	ERROR_TYPE_NOT_FOUND          = "NotFoundException"
+	ERROR_TYPE_ALREADY_EXISTS     = "AlreadyExistsException"
+	ERROR_TYPE_VALIDATION         = "ValidationException"
+	ERROR_TYPE_INVALID_CIPHERTEXT = "InvalidCiphertextException"
+	ERROR_TYPE_INVALID_KEY_USAGE  = "InvalidKeyUsageException"
+	ERROR_TYPE_INVALID_STATE      = "KMSInvalidStateException"
+	ERROR_TYPE_DISABLED           = "DisabledException"
+	ERROR_TYPE_INVALID_SIGNATURE  = "KMSInvalidSignatureException"
+	ERROR_TYPE_ACCESS_DENIED      = "AccessDeniedException"
+	ERROR_TYPE_UNKNOWN_OPERATION  = "UnknownOperationException"
+	ERROR_TYPE_INTERNAL           = "KMSInternalException"
+)
+
+var (
+	// credentialPattern - access key ID in the credential scope of an AWS signature, recorded but not verified.
+	credentialPattern = regexp.MustCompile(`Credential=([A-Za-z0-9]+)/`)
+
+	// errorTypes - AWS error type answered for every KMS error code.
+	errorTypes = map[string]string{
+		kms.ERROR_CODE_NOT_FOUND:           ERROR_TYPE_NOT_FOUND,
+		kms.ERROR_CODE_ALREADY_EXISTS:      ERROR_TYPE_ALREADY_EXISTS,
+		kms.ERROR_CODE_INVALID_ARGUMENT:    ERROR_TYPE_VALIDATION,
+		kms.ERROR_CODE_FAILED_PRECONDITION: ERROR_TYPE_INVALID_STATE,
+		kms.ERROR_CODE_INTERNAL:            ERROR_TYPE_INTERNAL,
+	}
+)
+
+// Error - AWS KMS error answered to the client.
+type Error struct {
+	Type    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Type + ": " + e.Message
+}
+
+// action - handler of an AWS KMS action.
+type action func(ctx context.Context, body []byte) (any, error)
+
+// Server - serves the KMS as the AWS KMS JSON protocol.
+type Server struct {
+	kmsService *kms.Service
+	auditing   httpapi.Auditing
+	region     string
+	identities *tlsconfig.IdentityMapper // maps client certificates to caller identities, nil accepting any subject.
+	actions    map[string]action
+	app        *fiber.App
+}
+
+// NewServer - creates the server, reporting ARNs in the given region, the default one when empty.
+func NewServer(kmsService *kms.Service, auditor audit.Auditor, auditGroup, region string, identities *tlsconfig.IdentityMapper) *Server {
+	if region == "" {
+		region = DEFAULT_REGION
+	}
+
+	s := &Server{
+		kmsService: kmsService,
+		auditing:   httpapi.Auditing{Auditor: auditor, Group: auditGroup, Topic: audit.TOPIC_AWS_API, Message: "AWS KMS API request handled"},
+		region:     region,
+		identities: identities,
+	}
+
+	s.actions = map[string]action{
+		"CreateKey":       handle(s.createKey),
+		"DescribeKey":     handle(s.describeKey),
+		"ListKeys":        handle(s.listKeys),
+		"CreateAlias":     handle(s.createAlias),
+		"Encrypt":         handle(s.encrypt),
+		"Decrypt":         handle(s.decrypt),
+		"GenerateDataKey": handle(s.generateDataKey),
+		"ReEncrypt":       handle(s.reEncrypt),
+		"Sign":            handle(s.sign),
+		"Verify":          handle(s.verify),
+	}
+
+	s.app = httpapi.NewApp(nil)
+	s.app.Post("/", s.serveRequest)
+
+	return s
+}
+
+// App - Fiber application serving the API.
+func (s *Server) App() *fiber.App {
+	return s.app
+}
+
+// Serve - listens on the address, over TLS when a configuration is given, until the context is done.
+func (s *Server) Serve(ctx context.Context, address string, tlsConfig *tls.Config) error {
+	return httpapi.Serve(ctx, s.app, address, tlsConfig)
+}
+
+// ServeListener - serves the connections accepted by the listener until the context is done.
+func (s *Server) ServeListener(ctx context.Context, listener net.Listener) error {
+	return httpapi.ServeListener(ctx, s.app, listener)
+}
+
+// serveRequest - runs the action named by the target header and records its outcome.
+func (s *Server) serveRequest(c *fiber.Ctx) error {
+	requestID := kms.ClientRequestID(c.Get(HEADER_INVOCATION_ID))
+	c.Set(HEADER_REQUEST_ID, requestID)
+	ctx := kms.WithRequestID(c.UserContext(), requestID)
+
+	actionName := strings.TrimPrefix(c.Get(HEADER_TARGET), TARGET_PREFIX)
+	labels := map[string]string{
+		"requestId":     requestID,
+		"action":        actionName,
+		"remoteAddress": c.IP(),
+	}
+	if match := credentialPattern.FindStringSubmatch(c.Get(fiber.HeaderAuthorization)); match != nil {
+		labels["accessKeyId"] = match[1]
+	}
+
+	ctx, err := httpapi.Authenticate(ctx, c, s.identities, labels)
+	var response any
+	if err != nil {
+		err = &Error{Type: ERROR_TYPE_ACCESS_DENIED, Message: err.Error()}
+	} else {
+		if run, ok := s.actions[actionName]; ok {
+			response, err = run(ctx, c.Body())
+		} else {
+			err = &Error{Type: ERROR_TYPE_UNKNOWN_OPERATION, Message: "unsupported action " + c.Get(HEADER_TARGET)}
+		}
+	}
+
+	status := fiber.StatusOK
+	c.Set(fiber.HeaderContentType, CONTENT_TYPE_AMZ_JSON)
+	if err != nil {
+		labels["error"] = err.Error()
+		awsError, errorStatus := toError(err)
+		status = errorStatus
+		c.Set(HEADER_ERROR_TYPE, awsError.Type)
+		response = ErrorResponse{Type: awsError.Type, Message: awsError.Message}
+	}
+	s.auditing.Record(ctx, status, labels)
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return c.Status(status).Send(body)
+}
+
+// toError - converts an error into the AWS error and HTTP status answered for it.
+func toError(err error) (*Error, int) {
+	var awsError *Error
+	if errors.As(err, &awsError) {
+		if awsError.Type == ERROR_TYPE_INTERNAL {
+			return awsError, fiber.StatusInternalServerError
+		}
+		return awsError, fiber.StatusBadRequest
+	}
+
+	var stateError *kms.KeyStateError
+	switch {
+	case errors.As(err, &stateError) && stateError.State == kms.KEY_STATE_DISABLED:
+		return &Error{Type: ERROR_TYPE_DISABLED, Message: err.Error()}, fiber.StatusBadRequest
+	case errors.Is(err, kms.ErrInvalidCiphertext):
+		return &Error{Type: ERROR_TYPE_INVALID_CIPHERTEXT, Message: err.Error()}, fiber.StatusBadRequest
+	case errors.Is(err, kms.ErrIncompatibleKey):
+		return &Error{Type: ERROR_TYPE_INVALID_KEY_USAGE, Message: err.Error()}, fiber.StatusBadRequest
+	}
+
+	code := kms.ErrorCode(err)
+	switch code {
+	case kms.ERROR_CODE_INTERNAL:
+		return &Error{Type: ERROR_TYPE_INTERNAL, Message: httpapi.INTERNAL_ERROR_MESSAGE}, fiber.StatusInternalServerError
+	case kms.ERROR_CODE_ABORTED:
+		// AWS KMS has no conflict error, a server error is answered instead so the AWS SDKs retry it.
+		//
+		return &Error{Type: ERROR_TYPE_INTERNAL, Message: err.Error()}, fiber.StatusServiceUnavailable
+	}
+	return &Error{Type: errorTypes[code], Message: err.Error()}, fiber.StatusBadRequest
+}
+
+// handle - adapts an action taking its decoded request into an action taking the raw body.
+func handle[R any](run func(ctx context.Context, request R) (any, error)) action {
+	return func(ctx context.Context, body []byte) (any, error) {
+		var request R
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &request); err != nil {
+				return nil, &Error{Type: ERROR_TYPE_VALIDATION, Message: "malformed request body: " + err.Error()}
+			}
+		}
+		return run(ctx, request)
+	}
+}
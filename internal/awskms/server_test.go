@@ -0,0 +1,310 @@
+package awskms_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/awskms"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+// newServer - AWS KMS API backed by a file key store in a temporary directory.
+func newServer(t *testing.T) *awskms.Server {
+	t.Helper()
+
+	return awskms.NewServer(kmstest.NewService(t, nil), nil, "TEST", "eu-west-1", nil)
+}
+
+// call - runs an action as an AWS SDK would and decodes the response body, returning the error type of a failed
+// request.
+func call(t *testing.T, server *awskms.Server, action string, body any, response any) string {
+	t.Helper()
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to encode body: %v", err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(encoded))
+	request.Header.Set("Content-Type", awskms.CONTENT_TYPE_AMZ_JSON)
+	request.Header.Set(awskms.HEADER_TARGET, awskms.TARGET_PREFIX+action)
+
+	httpResponse, err := server.App().Test(request, -1)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		var errorResponse awskms.ErrorResponse
+		if err := json.NewDecoder(httpResponse.Body).Decode(&errorResponse); err != nil {
+			t.Fatalf("Failed to decode error response: %v", err)
+		}
+		if errorResponse.Type != httpResponse.Header.Get(awskms.HEADER_ERROR_TYPE) {
+			t.Errorf("Expected error type header %q, got %q", errorResponse.Type, httpResponse.Header.Get(awskms.HEADER_ERROR_TYPE))
+		}
+		return errorResponse.Type
+	}
+	if response != nil {
+		if err := json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return ""
+}
+
+// createKey - creates a key and returns its metadata.
+func createKey(t *testing.T, server *awskms.Server, request awskms.CreateKeyRequest) awskms.KeyMetadata {
+	t.Helper()
+
+	var response awskms.KeyMetadataResponse
+	if errorType := call(t, server, "CreateKey", request, &response); errorType != "" {
+		t.Fatalf("Failed to create key: %s", errorType)
+	}
+	return response.KeyMetadata
+}
+
+func TestServer_EncryptionRoundTrip(t *testing.T) {
+	server := newServer(t)
+	source := createKey(t, server, awskms.CreateKeyRequest{Description: "source", Tags: []awskms.Tag{{TagKey: "team", TagValue: "payments"}}})
+	destination := createKey(t, server, awskms.CreateKeyRequest{Description: "destination"})
+
+	if source.KeyState != "Enabled" || !source.Enabled || source.KeySpec != kms.KEY_SPEC_SYMMETRIC_DEFAULT {
+		t.Errorf("Unexpected key metadata %+v", source)
+	}
+	if expected := "arn:aws:kms:eu-west-1:000000000000:key/" + source.KeyId; source.Arn != expected {
+		t.Errorf("Expected ARN %q, got %q", expected, source.Arn)
+	}
+
+	encryptionContext := map[string]string{"purpose": "test"}
+	var encrypted awskms.EncryptResponse
+	if errorType := call(t, server, "Encrypt", awskms.EncryptRequest{KeyId: source.KeyId, Plaintext: []byte("secret"), EncryptionContext: encryptionContext}, &encrypted); errorType != "" {
+		t.Fatalf("Expected encryption to succeed, got %s", errorType)
+	}
+	if encrypted.KeyId != source.Arn {
+		t.Errorf("Expected key %q, got %q", source.Arn, encrypted.KeyId)
+	}
+
+	// Symmetric ciphertexts name their key, the key ID is optional when decrypting.
+	//
+	var decrypted awskms.DecryptResponse
+	if errorType := call(t, server, "Decrypt", awskms.DecryptRequest{CiphertextBlob: encrypted.CiphertextBlob, EncryptionContext: encryptionContext}, &decrypted); errorType != "" || string(decrypted.Plaintext) != "secret" {
+		t.Errorf("Expected decryption to succeed, got %q %s", decrypted.Plaintext, errorType)
+	}
+	if errorType := call(t, server, "Decrypt", awskms.DecryptRequest{CiphertextBlob: encrypted.CiphertextBlob}, nil); errorType != awskms.ERROR_TYPE_INVALID_CIPHERTEXT {
+		t.Errorf("Expected decryption without the encryption context to fail, got %q", errorType)
+	}
+
+	var reEncrypted awskms.ReEncryptResponse
+	if errorType := call(t, server, "ReEncrypt", awskms.ReEncryptRequest{
+		CiphertextBlob:          encrypted.CiphertextBlob,
+		SourceEncryptionContext: encryptionContext,
+		DestinationKeyId:        destination.Arn,
+	}, &reEncrypted); errorType != "" {
+		t.Fatalf("Expected re-encryption to succeed, got %s", errorType)
+	}
+	if reEncrypted.SourceKeyId != source.Arn || reEncrypted.KeyId != destination.Arn {
+		t.Errorf("Unexpected re-encryption keys %q %q", reEncrypted.SourceKeyId, reEncrypted.KeyId)
+	}
+	if errorType := call(t, server, "Decrypt", awskms.DecryptRequest{KeyId: destination.KeyId, CiphertextBlob: reEncrypted.CiphertextBlob}, &decrypted); errorType != "" || string(decrypted.Plaintext) != "secret" {
+		t.Errorf("Expected re-encrypted ciphertext to decrypt, got %q %s", decrypted.Plaintext, errorType)
+	}
+
+	var dataKey awskms.GenerateDataKeyResponse
+	if errorType := call(t, server, "GenerateDataKey", awskms.GenerateDataKeyRequest{KeyId: source.KeyId, NumberOfBytes: 32}, &dataKey); errorType != "" {
+		t.Fatalf("Expected data key generation to succeed, got %s", errorType)
+	}
+	if len(dataKey.Plaintext) != 32 {
+		t.Errorf("Expected a 32 byte data key, got %d bytes", len(dataKey.Plaintext))
+	}
+	if errorType := call(t, server, "Decrypt", awskms.DecryptRequest{CiphertextBlob: dataKey.CiphertextBlob}, &decrypted); errorType != "" || !bytes.Equal(decrypted.Plaintext, dataKey.Plaintext) {
+		t.Errorf("Expected data key to decrypt, got %s", errorType)
+	}
+}
+
+func TestServer_KeyIdentifiers(t *testing.T) {
+	server := newServer(t)
+	key := createKey(t, server, awskms.CreateKeyRequest{})
+
+	if errorType := call(t, server, "CreateAlias", awskms.CreateAliasRequest{AliasName: "alias/app", TargetKeyId: key.Arn}, nil); errorType != "" {
+		t.Fatalf("Expected alias creation to succeed, got %s", errorType)
+	}
+
+	scenarios := []string{
+		key.KeyId,
+		key.Arn,
+		"alias/app",
+		"arn:aws:kms:eu-west-1:000000000000:alias/app",
+	}
+
+	for _, identifier := range scenarios {
+		var response awskms.KeyMetadataResponse
+		if errorType := call(t, server, "DescribeKey", awskms.DescribeKeyRequest{KeyId: identifier}, &response); errorType != "" || response.KeyMetadata.KeyId != key.KeyId {
+			t.Errorf("Expected %q to name key %q, got %q %s", identifier, key.KeyId, response.KeyMetadata.KeyId, errorType)
+		}
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	server := newServer(t)
+	key := createKey(t, server, awskms.CreateKeyRequest{})
+	other := createKey(t, server, awskms.CreateKeyRequest{})
+	signingKey := createKey(t, server, awskms.CreateKeyRequest{KeySpec: kms.KEY_SPEC_ECC_NIST_P256, KeyUsage: kms.KEY_USAGE_SIGN_VERIFY})
+
+	var encrypted awskms.EncryptResponse
+	if errorType := call(t, server, "Encrypt", awskms.EncryptRequest{KeyId: key.KeyId, Plaintext: []byte("secret")}, &encrypted); errorType != "" {
+		t.Fatalf("Expected encryption to succeed, got %s", errorType)
+	}
+
+	scenarios := []struct {
+		name         string
+		action       string
+		body         any
+		expectedType string
+	}{
+		{"unknown action", "DeleteImportedKeyMaterial", struct{}{}, awskms.ERROR_TYPE_UNKNOWN_OPERATION},
+		{"unknown key", "DescribeKey", awskms.DescribeKeyRequest{KeyId: "missing"}, awskms.ERROR_TYPE_NOT_FOUND},
+		{"unknown key spec", "CreateKey", awskms.CreateKeyRequest{KeySpec: "RSA_1024"}, awskms.ERROR_TYPE_VALIDATION},
+		{"existing alias", "CreateAlias", awskms.CreateAliasRequest{AliasName: "alias/app", TargetKeyId: key.KeyId}, ""},
+		{"duplicate alias", "CreateAlias", awskms.CreateAliasRequest{AliasName: "alias/app", TargetKeyId: other.KeyId}, awskms.ERROR_TYPE_ALREADY_EXISTS},
+		{"malformed ciphertext", "Decrypt", awskms.DecryptRequest{CiphertextBlob: []byte("garbage")}, awskms.ERROR_TYPE_INVALID_CIPHERTEXT},
+		{"incorrect key", "Decrypt", awskms.DecryptRequest{KeyId: other.KeyId, CiphertextBlob: encrypted.CiphertextBlob}, awskms.ERROR_TYPE_INCORRECT_KEY},
+		{"wrong key usage", "Encrypt", awskms.EncryptRequest{KeyId: signingKey.KeyId, Plaintext: []byte("x")}, awskms.ERROR_TYPE_INVALID_KEY_USAGE},
+		{"data key size", "GenerateDataKey", awskms.GenerateDataKeyRequest{KeyId: key.KeyId, NumberOfBytes: 24}, awskms.ERROR_TYPE_VALIDATION},
+		{"list limit", "ListKeys", awskms.ListKeysRequest{Limit: 5000}, awskms.ERROR_TYPE_VALIDATION},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if errorType := call(t, server, scenario.action, scenario.body, nil); errorType != scenario.expectedType {
+				t.Errorf("Expected error type %q, got %q", scenario.expectedType, errorType)
+			}
+		})
+	}
+}
+
+func TestServer_DisabledKey(t *testing.T) {
+	service := kmstest.NewService(t, nil)
+	server := awskms.NewServer(service, nil, "TEST", "", nil)
+
+	key := createKey(t, server, awskms.CreateKeyRequest{})
+	if _, err := service.KeyStore().DisableKey(context.Background(), key.KeyId); err != nil {
+		t.Fatalf("Failed to disable key: %v", err)
+	}
+
+	if errorType := call(t, server, "Encrypt", awskms.EncryptRequest{KeyId: key.KeyId, Plaintext: []byte("x")}, nil); errorType != awskms.ERROR_TYPE_DISABLED {
+		t.Errorf("Expected error type %q, got %q", awskms.ERROR_TYPE_DISABLED, errorType)
+	}
+
+	var response awskms.KeyMetadataResponse
+	call(t, server, "DescribeKey", awskms.DescribeKeyRequest{KeyId: key.KeyId}, &response)
+	if response.KeyMetadata.KeyState != "Disabled" || response.KeyMetadata.Enabled {
+		t.Errorf("Expected a disabled key, got %+v", response.KeyMetadata)
+	}
+	if expected := "arn:aws:kms:" + awskms.DEFAULT_REGION + ":"; response.KeyMetadata.Arn[:len(expected)] != expected {
+		t.Errorf("Expected an ARN in the default region, got %q", response.KeyMetadata.Arn)
+	}
+}
+
+func TestServer_SignVerify(t *testing.T) {
+	server := newServer(t)
+	key := createKey(t, server, awskms.CreateKeyRequest{KeySpec: kms.KEY_SPEC_ECC_NIST_P256, KeyUsage: kms.KEY_USAGE_SIGN_VERIFY})
+
+	if len(key.SigningAlgorithms) == 0 {
+		t.Errorf("Expected signing algorithms in the key metadata, got %+v", key)
+	}
+
+	message := []byte("message to sign")
+	digest := sha256.Sum256(message)
+
+	scenarios := []struct {
+		name        string
+		messageType string
+		message     []byte
+	}{
+		{"raw", awskms.MESSAGE_TYPE_RAW, message},
+		{"digest", awskms.MESSAGE_TYPE_DIGEST, digest[:]},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			var signed awskms.SignResponse
+			if errorType := call(t, server, "Sign", awskms.SignRequest{
+				KeyId:            key.KeyId,
+				Message:          scenario.message,
+				MessageType:      scenario.messageType,
+				SigningAlgorithm: kms.SIGNING_ALGORITHM_ECDSA_SHA_256,
+			}, &signed); errorType != "" {
+				t.Fatalf("Expected signing to succeed, got %s", errorType)
+			}
+
+			// A signature over the raw message is the signature over its digest.
+			//
+			var verified awskms.VerifyResponse
+			if errorType := call(t, server, "Verify", awskms.VerifyRequest{
+				KeyId:            key.Arn,
+				Message:          digest[:],
+				MessageType:      awskms.MESSAGE_TYPE_DIGEST,
+				Signature:        signed.Signature,
+				SigningAlgorithm: kms.SIGNING_ALGORITHM_ECDSA_SHA_256,
+			}, &verified); errorType != "" || !verified.SignatureValid {
+				t.Errorf("Expected a valid signature, got %+v %s", verified, errorType)
+			}
+
+			if errorType := call(t, server, "Verify", awskms.VerifyRequest{
+				KeyId:            key.KeyId,
+				Message:          []byte("another message"),
+				Signature:        signed.Signature,
+				SigningAlgorithm: kms.SIGNING_ALGORITHM_ECDSA_SHA_256,
+			}, nil); errorType != awskms.ERROR_TYPE_INVALID_SIGNATURE {
+				t.Errorf("Expected error type %q, got %q", awskms.ERROR_TYPE_INVALID_SIGNATURE, errorType)
+			}
+		})
+	}
+}
+
+func TestServer_ListKeysPaging(t *testing.T) {
+	server := newServer(t)
+
+	created := map[string]bool{}
+	for range 5 {
+		created[createKey(t, server, awskms.CreateKeyRequest{}).KeyId] = true
+	}
+
+	listed := map[string]bool{}
+	marker := ""
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatalf("Expected paging to end")
+		}
+
+		var response awskms.ListKeysResponse
+		if errorType := call(t, server, "ListKeys", awskms.ListKeysRequest{Limit: 2, Marker: marker}, &response); errorType != "" {
+			t.Fatalf("Expected listing to succeed, got %s", errorType)
+		}
+		if len(response.Keys) > 2 {
+			t.Errorf("Expected at most 2 keys per page, got %d", len(response.Keys))
+		}
+		for _, entry := range response.Keys {
+			if listed[entry.KeyId] {
+				t.Errorf("Key %q listed twice", entry.KeyId)
+			}
+			listed[entry.KeyId] = true
+		}
+		if !response.Truncated {
+			break
+		}
+		marker = response.NextMarker
+	}
+
+	if fmt.Sprint(listed) != fmt.Sprint(created) {
+		t.Errorf("Expected keys %v, got %v", created, listed)
+	}
+}
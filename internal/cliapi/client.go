@@ -0,0 +1,73 @@
+package cliapi
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DEFAULT_CLIENT_TIMEOUT = 30 * time.Second
+)
+
+// Client - client of the CLI API served by the daemon.
+type Client struct {
+	socketPath string
+	timeout    time.Duration
+}
+
+func NewClient(socketPath string) *Client {
+	return &Client{
+		socketPath: socketPath,
+		timeout:    DEFAULT_CLIENT_TIMEOUT,
+	}
+}
+
+// Call - sends a request to the daemon and decodes its result. API failures are returned as *Error.
+func (c *Client) Call(method string, params any, result any) error {
+	connection, err := net.DialTimeout("unix", c.socketPath, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	if err := connection.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+
+	request := Request{
+		Version: PROTOCOL_VERSION,
+		ID:      uuid.NewString(),
+		Method:  method,
+	}
+	if params != nil {
+		request.Params, err = json.Marshal(params)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The daemon may answer before reading the whole request, e.g. when rejecting the peer, so the response is read
+	// even when writing the request failed and only the write error is returned when there is none.
+	//
+	writeErr := json.NewEncoder(connection).Encode(request)
+
+	var response Response
+	if err := json.NewDecoder(connection).Decode(&response); err != nil {
+		if writeErr != nil {
+			return writeErr
+		}
+		return err
+	}
+
+	if response.Error != nil {
+		return response.Error
+	}
+
+	if result == nil || len(response.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
@@ -0,0 +1,246 @@
+package cliapi
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+type StatusResult struct {
+	ProtocolVersion int       `json:"protocolVersion"`
+	StartedAt       time.Time `json:"startedAt"`
+	Sealed          bool      `json:"sealed"`
+	Keys            int       `json:"keys"`    // zero while sealed.
+	Aliases         int       `json:"aliases"` // zero while sealed.
+}
+
+type OperatorInitParams struct {
+	Shares    int `json:"shares"`
+	Threshold int `json:"threshold"`
+}
+
+// OperatorInitResult - unseal shares of a freshly generated root key, returned once and never stored.
+type OperatorInitResult struct {
+	Shares    [][]byte `json:"shares"`
+	Threshold int      `json:"threshold"`
+}
+
+type OperatorUnsealParams struct {
+	Share []byte `json:"share"`
+}
+
+type SealStatusResult struct {
+	Type        string `json:"type"`
+	Initialized bool   `json:"initialized"`
+	Sealed      bool   `json:"sealed"`
+	Shares      int    `json:"shares"`
+	Threshold   int    `json:"threshold"`
+	Progress    int    `json:"progress"`
+}
+
+type AuditTailParams struct {
+	Lines int `json:"lines"`
+}
+
+type AuditTailResult struct {
+	Events []string `json:"events"`
+}
+
+type KeyIDParams struct {
+	KeyID string `json:"keyId"`
+}
+
+type KeyCreateParams struct {
+	Spec               string            `json:"spec"`
+	Usage              string            `json:"usage"`
+	Provider           string            `json:"provider"`
+	Description        string            `json:"description"`
+	Tags               map[string]string `json:"tags"`
+	RotationPeriodDays int               `json:"rotationPeriodDays"`
+}
+
+type KeyUpdateMetadataParams struct {
+	KeyID       string            `json:"keyId"`
+	Description string            `json:"description"`
+	Tags        map[string]string `json:"tags"`
+}
+
+type KeySetRotationPeriodParams struct {
+	KeyID              string `json:"keyId"`
+	RotationPeriodDays int    `json:"rotationPeriodDays"`
+}
+
+type KeyScheduleDeletionParams struct {
+	KeyID             string `json:"keyId"`
+	PendingWindowDays int    `json:"pendingWindowDays"`
+}
+
+// DescribeSealStatus - converts a seal status into its CLI API result.
+func DescribeSealStatus(status kms.SealStatus) SealStatusResult {
+	return SealStatusResult{
+		Type:        status.Type,
+		Initialized: status.Initialized,
+		Sealed:      status.Sealed,
+		Shares:      status.Shares,
+		Threshold:   status.Threshold,
+		Progress:    status.Progress,
+	}
+}
+
+// KeyDescription - key as exposed to clients, never carrying key material.
+type KeyDescription struct {
+	ID                 string            `json:"id"`
+	Spec               string            `json:"spec"`
+	Usage              string            `json:"usage"`
+	Provider           string            `json:"provider"`
+	State              string            `json:"state"`
+	Description        string            `json:"description"`
+	Tags               map[string]string `json:"tags"`
+	PrimaryVersion     int               `json:"primaryVersion"`
+	Versions           []int             `json:"versions"`
+	RotationPeriodDays int               `json:"rotationPeriodDays"`
+	NextRotationAt     *time.Time        `json:"nextRotationAt,omitempty"`
+	DeletionDate       *time.Time        `json:"deletionDate,omitempty"`
+	CreatedAt          time.Time         `json:"createdAt"`
+	UpdatedAt          time.Time         `json:"updatedAt"`
+}
+
+type KeyListResult struct {
+	Keys []KeyDescription `json:"keys"`
+}
+
+type PublicKeyResult struct {
+	KeyID      string          `json:"keyId"`
+	KeyVersion int             `json:"keyVersion"`
+	Spec       string          `json:"spec"`
+	Usage      string          `json:"usage"`
+	PEM        string          `json:"pem"`
+	JWK        json.RawMessage `json:"jwk"`
+}
+
+type AliasParams struct {
+	Name  string `json:"name"`
+	KeyID string `json:"keyId,omitempty"`
+}
+
+type AliasDescription struct {
+	Name      string    `json:"name"`
+	KeyID     string    `json:"keyId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type AliasListResult struct {
+	Aliases []AliasDescription `json:"aliases"`
+}
+
+type EncryptParams struct {
+	KeyID             string            `json:"keyId"`
+	Plaintext         []byte            `json:"plaintext"`
+	EncryptionContext map[string]string `json:"encryptionContext,omitempty"`
+}
+
+type EncryptResult struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type DecryptParams struct {
+	Ciphertext        []byte            `json:"ciphertext"`
+	EncryptionContext map[string]string `json:"encryptionContext,omitempty"`
+}
+
+type DecryptResult struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Plaintext  []byte `json:"plaintext"`
+}
+
+type GenerateDataKeyParams struct {
+	KeyID             string            `json:"keyId"`
+	Spec              string            `json:"spec"`
+	EncryptionContext map[string]string `json:"encryptionContext,omitempty"`
+	WithoutPlaintext  bool              `json:"withoutPlaintext"`
+}
+
+type GenerateDataKeyResult struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Plaintext  []byte `json:"plaintext,omitempty"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type SignParams struct {
+	KeyID     string `json:"keyId"`
+	Digest    []byte `json:"digest"`
+	Algorithm string `json:"algorithm"`
+}
+
+type SignResult struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Algorithm  string `json:"algorithm"`
+	Signature  []byte `json:"signature"`
+}
+
+type VerifyParams struct {
+	KeyID     string `json:"keyId"`
+	Digest    []byte `json:"digest"`
+	Signature []byte `json:"signature"`
+	Algorithm string `json:"algorithm"`
+}
+
+type MacParams struct {
+	KeyID     string `json:"keyId"`
+	Message   []byte `json:"message"`
+	Mac       []byte `json:"mac,omitempty"`
+	Algorithm string `json:"algorithm"`
+}
+
+type MacResult struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Algorithm  string `json:"algorithm"`
+	Mac        []byte `json:"mac"`
+}
+
+type ValidityResult struct {
+	Valid bool `json:"valid"`
+}
+
+// DescribeKey - converts a key into its description, leaving the key material out.
+func DescribeKey(key kms.Key) KeyDescription {
+	versions := make([]int, 0, len(key.Versions))
+	for _, version := range key.Versions {
+		versions = append(versions, version.Version)
+	}
+
+	return KeyDescription{
+		ID:                 key.ID,
+		Spec:               key.Spec,
+		Usage:              key.Usage,
+		Provider:           key.Provider,
+		State:              key.State,
+		Description:        key.Metadata.Description,
+		Tags:               key.Metadata.Tags,
+		PrimaryVersion:     key.PrimaryVersion,
+		Versions:           versions,
+		RotationPeriodDays: int(key.RotationPeriod / (24 * time.Hour)),
+		NextRotationAt:     key.NextRotationAt,
+		DeletionDate:       key.DeletionDate,
+		CreatedAt:          key.CreatedAt,
+		UpdatedAt:          key.UpdatedAt,
+	}
+}
+
+// DescribeAlias - converts an alias into its description.
+func DescribeAlias(alias kms.Alias) AliasDescription {
+	return AliasDescription{
+		Name:      alias.Name,
+		KeyID:     alias.KeyID,
+		CreatedAt: alias.CreatedAt,
+		UpdatedAt: alias.UpdatedAt,
+	}
+}
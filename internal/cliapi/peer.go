@@ -0,0 +1,39 @@
+package cliapi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+var (
+	ErrPeerCredentialsUnsupported = errors.New("peer credentials are not supported on this platform")
+)
+
+// PeerCredentials - credentials of the process on the other end of a Unix socket connection, as reported by the kernel.
+type PeerCredentials struct {
+	PID int
+	UID int
+	GID int
+}
+
+// CallerIdentity - identity under which the requests of the peer are audited.
+func (p PeerCredentials) CallerIdentity() string {
+	return fmt.Sprintf("%s:uid=%d", CALLER_IDENTITY, p.UID)
+}
+
+// Allowlist - users and groups allowed to connect to the CLI API. A peer is authorized when either its uid or its
+// gid is listed. An empty allowlist only authorizes the user the daemon runs as.
+type Allowlist struct {
+	UIDs []int
+	GIDs []int
+}
+
+// Authorizes - reports whether the peer may use the CLI API.
+func (a Allowlist) Authorizes(peer PeerCredentials) bool {
+	if len(a.UIDs) == 0 && len(a.GIDs) == 0 {
+		return peer.UID == os.Geteuid()
+	}
+	return slices.Contains(a.UIDs, peer.UID) || slices.Contains(a.GIDs, peer.GID)
+}
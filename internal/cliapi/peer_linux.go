@@ -0,0 +1,38 @@
+package cliapi
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// peerCredentials - reads the SO_PEERCRED credentials of a Unix socket connection.
+func peerCredentials(connection net.Conn) (PeerCredentials, error) {
+	unixConnection, ok := connection.(*net.UnixConn)
+	if !ok {
+		return PeerCredentials{}, fmt.Errorf("%w: not a Unix socket connection", ErrPeerCredentialsUnsupported)
+	}
+
+	rawConnection, err := unixConnection.SyscallConn()
+	if err != nil {
+		return PeerCredentials{}, err
+	}
+
+	var credentials *syscall.Ucred
+	var credentialsErr error
+	err = rawConnection.Control(func(fd uintptr) {
+		credentials, credentialsErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return PeerCredentials{}, err
+	}
+	if credentialsErr != nil {
+		return PeerCredentials{}, credentialsErr
+	}
+
+	return PeerCredentials{
+		PID: int(credentials.Pid),
+		UID: int(credentials.Uid),
+		GID: int(credentials.Gid),
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package cliapi
+
+import "net"
+
+// peerCredentials - peer credentials are only read on Linux, every other platform is refused.
+func peerCredentials(connection net.Conn) (PeerCredentials, error) {
+	return PeerCredentials{}, ErrPeerCredentialsUnsupported
+}
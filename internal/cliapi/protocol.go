@@ -0,0 +1,88 @@
+package cliapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+// The CLI API exchanges JSON documents over a Unix domain socket. A client writes one Request at a time and reads
+// the matching Response before sending the next one; several requests may be sent over the same connection.
+const (
+	PROTOCOL_VERSION = 1
+
+	CALLER_IDENTITY = "cli"
+
+	ERROR_CODE_UNSUPPORTED_VERSION = "UNSUPPORTED_VERSION"
+	ERROR_CODE_UNKNOWN_METHOD      = "UNKNOWN_METHOD"
+	ERROR_CODE_INVALID_REQUEST     = "INVALID_REQUEST"
+	ERROR_CODE_PERMISSION_DENIED   = "PERMISSION_DENIED"
+	ERROR_CODE_NOT_FOUND           = kms.ERROR_CODE_NOT_FOUND
+	ERROR_CODE_ALREADY_EXISTS      = kms.ERROR_CODE_ALREADY_EXISTS
+	ERROR_CODE_INVALID_ARGUMENT    = kms.ERROR_CODE_INVALID_ARGUMENT
+	ERROR_CODE_FAILED_PRECONDITION = kms.ERROR_CODE_FAILED_PRECONDITION
+	ERROR_CODE_ABORTED             = kms.ERROR_CODE_ABORTED
+	ERROR_CODE_INTERNAL            = kms.ERROR_CODE_INTERNAL
+
+	METHOD_DAEMON_STATUS            = "daemon.status"
+	METHOD_AUDIT_TAIL               = "audit.tail"
+	METHOD_OPERATOR_INIT            = "operator.init"
+	METHOD_OPERATOR_UNSEAL          = "operator.unseal"
+	METHOD_OPERATOR_SEAL            = "operator.seal"
+	METHOD_OPERATOR_SEAL_STATUS     = "operator.seal-status"
+	METHOD_KEYS_CREATE              = "keys.create"
+	METHOD_KEYS_LIST                = "keys.list"
+	METHOD_KEYS_DESCRIBE            = "keys.describe"
+	METHOD_KEYS_UPDATE_METADATA     = "keys.update-metadata"
+	METHOD_KEYS_ROTATE              = "keys.rotate"
+	METHOD_KEYS_SET_ROTATION_PERIOD = "keys.set-rotation-period"
+	METHOD_KEYS_ENABLE              = "keys.enable"
+	METHOD_KEYS_DISABLE             = "keys.disable"
+	METHOD_KEYS_SCHEDULE_DELETION   = "keys.schedule-deletion"
+	METHOD_KEYS_CANCEL_DELETION     = "keys.cancel-deletion"
+	METHOD_KEYS_GET_PUBLIC_KEY      = "keys.get-public-key"
+	METHOD_ALIASES_CREATE           = "aliases.create"
+	METHOD_ALIASES_UPDATE           = "aliases.update"
+	METHOD_ALIASES_DELETE           = "aliases.delete"
+	METHOD_ALIASES_LIST             = "aliases.list"
+	METHOD_ENCRYPT                  = "encrypt"
+	METHOD_DECRYPT                  = "decrypt"
+	METHOD_GENERATE_DATA_KEY        = "generate-data-key"
+	METHOD_SIGN                     = "sign"
+	METHOD_VERIFY                   = "verify"
+	METHOD_GENERATE_MAC             = "generate-mac"
+	METHOD_VERIFY_MAC               = "verify-mac"
+)
+
+type Request struct {
+	Version int             `json:"version"`
+	ID      string          `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type Response struct {
+	Version int             `json:"version"`
+	ID      string          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error - error returned to the client, identified by a stable code.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// NewError - constructor for Error.
+func NewError(code, format string, arguments ...any) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, arguments...),
+	}
+}
@@ -0,0 +1,230 @@
+package cliapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/unixsocket"
+)
+
+const (
+	// REJECTION_READ_TIMEOUT - how long the request of a rejected peer is waited for before answering it.
+	REJECTION_READ_TIMEOUT = time.Second
+)
+
+// HandlerFunc - handles the parameters of a request and returns its result.
+type HandlerFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Server - serves the CLI API over a Unix domain socket.
+type Server struct {
+	socketPath string
+	auditor    audit.Auditor
+	auditGroup string
+	allowlist  Allowlist
+	handlers   map[string]HandlerFunc
+
+	connections     map[net.Conn]struct{}
+	connectionsLock sync.Mutex
+	waitGroup       sync.WaitGroup
+}
+
+func NewServer(socketPath string, auditor audit.Auditor, auditGroup string, allowlist Allowlist) *Server {
+	return &Server{
+		socketPath:  socketPath,
+		auditor:     auditor,
+		auditGroup:  auditGroup,
+		allowlist:   allowlist,
+		handlers:    map[string]HandlerFunc{},
+		connections: map[net.Conn]struct{}{},
+	}
+}
+
+// Handle - registers the handler of a method.
+func (s *Server) Handle(method string, handler HandlerFunc) {
+	s.handlers[method] = handler
+}
+
+// Serve - accepts connections until the context is done.
+func (s *Server) Serve(ctx context.Context) error {
+	listener, err := unixsocket.Listen(s.socketPath, 0660)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(s.socketPath)
+
+	// Close the listener and all open connections once the context is done.
+	//
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+
+		s.connectionsLock.Lock()
+		for connection := range s.connections {
+			connection.Close()
+		}
+		s.connectionsLock.Unlock()
+	}()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			s.waitGroup.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		// A connection accepted while the context is being cancelled would be missed by the goroutine closing them.
+		//
+		s.connectionsLock.Lock()
+		if ctx.Err() != nil {
+			s.connectionsLock.Unlock()
+			connection.Close()
+			continue
+		}
+		s.connections[connection] = struct{}{}
+		s.connectionsLock.Unlock()
+
+		s.waitGroup.Add(1)
+		go s.serveConnection(ctx, connection)
+	}
+}
+
+// serveConnection - answers the requests sent over a connection until it is closed.
+func (s *Server) serveConnection(ctx context.Context, connection net.Conn) {
+	defer s.waitGroup.Done()
+	defer func() {
+		s.connectionsLock.Lock()
+		delete(s.connections, connection)
+		s.connectionsLock.Unlock()
+		connection.Close()
+	}()
+
+	encoder := json.NewEncoder(connection)
+	decoder := json.NewDecoder(connection)
+
+	// Authorize the peer from the credentials reported by the kernel before acting on anything it sent. A rejected
+	// peer still gets its first request read, for a shortly bounded time, as closing the connection with the request
+	// unread would reset it before the rejection reaches the peer.
+	//
+	peer, err := s.authorize(connection)
+	if err != nil {
+		var request Request
+		connection.SetReadDeadline(time.Now().Add(REJECTION_READ_TIMEOUT))
+		decoder.Decode(&request)
+
+		encoder.Encode(Response{
+			Version: PROTOCOL_VERSION,
+			ID:      kms.ClientRequestID(request.ID),
+			Error:   NewError(ERROR_CODE_PERMISSION_DENIED, "%v", err),
+		})
+		return
+	}
+	ctx = kms.WithCallerIdentity(ctx, peer.CallerIdentity())
+
+	for {
+		var request Request
+		if err := decoder.Decode(&request); err != nil {
+			var syntaxError *json.SyntaxError
+			if errors.As(err, &syntaxError) {
+				encoder.Encode(Response{
+					Version: PROTOCOL_VERSION,
+					Error:   NewError(ERROR_CODE_INVALID_REQUEST, "malformed request: %v", err),
+				})
+			}
+			return
+		}
+
+		if err := encoder.Encode(s.dispatch(ctx, request)); err != nil {
+			return
+		}
+	}
+}
+
+// authorize - reads the credentials of the peer and checks them against the allowlist, recording rejections.
+func (s *Server) authorize(connection net.Conn) (PeerCredentials, error) {
+	peer, err := peerCredentials(connection)
+	if err != nil {
+		s.recordEvent(audit.LEVEL_WARN, "CLI API connection rejected", map[string]string{
+			"error": err.Error(),
+		})
+		return PeerCredentials{}, errors.New("peer credentials could not be verified")
+	}
+
+	if !s.allowlist.Authorizes(peer) {
+		s.recordEvent(audit.LEVEL_WARN, "CLI API connection rejected", map[string]string{
+			"pid": strconv.Itoa(peer.PID),
+			"uid": strconv.Itoa(peer.UID),
+			"gid": strconv.Itoa(peer.GID),
+		})
+		return PeerCredentials{}, errors.New("peer is not allowed to use the CLI API")
+	}
+
+	return peer, nil
+}
+
+// dispatch - runs the handler of a request and records its outcome.
+func (s *Server) dispatch(ctx context.Context, request Request) Response {
+	requestID := kms.ClientRequestID(request.ID)
+	response := Response{
+		Version: PROTOCOL_VERSION,
+		ID:      requestID,
+	}
+
+	result, err := s.handle(kms.WithRequestID(ctx, requestID), request)
+	if err == nil {
+		response.Result, err = json.Marshal(result)
+	}
+
+	level := audit.LEVEL_INFO
+	labels := map[string]string{
+		"requestId": requestID,
+		"method":    request.Method,
+	}
+	if err != nil {
+		var apiError *Error
+		if !errors.As(err, &apiError) {
+			apiError = NewError(kms.ErrorCode(err), "%v", err)
+		}
+		response.Error = apiError
+		response.Result = nil
+
+		level = audit.LEVEL_WARN
+		labels["errorCode"] = apiError.Code
+	}
+
+	labels["callerIdentity"] = kms.CallerIdentity(ctx)
+	s.recordEvent(level, "CLI API request handled", labels)
+
+	return response
+}
+
+// handle - validates a request and runs its handler.
+func (s *Server) handle(ctx context.Context, request Request) (any, error) {
+	if request.Version != PROTOCOL_VERSION {
+		return nil, NewError(ERROR_CODE_UNSUPPORTED_VERSION, "protocol version %d is not supported, expected %d", request.Version, PROTOCOL_VERSION)
+	}
+
+	handler, ok := s.handlers[request.Method]
+	if !ok {
+		return nil, NewError(ERROR_CODE_UNKNOWN_METHOD, "unknown method %q", request.Method)
+	}
+
+	return handler(ctx, request.Params)
+}
+
+// recordEvent - records an event of the CLI API if auditing is enabled.
+func (s *Server) recordEvent(level, message string, labels map[string]string) {
+	if s.auditor != nil {
+		s.auditor.RecordEvent(audit.NewEvent(level, s.auditGroup, audit.TOPIC_CLI_API, message, labels))
+	}
+}
@@ -0,0 +1,258 @@
+package cliapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/cliapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+// startServer - serves a CLI API with an echo method on a temporary socket until the test ends.
+func startServer(t *testing.T, auditor audit.Auditor, allowlist cliapi.Allowlist) string {
+	t.Helper()
+
+	// Unix socket paths are limited in length, so avoid the long test temp directory.
+	//
+	directory, err := os.MkdirTemp("", "cliapi")
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(directory) })
+	socketPath := filepath.Join(directory, "openkms.sock")
+
+	server := cliapi.NewServer(socketPath, auditor, "TEST", allowlist)
+	server.Handle("echo", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var echo map[string]string
+		if err := json.Unmarshal(params, &echo); err != nil {
+			return nil, err
+		}
+		echo["caller"] = kms.CallerIdentity(ctx)
+		return echo, nil
+	})
+	server.Handle("missing", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, kms.ErrKeyNotFound
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- server.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Expected server to stop cleanly, got %v", err)
+		}
+	})
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(socketPath); err == nil {
+			return socketPath
+		}
+	}
+	t.Fatalf("Server did not start listening")
+	return ""
+}
+
+func TestServer(t *testing.T) {
+	socketPath := startServer(t, nil, cliapi.Allowlist{})
+	client := cliapi.NewClient(socketPath)
+
+	scenarios := []struct {
+		name       string
+		method     string
+		params     any
+		assertions func(t *testing.T, result map[string]string, err error)
+	}{
+		{
+			name:   "Result",
+			method: "echo",
+			params: map[string]string{"hello": "world"},
+			assertions: func(t *testing.T, result map[string]string, err error) {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if result["hello"] != "world" || result["caller"] != "cli:uid="+strconv.Itoa(os.Getuid()) {
+					t.Errorf("Unexpected result %v", result)
+				}
+			},
+		},
+		{
+			name:   "Unknown method",
+			method: "unknown",
+			assertions: func(t *testing.T, result map[string]string, err error) {
+				var apiError *cliapi.Error
+				if !errors.As(err, &apiError) || apiError.Code != cliapi.ERROR_CODE_UNKNOWN_METHOD {
+					t.Errorf("Expected %s, got %v", cliapi.ERROR_CODE_UNKNOWN_METHOD, err)
+				}
+			},
+		},
+		{
+			name:   "KMS error",
+			method: "missing",
+			assertions: func(t *testing.T, result map[string]string, err error) {
+				var apiError *cliapi.Error
+				if !errors.As(err, &apiError) || apiError.Code != cliapi.ERROR_CODE_NOT_FOUND {
+					t.Errorf("Expected %s, got %v", cliapi.ERROR_CODE_NOT_FOUND, err)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			var result map[string]string
+			err := client.Call(scenario.method, scenario.params, &result)
+			scenario.assertions(t, result, err)
+		})
+	}
+}
+
+func TestServer_UnsupportedVersion(t *testing.T) {
+	socketPath := startServer(t, nil, cliapi.Allowlist{})
+
+	connection, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer connection.Close()
+
+	if err := json.NewEncoder(connection).Encode(cliapi.Request{Version: 99, ID: "1", Method: "echo"}); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+
+	var response cliapi.Response
+	if err := json.NewDecoder(connection).Decode(&response); err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	if response.ID != "1" || response.Error == nil || response.Error.Code != cliapi.ERROR_CODE_UNSUPPORTED_VERSION {
+		t.Errorf("Expected %s, got %+v", cliapi.ERROR_CODE_UNSUPPORTED_VERSION, response)
+	}
+}
+
+func TestServer_RequestID(t *testing.T) {
+	auditor := &kmstest.Auditor{}
+	socketPath := startServer(t, auditor, cliapi.Allowlist{})
+
+	connection, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer connection.Close()
+	encoder := json.NewEncoder(connection)
+	decoder := json.NewDecoder(connection)
+
+	scenarios := []struct {
+		name      string
+		requestID string
+		forged    bool
+	}{
+		{name: "Valid", requestID: "cli-1.2_3"},
+		{name: "Forged", requestID: "1\" level=ERROR message=\"forged", forged: true},
+		{name: "Missing", requestID: "", forged: true},
+	}
+
+	for i, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			request := cliapi.Request{Version: cliapi.PROTOCOL_VERSION, ID: scenario.requestID, Method: "echo", Params: json.RawMessage(`{}`)}
+			if err := encoder.Encode(request); err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			var response cliapi.Response
+			if err := decoder.Decode(&response); err != nil {
+				t.Fatalf("Failed to read response: %v", err)
+			}
+
+			// Request IDs that are missing or malformed are replaced, in the response as in the audit log.
+			//
+			if scenario.forged && response.ID == scenario.requestID {
+				t.Errorf("Expected request ID %q to be replaced", scenario.requestID)
+			}
+			if !scenario.forged && response.ID != scenario.requestID {
+				t.Errorf("Expected request ID %q, got %q", scenario.requestID, response.ID)
+			}
+			if events := auditor.Events(); len(events) != i+1 || events[i].Labels["requestId"] != response.ID {
+				t.Errorf("Expected request ID %q in the audit log, got %v", response.ID, events)
+			}
+		})
+	}
+}
+
+func TestServer_PeerCredentials(t *testing.T) {
+	auditor := &kmstest.Auditor{}
+	socketPath := startServer(t, auditor, cliapi.Allowlist{UIDs: []int{os.Getuid() + 1}, GIDs: []int{os.Getgid() + 1}})
+
+	err := cliapi.NewClient(socketPath).Call("echo", map[string]string{}, nil)
+	var apiError *cliapi.Error
+	if !errors.As(err, &apiError) || apiError.Code != cliapi.ERROR_CODE_PERMISSION_DENIED {
+		t.Fatalf("Expected %s, got %v", cliapi.ERROR_CODE_PERMISSION_DENIED, err)
+	}
+
+	events := auditor.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Level != audit.LEVEL_WARN {
+		t.Errorf("Expected level %s, got %s", audit.LEVEL_WARN, event.Level)
+	}
+	if event.Labels["pid"] != strconv.Itoa(os.Getpid()) || event.Labels["uid"] != strconv.Itoa(os.Getuid()) || event.Labels["gid"] != strconv.Itoa(os.Getgid()) {
+		t.Errorf("Expected peer credentials as labels, got %v", event.Labels)
+	}
+}
+
+func TestAllowlist_Authorizes(t *testing.T) {
+	scenarios := []struct {
+		name       string
+		allowlist  cliapi.Allowlist
+		peer       cliapi.PeerCredentials
+		authorized bool
+	}{
+		{
+			name:       "Empty allowlist authorizes the daemon user",
+			allowlist:  cliapi.Allowlist{},
+			peer:       cliapi.PeerCredentials{UID: os.Geteuid(), GID: 4242},
+			authorized: true,
+		},
+		{
+			name:       "Empty allowlist rejects other users",
+			allowlist:  cliapi.Allowlist{},
+			peer:       cliapi.PeerCredentials{UID: os.Geteuid() + 1, GID: 4242},
+			authorized: false,
+		},
+		{
+			name:       "Listed uid",
+			allowlist:  cliapi.Allowlist{UIDs: []int{1000}},
+			peer:       cliapi.PeerCredentials{UID: 1000, GID: 1000},
+			authorized: true,
+		},
+		{
+			name:       "Listed gid",
+			allowlist:  cliapi.Allowlist{UIDs: []int{1000}, GIDs: []int{2000}},
+			peer:       cliapi.PeerCredentials{UID: 1001, GID: 2000},
+			authorized: true,
+		},
+		{
+			name:       "Neither listed",
+			allowlist:  cliapi.Allowlist{UIDs: []int{1000}, GIDs: []int{2000}},
+			peer:       cliapi.PeerCredentials{UID: 1001, GID: 2001},
+			authorized: false,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if authorized := scenario.allowlist.Authorizes(scenario.peer); authorized != scenario.authorized {
+				t.Errorf("Expected %v, got %v", scenario.authorized, authorized)
+			}
+		})
+	}
+}
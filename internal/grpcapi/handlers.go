@@ -0,0 +1,247 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	openkmsv1 "github.com/hyperplane-sh/openkms/api/openkms/v1"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) CreateKey(ctx context.Context, request *openkmsv1.CreateKeyRequest) (*openkmsv1.Key, error) {
+	key, err := s.kmsService.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{
+		Spec:           request.GetSpec(),
+		Usage:          request.GetUsage(),
+		Metadata:       kms.KeyMetadata{Description: request.GetDescription(), Tags: request.GetTags()},
+		RotationPeriod: days(request.GetRotationPeriodDays()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newKey(key), nil
+}
+
+func (s *Server) ListKeys(ctx context.Context, request *openkmsv1.ListKeysRequest) (*openkmsv1.ListKeysResponse, error) {
+	keys, err := s.kmsService.KeyStore().ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &openkmsv1.ListKeysResponse{Keys: make([]*openkmsv1.Key, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, newKey(key))
+	}
+	return response, nil
+}
+
+func (s *Server) DescribeKey(ctx context.Context, request *openkmsv1.KeyRequest) (*openkmsv1.Key, error) {
+	return s.keyOperation(ctx, request, kms.KeyStore.GetKey)
+}
+
+// UpdateKey - updates the fields present in the request, leaving the others unchanged.
+func (s *Server) UpdateKey(ctx context.Context, request *openkmsv1.UpdateKeyRequest) (*openkmsv1.Key, error) {
+	options := kms.UpdateKeyOptions{Description: request.Description}
+	if request.Tags != nil {
+		options.Tags = request.GetTags().GetTags()
+		if options.Tags == nil {
+			options.Tags = map[string]string{}
+		}
+	}
+	if request.RotationPeriodDays != nil {
+		rotationPeriod := days(request.GetRotationPeriodDays())
+		options.RotationPeriod = &rotationPeriod
+	}
+
+	key, err := s.kmsService.UpdateKey(ctx, request.GetKeyId(), options)
+	if err != nil {
+		return nil, err
+	}
+	return newKey(key), nil
+}
+
+// ScheduleKeyDeletion - keys are never deleted right away, only scheduled for destruction.
+func (s *Server) ScheduleKeyDeletion(ctx context.Context, request *openkmsv1.ScheduleKeyDeletionRequest) (*openkmsv1.Key, error) {
+	key, err := s.kmsService.ScheduleKeyDeletion(ctx, request.GetKeyId(), days(request.GetPendingWindowDays()))
+	if err != nil {
+		return nil, err
+	}
+	return newKey(key), nil
+}
+
+func (s *Server) CancelKeyDeletion(ctx context.Context, request *openkmsv1.KeyRequest) (*openkmsv1.Key, error) {
+	return s.keyOperation(ctx, request, kms.KeyStore.CancelKeyDeletion)
+}
+
+func (s *Server) EnableKey(ctx context.Context, request *openkmsv1.KeyRequest) (*openkmsv1.Key, error) {
+	return s.keyOperation(ctx, request, kms.KeyStore.EnableKey)
+}
+
+func (s *Server) DisableKey(ctx context.Context, request *openkmsv1.KeyRequest) (*openkmsv1.Key, error) {
+	return s.keyOperation(ctx, request, kms.KeyStore.DisableKey)
+}
+
+func (s *Server) RotateKey(ctx context.Context, request *openkmsv1.KeyRequest) (*openkmsv1.Key, error) {
+	return s.keyOperation(ctx, request, kms.KeyStore.RotateKey)
+}
+
+func (s *Server) GetPublicKey(ctx context.Context, request *openkmsv1.KeyRequest) (*openkmsv1.PublicKey, error) {
+	publicKey, err := s.kmsService.GetPublicKey(ctx, request.GetKeyId())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.PublicKey{
+		KeyId:      publicKey.KeyID,
+		KeyVersion: int32(publicKey.KeyVersion),
+		Spec:       publicKey.Spec,
+		Usage:      publicKey.Usage,
+		Pem:        string(publicKey.PEM),
+		Jwk:        string(publicKey.JWK),
+	}, nil
+}
+
+func (s *Server) Encrypt(ctx context.Context, request *openkmsv1.EncryptRequest) (*openkmsv1.EncryptResponse, error) {
+	ciphertext, err := s.kmsService.EncryptPlaintext(ctx, request.GetKeyId(), request.GetPlaintext(), request.GetEncryptionContext())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.EncryptResponse{KeyId: ciphertext.KeyID, KeyVersion: int32(ciphertext.KeyVersion), Ciphertext: ciphertext.Ciphertext}, nil
+}
+
+func (s *Server) Decrypt(ctx context.Context, request *openkmsv1.DecryptRequest) (*openkmsv1.DecryptResponse, error) {
+	plaintext, err := s.kmsService.DecryptCiphertext(ctx, request.GetCiphertext(), request.GetEncryptionContext())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.DecryptResponse{KeyId: plaintext.KeyID, KeyVersion: int32(plaintext.KeyVersion), Plaintext: plaintext.Plaintext}, nil
+}
+
+func (s *Server) GenerateDataKey(ctx context.Context, request *openkmsv1.GenerateDataKeyRequest) (*openkmsv1.GenerateDataKeyResponse, error) {
+	generate := s.kmsService.GenerateDataKey
+	if request.GetWithoutPlaintext() {
+		generate = s.kmsService.GenerateDataKeyWithoutPlaintext
+	}
+	dataKey, err := generate(ctx, request.GetKeyId(), request.GetSpec(), request.GetEncryptionContext())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.GenerateDataKeyResponse{
+		KeyId:      dataKey.KeyID,
+		KeyVersion: int32(dataKey.KeyVersion),
+		Plaintext:  dataKey.Plaintext,
+		Ciphertext: dataKey.Ciphertext,
+	}, nil
+}
+
+func (s *Server) Sign(ctx context.Context, request *openkmsv1.SignRequest) (*openkmsv1.SignResponse, error) {
+	signature, err := s.kmsService.Sign(ctx, request.GetKeyId(), request.GetDigest(), request.GetAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.SignResponse{
+		KeyId:      signature.KeyID,
+		KeyVersion: int32(signature.KeyVersion),
+		Algorithm:  signature.Algorithm,
+		Signature:  signature.Signature,
+	}, nil
+}
+
+func (s *Server) Verify(ctx context.Context, request *openkmsv1.VerifyRequest) (*openkmsv1.VerifyResponse, error) {
+	valid, err := s.kmsService.Verify(ctx, request.GetKeyId(), request.GetDigest(), request.GetSignature(), request.GetAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.VerifyResponse{Valid: valid}, nil
+}
+
+func (s *Server) GenerateMac(ctx context.Context, request *openkmsv1.GenerateMacRequest) (*openkmsv1.GenerateMacResponse, error) {
+	mac, err := s.kmsService.GenerateMac(ctx, request.GetKeyId(), request.GetMessage(), request.GetAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.GenerateMacResponse{
+		KeyId:      mac.KeyID,
+		KeyVersion: int32(mac.KeyVersion),
+		Algorithm:  mac.Algorithm,
+		Mac:        mac.Mac,
+	}, nil
+}
+
+func (s *Server) VerifyMac(ctx context.Context, request *openkmsv1.VerifyMacRequest) (*openkmsv1.VerifyMacResponse, error) {
+	valid, err := s.kmsService.VerifyMac(ctx, request.GetKeyId(), request.GetMessage(), request.GetMac(), request.GetAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.VerifyMacResponse{Valid: valid}, nil
+}
+
+// AsymmetricEncrypt - encrypts with the public key of an RSA key, answering the ID of the key rather than the alias
+// the request may name it by.
+func (s *Server) AsymmetricEncrypt(ctx context.Context, request *openkmsv1.AsymmetricEncryptRequest) (*openkmsv1.AsymmetricEncryptResponse, error) {
+	key, err := s.kmsService.KeyStore().GetKey(ctx, request.GetKeyId())
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := s.kmsService.AsymmetricEncrypt(ctx, key.ID, request.GetPlaintext(), request.GetAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.AsymmetricEncryptResponse{KeyId: key.ID, Algorithm: request.GetAlgorithm(), Ciphertext: ciphertext}, nil
+}
+
+func (s *Server) AsymmetricDecrypt(ctx context.Context, request *openkmsv1.AsymmetricDecryptRequest) (*openkmsv1.AsymmetricDecryptResponse, error) {
+	key, err := s.kmsService.KeyStore().GetKey(ctx, request.GetKeyId())
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := s.kmsService.AsymmetricDecrypt(ctx, key.ID, request.GetCiphertext(), request.GetAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	return &openkmsv1.AsymmetricDecryptResponse{KeyId: key.ID, Plaintext: plaintext}, nil
+}
+
+// keyOperation - applies a key store operation to the key in the request.
+func (s *Server) keyOperation(ctx context.Context, request *openkmsv1.KeyRequest, operation func(keyStore kms.KeyStore, ctx context.Context, keyID string) (kms.Key, error)) (*openkmsv1.Key, error) {
+	key, err := operation(s.kmsService.KeyStore(), ctx, request.GetKeyId())
+	if err != nil {
+		return nil, err
+	}
+	return newKey(key), nil
+}
+
+// newKey - converts a key into its API representation, leaving the key material out.
+func newKey(key kms.Key) *openkmsv1.Key {
+	versions := make([]int32, 0, len(key.Versions))
+	for _, version := range key.Versions {
+		versions = append(versions, int32(version.Version))
+	}
+
+	response := &openkmsv1.Key{
+		Id:                 key.ID,
+		Spec:               key.Spec,
+		Usage:              key.Usage,
+		State:              key.State,
+		Description:        key.Metadata.Description,
+		Tags:               key.Metadata.Tags,
+		PrimaryVersion:     int32(key.PrimaryVersion),
+		Versions:           versions,
+		RotationPeriodDays: int32(key.RotationPeriod / (24 * time.Hour)),
+		CreatedAt:          timestamppb.New(key.CreatedAt),
+		UpdatedAt:          timestamppb.New(key.UpdatedAt),
+	}
+	if key.NextRotationAt != nil {
+		response.NextRotationAt = timestamppb.New(*key.NextRotationAt)
+	}
+	if key.DeletionDate != nil {
+		response.DeletionDate = timestamppb.New(*key.DeletionDate)
+	}
+	return response
+}
+
+// days - converts a number of days into a duration.
+func days(n int32) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
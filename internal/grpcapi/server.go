@@ -0,0 +1,199 @@
+// Package grpcapi serves the KMS over gRPC, with the same operations, errors and audit events as the REST API. Clients
+// are authenticated by their TLS certificates, so the daemon refuses to serve the API unless the listener requires them.
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	openkmsv1 "github.com/hyperplane-sh/openkms/api/openkms/v1"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	METADATA_REQUEST_ID = "x-request-id"
+
+	SHUTDOWN_TIMEOUT = 10 * time.Second
+)
+
+var (
+	// grpcCodes - gRPC status answered for every KMS error code.
+	grpcCodes = map[string]codes.Code{
+		kms.ERROR_CODE_NOT_FOUND:           codes.NotFound,
+		kms.ERROR_CODE_ALREADY_EXISTS:      codes.AlreadyExists,
+		kms.ERROR_CODE_INVALID_ARGUMENT:    codes.InvalidArgument,
+		kms.ERROR_CODE_FAILED_PRECONDITION: codes.FailedPrecondition,
+		kms.ERROR_CODE_ABORTED:             codes.Aborted,
+		kms.ERROR_CODE_INTERNAL:            codes.Internal,
+	}
+)
+
+// Server - serves the KMS as the KeyManagement gRPC service.
+type Server struct {
+	openkmsv1.UnimplementedKeyManagementServer
+
+	kmsService *kms.Service
+	auditor    audit.Auditor
+	auditGroup string
+	identities *tlsconfig.IdentityMapper // maps client certificates to caller identities, nil accepting any subject.
+	grpcServer *grpc.Server
+}
+
+// NewServer - creates the gRPC server, serving TLS when a configuration is given.
+func NewServer(kmsService *kms.Service, auditor audit.Auditor, auditGroup string, identities *tlsconfig.IdentityMapper, tlsConfig *tls.Config) *Server {
+	s := &Server{
+		kmsService: kmsService,
+		auditor:    auditor,
+		auditGroup: auditGroup,
+		identities: identities,
+	}
+
+	options := []grpc.ServerOption{grpc.UnaryInterceptor(s.serveRequest), grpc.StreamInterceptor(s.serveStream)}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s.grpcServer = grpc.NewServer(options...)
+	openkmsv1.RegisterKeyManagementServer(s.grpcServer, s)
+
+	return s
+}
+
+// Serve - listens on the address until the context is done.
+func (s *Server) Serve(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return s.ServeListener(ctx, listener)
+}
+
+// ServeListener - serves the connections accepted by the listener until the context is done, then lets the calls in
+// flight finish for up to the shutdown timeout.
+func (s *Server) ServeListener(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		timer := time.AfterFunc(SHUTDOWN_TIMEOUT, s.grpcServer.Stop)
+		s.grpcServer.GracefulStop()
+		timer.Stop()
+	}()
+
+	err := s.grpcServer.Serve(listener)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// serverStream - server stream whose context carries the request ID and caller identity.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (sS *serverStream) Context() context.Context {
+	return sS.ctx
+}
+
+// serveRequest - runs a unary call through serve.
+func (s *Server) serveRequest(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var response any
+	err := s.serve(ctx, info.FullMethod, func(ctx context.Context) error {
+		var err error
+		response, err = handler(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// serveStream - runs a streaming call through serve, recording one event once the stream ended.
+func (s *Server) serveStream(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return s.serve(stream.Context(), info.FullMethod, func(ctx context.Context) error {
+		return handler(server, &serverStream{ServerStream: stream, ctx: ctx})
+	})
+}
+
+// serve - assigns the call its ID and caller identity, runs it and records its outcome.
+func (s *Server) serve(ctx context.Context, method string, run func(ctx context.Context) error) error {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(METADATA_REQUEST_ID)) > 0 {
+		requestID = md.Get(METADATA_REQUEST_ID)[0]
+	}
+	requestID = kms.ClientRequestID(requestID)
+	grpc.SetHeader(ctx, metadata.Pairs(METADATA_REQUEST_ID, requestID))
+	ctx = kms.WithRequestID(ctx, requestID)
+
+	labels := map[string]string{
+		"requestId": requestID,
+		"method":    method,
+	}
+
+	ctx, err := s.authenticate(ctx, labels)
+	if err == nil {
+		err = run(ctx)
+	}
+	if err != nil {
+		labels["error"] = err.Error()
+		err = StatusError(err)
+	}
+
+	level := audit.LEVEL_INFO
+	if status.Code(err) != codes.OK {
+		level = audit.LEVEL_WARN
+	}
+	labels["status"] = status.Code(err).String()
+	labels["callerIdentity"] = kms.CallerIdentity(ctx)
+
+	if s.auditor != nil {
+		s.auditor.RecordEvent(audit.NewEvent(level, s.auditGroup, audit.TOPIC_GRPC_API, "gRPC API request handled", labels))
+	}
+	return err
+}
+
+// authenticate - sets the caller identity of a client presenting a certificate, rejecting subjects that are not
+// mapped to an identity.
+func (s *Server) authenticate(ctx context.Context, labels map[string]string) (context.Context, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	labels["remoteAddress"] = p.Addr.String()
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return ctx, nil
+	}
+
+	certificate := tlsInfo.State.PeerCertificates[0]
+	labels["clientSubject"] = certificate.Subject.String()
+
+	identity, err := s.identities.Identity(certificate)
+	if err != nil {
+		return ctx, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return kms.WithCallerIdentity(ctx, identity), nil
+}
+
+// StatusError - converts an error into the gRPC status matching its KMS error code.
+func StatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	// Internal failures are recorded in the audit log, their details are not returned to clients.
+	//
+	code := kms.ErrorCode(err)
+	if code == kms.ERROR_CODE_INTERNAL {
+		return status.Error(codes.Internal, "internal error")
+	}
+	return status.Error(grpcCodes[code], err.Error())
+}
@@ -0,0 +1,375 @@
+package grpcapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openkmsv1 "github.com/hyperplane-sh/openkms/api/openkms/v1"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/grpcapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+	"github.com/hyperplane-sh/openkms/internal/restapi"
+	"github.com/hyperplane-sh/openkms/internal/stream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newClient - serves the gRPC API in process and returns a client connected to it.
+func newClient(t *testing.T, service *kms.Service, auditor audit.Auditor) openkmsv1.KeyManagementClient {
+	t.Helper()
+
+	server := grpcapi.NewServer(service, auditor, "TEST", nil, nil)
+	return openkmsv1.NewKeyManagementClient(kmstest.ServeGRPC(t, server.ServeListener))
+}
+
+func TestServer_KeysAndCryptography(t *testing.T) {
+	client := newClient(t, kmstest.NewService(t, nil), nil)
+	ctx := context.Background()
+
+	key, err := client.CreateKey(ctx, &openkmsv1.CreateKeyRequest{Description: "payments"})
+	if err != nil || key.GetId() == "" || key.GetState() != kms.KEY_STATE_ENABLED {
+		t.Fatalf("Expected key to be created, got %+v %v", key, err)
+	}
+
+	encrypted, err := client.Encrypt(ctx, &openkmsv1.EncryptRequest{
+		KeyId:             key.GetId(),
+		Plaintext:         []byte("card number"),
+		EncryptionContext: map[string]string{"tenant": "acme"},
+	})
+	if err != nil || encrypted.GetKeyId() != key.GetId() || encrypted.GetKeyVersion() != 1 {
+		t.Fatalf("Expected encryption to succeed, got %+v %v", encrypted, err)
+	}
+
+	decrypted, err := client.Decrypt(ctx, &openkmsv1.DecryptRequest{
+		Ciphertext:        encrypted.GetCiphertext(),
+		EncryptionContext: map[string]string{"tenant": "acme"},
+	})
+	if err != nil || string(decrypted.GetPlaintext()) != "card number" {
+		t.Errorf("Expected decryption to succeed, got %+v %v", decrypted, err)
+	}
+
+	description := "payments v2"
+	updated, err := client.UpdateKey(ctx, &openkmsv1.UpdateKeyRequest{KeyId: key.GetId(), Description: &description})
+	if err != nil || updated.GetDescription() != description {
+		t.Errorf("Expected description %q, got %+v %v", description, updated, err)
+	}
+
+	rotated, err := client.RotateKey(ctx, &openkmsv1.KeyRequest{KeyId: key.GetId()})
+	if err != nil || rotated.GetPrimaryVersion() != 2 || len(rotated.GetVersions()) != 2 {
+		t.Errorf("Expected primary version 2, got %+v %v", rotated, err)
+	}
+
+	list, err := client.ListKeys(ctx, &openkmsv1.ListKeysRequest{})
+	if err != nil || len(list.GetKeys()) != 1 {
+		t.Errorf("Expected 1 key, got %+v %v", list, err)
+	}
+}
+
+func TestServer_UpdateKeyTags(t *testing.T) {
+	client := newClient(t, kmstest.NewService(t, nil), nil)
+	ctx := context.Background()
+
+	scenarios := []struct {
+		name string
+		tags *openkmsv1.Tags
+		want map[string]string
+	}{
+		{name: "Tags absent", want: map[string]string{"team": "billing"}},
+		{name: "Tags replaced", tags: &openkmsv1.Tags{Tags: map[string]string{"env": "prod"}}, want: map[string]string{"env": "prod"}},
+		{name: "Tags empty", tags: &openkmsv1.Tags{}, want: map[string]string{}},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			key, _ := client.CreateKey(ctx, &openkmsv1.CreateKeyRequest{Tags: map[string]string{"team": "billing"}})
+
+			updated, err := client.UpdateKey(ctx, &openkmsv1.UpdateKeyRequest{KeyId: key.GetId(), Tags: scenario.tags})
+			if err != nil || len(updated.GetTags()) != len(scenario.want) {
+				t.Fatalf("Expected tags %v, got %v %v", scenario.want, updated.GetTags(), err)
+			}
+			for name, value := range scenario.want {
+				if updated.GetTags()[name] != value {
+					t.Errorf("Expected tag %s=%s, got %v", name, value, updated.GetTags())
+				}
+			}
+		})
+	}
+}
+
+func TestServer_MacAndAsymmetricEncryption(t *testing.T) {
+	client := newClient(t, kmstest.NewService(t, nil), nil)
+	ctx := context.Background()
+
+	macKey, _ := client.CreateKey(ctx, &openkmsv1.CreateKeyRequest{Spec: kms.KEY_SPEC_HMAC_256, Usage: kms.KEY_USAGE_GENERATE_VERIFY_MAC})
+	mac, err := client.GenerateMac(ctx, &openkmsv1.GenerateMacRequest{KeyId: macKey.GetId(), Message: []byte("invoice 12"), Algorithm: kms.MAC_ALGORITHM_HMAC_SHA_256})
+	if err != nil || mac.GetKeyId() != macKey.GetId() || mac.GetKeyVersion() != 1 {
+		t.Fatalf("Expected MAC to be generated, got %+v %v", mac, err)
+	}
+	verified, err := client.VerifyMac(ctx, &openkmsv1.VerifyMacRequest{KeyId: macKey.GetId(), Message: []byte("invoice 12"), Mac: mac.GetMac(), Algorithm: kms.MAC_ALGORITHM_HMAC_SHA_256})
+	if err != nil || !verified.GetValid() {
+		t.Errorf("Expected MAC to verify, got %+v %v", verified, err)
+	}
+
+	rsaKey, _ := client.CreateKey(ctx, &openkmsv1.CreateKeyRequest{Spec: kms.KEY_SPEC_RSA_2048, Usage: kms.KEY_USAGE_ENCRYPT_DECRYPT})
+	encrypted, err := client.AsymmetricEncrypt(ctx, &openkmsv1.AsymmetricEncryptRequest{KeyId: rsaKey.GetId(), Plaintext: []byte("wrapped key"), Algorithm: kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256})
+	if err != nil || encrypted.GetKeyId() != rsaKey.GetId() {
+		t.Fatalf("Expected asymmetric encryption to succeed, got %+v %v", encrypted, err)
+	}
+	decrypted, err := client.AsymmetricDecrypt(ctx, &openkmsv1.AsymmetricDecryptRequest{KeyId: rsaKey.GetId(), Ciphertext: encrypted.GetCiphertext(), Algorithm: kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256})
+	if err != nil || string(decrypted.GetPlaintext()) != "wrapped key" {
+		t.Errorf("Expected asymmetric decryption to succeed, got %+v %v", decrypted, err)
+	}
+}
+
+// encryptStream - encrypts the plaintext through EncryptStream, sending it in messages of the given size.
+func encryptStream(t *testing.T, client openkmsv1.KeyManagementClient, keyID string, plaintext []byte, messageSize int) ([]byte, *openkmsv1.EncryptStreamResponse) {
+	t.Helper()
+
+	call, err := client.EncryptStream(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	request := &openkmsv1.EncryptStreamRequest{KeyId: keyID, EncryptionContext: map[string]string{"file": "report"}, ChunkSize: 1000}
+	for len(plaintext) > 0 {
+		n := min(len(plaintext), messageSize)
+		request.Plaintext, plaintext = plaintext[:n], plaintext[n:]
+		if err := call.Send(request); err != nil {
+			t.Fatalf("Failed to send plaintext: %v", err)
+		}
+		request = &openkmsv1.EncryptStreamRequest{}
+	}
+	call.CloseSend()
+
+	first, err := call.Recv()
+	if err != nil {
+		t.Fatalf("Expected stream encryption to succeed, got %v", err)
+	}
+	var ciphertext []byte
+	for {
+		response, err := call.Recv()
+		if err == io.EOF {
+			return ciphertext, first
+		}
+		if err != nil {
+			t.Fatalf("Expected stream encryption to succeed, got %v", err)
+		}
+		ciphertext = append(ciphertext, response.GetCiphertext()...)
+	}
+}
+
+// decryptStream - decrypts the ciphertext through DecryptStream, returning the plaintext received before any error.
+func decryptStream(client openkmsv1.KeyManagementClient, ciphertext []byte, messageSize int) ([]byte, error) {
+	call, err := client.DecryptStream(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	request := &openkmsv1.DecryptStreamRequest{EncryptionContext: map[string]string{"file": "report"}}
+	for len(ciphertext) > 0 {
+		n := min(len(ciphertext), messageSize)
+		request.Ciphertext, ciphertext = ciphertext[:n], ciphertext[n:]
+		if err := call.Send(request); err != nil {
+			break
+		}
+		request = &openkmsv1.DecryptStreamRequest{}
+	}
+	call.CloseSend()
+
+	var plaintext []byte
+	for {
+		response, err := call.Recv()
+		if err == io.EOF {
+			return plaintext, nil
+		}
+		if err != nil {
+			return plaintext, err
+		}
+		plaintext = append(plaintext, response.GetPlaintext()...)
+	}
+}
+
+func TestServer_Streams(t *testing.T) {
+	auditor := &kmstest.Auditor{}
+	service := kmstest.NewService(t, auditor)
+	client := newClient(t, service, auditor)
+
+	key, _ := client.CreateKey(context.Background(), &openkmsv1.CreateKeyRequest{})
+	plaintext := bytes.Repeat([]byte("quarterly report "), 1000)
+
+	ciphertext, first := encryptStream(t, client, key.GetId(), plaintext, 777)
+	if first.GetKeyId() != key.GetId() || first.GetKeyVersion() != 1 {
+		t.Errorf("Expected the first message to name %s version 1, got %+v", key.GetId(), first)
+	}
+
+	decrypted, err := decryptStream(client, ciphertext, 333)
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("Expected stream decryption to return the plaintext, got %d bytes, %v", len(decrypted), err)
+	}
+
+	handled := map[string]bool{}
+	for _, event := range auditor.Events() {
+		if event.Group == "TEST" && event.Labels["status"] == codes.OK.String() {
+			handled[event.Labels["method"]] = true
+		}
+	}
+	if !handled[openkmsv1.KeyManagement_EncryptStream_FullMethodName] || !handled[openkmsv1.KeyManagement_DecryptStream_FullMethodName] {
+		t.Errorf("Expected both streams to be audited, got %v", handled)
+	}
+
+	// The ciphertext is an encrypted stream as written by encrypt-file, readable with its data key alone.
+	//
+	header, err := stream.ReadHeader(bytes.NewReader(ciphertext))
+	if err != nil || header.ChunkSize != 1000 {
+		t.Fatalf("Expected an encrypted stream with 1000 byte chunks, got %+v, %v", header, err)
+	}
+	dataKey, err := service.Decrypt(context.Background(), header.EncryptedDataKey, map[string]string{"file": "report"})
+	if err != nil {
+		t.Fatalf("Failed to decrypt the data key: %v", err)
+	}
+	source := bytes.NewReader(ciphertext)
+	stream.ReadHeader(source)
+	reader, _ := stream.NewReader(source, header, dataKey)
+	if local, err := io.ReadAll(reader); err != nil || !bytes.Equal(local, plaintext) {
+		t.Errorf("Expected local decryption to return the plaintext, got %d bytes, %v", len(local), err)
+	}
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 0x01
+	scenarios := []struct {
+		name       string
+		ciphertext []byte
+	}{
+		{name: "Not an encrypted stream", ciphertext: []byte("plaintext")},
+		{name: "Truncated stream", ciphertext: ciphertext[:len(ciphertext)-1500]},
+		{name: "Tampered stream", ciphertext: tampered},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			decrypted, err := decryptStream(client, scenario.ciphertext, 333)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected code %s, got %v", codes.InvalidArgument, err)
+			}
+			if !bytes.HasPrefix(plaintext, decrypted) {
+				t.Errorf("Expected only authenticated plaintext, got %d unexpected bytes", len(decrypted))
+			}
+		})
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	client := newClient(t, kmstest.NewService(t, nil), nil)
+	ctx := context.Background()
+
+	key, _ := client.CreateKey(ctx, &openkmsv1.CreateKeyRequest{})
+	client.DisableKey(ctx, &openkmsv1.KeyRequest{KeyId: key.GetId()})
+
+	scenarios := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{
+			name: "Unknown key",
+			call: func() error {
+				_, err := client.DescribeKey(ctx, &openkmsv1.KeyRequest{KeyId: "00000000-0000-0000-0000-000000000000"})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "Unknown alias",
+			call: func() error {
+				_, err := client.DescribeKey(ctx, &openkmsv1.KeyRequest{KeyId: "alias/missing"})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "Unsupported key spec",
+			call: func() error {
+				_, err := client.CreateKey(ctx, &openkmsv1.CreateKeyRequest{Spec: "AES_1024"})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "Disabled key",
+			call: func() error {
+				_, err := client.Encrypt(ctx, &openkmsv1.EncryptRequest{KeyId: key.GetId(), Plaintext: []byte("x")})
+				return err
+			},
+			code: codes.FailedPrecondition,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if code := status.Code(scenario.call()); code != scenario.code {
+				t.Errorf("Expected code %s, got %s", scenario.code, code)
+			}
+		})
+	}
+}
+
+func TestServer_RequestIDInAuditLabels(t *testing.T) {
+	auditor := &kmstest.Auditor{}
+	client := newClient(t, kmstest.NewService(t, auditor), auditor)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), grpcapi.METADATA_REQUEST_ID, "trace-7")
+	if _, err := client.CreateKey(ctx, &openkmsv1.CreateKeyRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Expected key to be created, got %v", err)
+	}
+	if requestID := header.Get(grpcapi.METADATA_REQUEST_ID); len(requestID) != 1 || requestID[0] != "trace-7" {
+		t.Errorf("Expected request ID to be echoed, got %v", requestID)
+	}
+
+	groups := map[string]bool{}
+	for _, event := range auditor.Events() {
+		if event.Labels["requestId"] != "trace-7" {
+			t.Errorf("Expected request ID label on %q, got %v", event.Message, event.Labels)
+		}
+		groups[event.Group] = true
+	}
+	if !groups[kms.KMS_AUDIT_GROUP] || !groups["TEST"] {
+		t.Errorf("Expected events from the KMS and the API, got %v", groups)
+	}
+}
+
+// TestServer_RESTInteroperability - both APIs share the KMS service, so either can undo what the other did.
+func TestServer_RESTInteroperability(t *testing.T) {
+	service := kmstest.NewService(t, nil)
+	client := newClient(t, service, nil)
+	rest := restapi.NewServer(service, nil, "TEST", nil)
+
+	key, err := client.CreateKey(context.Background(), &openkmsv1.CreateKeyRequest{})
+	if err != nil {
+		t.Fatalf("Expected key to be created, got %v", err)
+	}
+	encrypted, err := client.Encrypt(context.Background(), &openkmsv1.EncryptRequest{KeyId: key.GetId(), Plaintext: []byte("shared")})
+	if err != nil {
+		t.Fatalf("Expected encryption to succeed, got %v", err)
+	}
+
+	body, _ := json.Marshal(restapi.DecryptRequest{Ciphertext: encrypted.GetCiphertext()})
+	request := httptest.NewRequest(http.MethodPost, "/v1/decrypt", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := rest.App().Test(request, -1)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+
+	var decrypted restapi.DecryptResponse
+	json.NewDecoder(response.Body).Decode(&decrypted)
+	if string(decrypted.Plaintext) != "shared" || decrypted.KeyID != key.GetId() {
+		t.Errorf("Expected the REST API to decrypt the gRPC ciphertext, got %+v", decrypted)
+	}
+}
@@ -0,0 +1,161 @@
+package grpcapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	openkmsv1 "github.com/hyperplane-sh/openkms/api/openkms/v1"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/stream"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// STREAM_MESSAGE_SIZE - most bytes of ciphertext or plaintext sent in one message, well below the default maximum
+	// message size of gRPC clients.
+	STREAM_MESSAGE_SIZE = 64 * 1024
+)
+
+// EncryptStream - encrypts the plaintext received with a data key generated for the stream, sending the encrypted
+// stream back as it is produced.
+func (s *Server) EncryptStream(call openkmsv1.KeyManagement_EncryptStreamServer) error {
+	first, err := call.Recv()
+	if err != nil {
+		return err
+	}
+
+	chunkSize := int(first.GetChunkSize())
+	if chunkSize == 0 {
+		chunkSize = stream.DEFAULT_CHUNK_SIZE
+	}
+	if chunkSize < 0 || chunkSize > stream.MAXIMUM_CHUNK_SIZE {
+		return status.Errorf(codes.InvalidArgument, "chunk size must be between 1 and %d", stream.MAXIMUM_CHUNK_SIZE)
+	}
+
+	dataKey, err := s.kmsService.GenerateDataKey(call.Context(), first.GetKeyId(), kms.DATA_KEY_SPEC_AES_256, first.GetEncryptionContext())
+	if err != nil {
+		return err
+	}
+	defer clear(dataKey.Plaintext)
+
+	if err := call.Send(&openkmsv1.EncryptStreamResponse{KeyId: dataKey.KeyID, KeyVersion: int32(dataKey.KeyVersion)}); err != nil {
+		return err
+	}
+
+	writer, err := stream.NewWriter(messageWriter(func(p []byte) error {
+		return call.Send(&openkmsv1.EncryptStreamResponse{Ciphertext: p})
+	}), dataKey.Plaintext, dataKey.Ciphertext, chunkSize)
+	if err != nil {
+		return err
+	}
+
+	plaintext := first.GetPlaintext()
+	for {
+		if _, err := writer.Write(plaintext); err != nil {
+			return err
+		}
+
+		request, err := call.Recv()
+		if errors.Is(err, io.EOF) {
+			return writer.Close()
+		}
+		if err != nil {
+			return err
+		}
+		plaintext = request.GetPlaintext()
+	}
+}
+
+// DecryptStream - decrypts the encrypted stream received, asking the KMS only for its data key, and sends the
+// plaintext of every chunk once it authenticated.
+func (s *Server) DecryptStream(call openkmsv1.KeyManagement_DecryptStreamServer) error {
+	first, err := call.Recv()
+	if err != nil {
+		return err
+	}
+
+	reader := &messageReader{buffer: first.GetCiphertext(), recv: func() ([]byte, error) {
+		request, err := call.Recv()
+		return request.GetCiphertext(), err
+	}}
+
+	header, err := stream.ReadHeader(reader)
+	if err != nil {
+		return reader.failure(err)
+	}
+
+	dataKey, err := s.kmsService.DecryptCiphertext(call.Context(), header.EncryptedDataKey, first.GetEncryptionContext())
+	if err != nil {
+		return err
+	}
+	defer clear(dataKey.Plaintext)
+
+	if err := call.Send(&openkmsv1.DecryptStreamResponse{KeyId: dataKey.KeyID, KeyVersion: int32(dataKey.KeyVersion)}); err != nil {
+		return err
+	}
+
+	plaintext, err := stream.NewReader(reader, header, dataKey.Plaintext)
+	if err != nil {
+		return reader.failure(err)
+	}
+	_, err = io.Copy(messageWriter(func(p []byte) error {
+		return call.Send(&openkmsv1.DecryptStreamResponse{Plaintext: p})
+	}), plaintext)
+	if err != nil {
+		return reader.failure(err)
+	}
+	return nil
+}
+
+// messageWriter - sends everything written to it in messages of at most STREAM_MESSAGE_SIZE bytes. Messages are
+// marshalled when sent, so the written bytes may be reused once Write returns.
+type messageWriter func(p []byte) error
+
+func (w messageWriter) Write(p []byte) (int, error) {
+	for written := 0; written < len(p); {
+		n := min(len(p)-written, STREAM_MESSAGE_SIZE)
+		if err := w(p[written : written+n]); err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return len(p), nil
+}
+
+// messageReader - reads the bytes of the messages received, io.EOF once the client closed its side of the stream.
+type messageReader struct {
+	buffer []byte
+	recv   func() ([]byte, error)
+	err    error // failure to receive a message, other than the end of the stream.
+}
+
+func (r *messageReader) Read(p []byte) (int, error) {
+	for len(r.buffer) == 0 {
+		data, err := r.recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				r.err = err
+			}
+			return 0, err
+		}
+		r.buffer = data
+	}
+
+	n := copy(p, r.buffer)
+	r.buffer = r.buffer[n:]
+	return n, nil
+}
+
+// failure - returns the error that ended the stream, the failure to receive a message first. An encrypted stream
+// malformed, truncated or failing authentication is an invalid ciphertext.
+func (r *messageReader) failure(err error) error {
+	switch {
+	case r.err != nil:
+		return r.err
+	case errors.Is(err, stream.ErrInvalidHeader), errors.Is(err, stream.ErrAuthentication), errors.Is(err, stream.ErrTruncated), errors.Is(err, stream.ErrTooManyChunks):
+		return fmt.Errorf("%w: %w", kms.ErrInvalidCiphertext, err)
+	}
+	return err
+}
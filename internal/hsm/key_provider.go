@@ -0,0 +1,395 @@
+//go:build cgo
+
+// Package hsm generates and uses the key versions of a KMS inside a PKCS #11 token, so their material never leaves
+// the HSM. The key store only keeps a handle to the objects of every version.
+package hsm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/miekg/pkcs11"
+)
+
+const (
+	HANDLE_SIZE = 16
+	TAG_BITS    = 128
+)
+
+var (
+	ErrObjectNotFound = errors.New("hsm: key version object not found")
+
+	// curves - object identifier of the curve of every elliptic curve key spec.
+	curves = map[string]asn1.ObjectIdentifier{
+		kms.KEY_SPEC_ECC_NIST_P256: {1, 2, 840, 10045, 3, 1, 7},
+		kms.KEY_SPEC_ECC_NIST_P384: {1, 3, 132, 0, 34},
+	}
+
+	// rsaModulusBits - modulus size of every RSA key spec.
+	rsaModulusBits = map[string]int{
+		kms.KEY_SPEC_RSA_2048: 2048,
+		kms.KEY_SPEC_RSA_3072: 3072,
+		kms.KEY_SPEC_RSA_4096: 4096,
+	}
+
+	// secretLengths - key length of every secret key spec.
+	secretLengths = map[string]int{
+		kms.KEY_SPEC_SYMMETRIC_DEFAULT: 32,
+		kms.KEY_SPEC_HMAC_256:          32,
+		kms.KEY_SPEC_HMAC_384:          48,
+		kms.KEY_SPEC_HMAC_512:          64,
+	}
+
+	// macMechanisms - mechanism computing every MAC algorithm.
+	macMechanisms = map[string]uint{
+		kms.MAC_ALGORITHM_HMAC_SHA_256: pkcs11.CKM_SHA256_HMAC,
+		kms.MAC_ALGORITHM_HMAC_SHA_384: pkcs11.CKM_SHA384_HMAC,
+		kms.MAC_ALGORITHM_HMAC_SHA_512: pkcs11.CKM_SHA512_HMAC,
+	}
+)
+
+// KeyProvider - key provider keeping the material of every key version as non-extractable objects of a token, the
+// objects of a version sharing a random CKA_ID stored as the version's handle.
+type KeyProvider struct {
+	module  *pkcs11.Ctx
+	slot    uint
+	session pkcs11.SessionHandle // logged in for the lifetime of the provider, so every other session is too.
+}
+
+// NewKeyProvider - constructor for KeyProvider. Loads the PKCS #11 module and logs in to the token with the given
+// label as its user.
+func NewKeyProvider(modulePath, tokenLabel, pin string) (*KeyProvider, error) {
+	module := pkcs11.New(modulePath)
+	if module == nil {
+		return nil, fmt.Errorf("hsm: failed to load module %s", modulePath)
+	}
+	if err := module.Initialize(); err != nil {
+		module.Destroy()
+		return nil, fmt.Errorf("hsm: failed to initialize module %s: %w", modulePath, err)
+	}
+
+	kP := &KeyProvider{module: module}
+	err := kP.open(tokenLabel, pin)
+	if err != nil {
+		module.Finalize()
+		module.Destroy()
+		return nil, err
+	}
+
+	return kP, nil
+}
+
+// Close - logs out of the token and unloads the module.
+func (kP *KeyProvider) Close() error {
+	kP.module.Logout(kP.session)
+	kP.module.CloseSession(kP.session)
+	err := kP.module.Finalize()
+	kP.module.Destroy()
+	return err
+}
+
+// GenerateKeyVersion - generates the objects of a key version inside the token.
+func (kP *KeyProvider) GenerateKeyVersion(ctx context.Context, keyID, spec string, version int) (kms.KeyVersion, error) {
+	handle := make([]byte, HANDLE_SIZE)
+	if _, err := rand.Read(handle); err != nil {
+		return kms.KeyVersion{}, err
+	}
+	label := "openkms/" + keyID + "/" + strconv.Itoa(version)
+
+	err := kP.withSession(ctx, func(session pkcs11.SessionHandle) error {
+		if length, ok := secretLengths[spec]; ok {
+			return kP.generateSecretKey(session, spec, length, handle, label)
+		}
+		return kP.generateKeyPair(session, spec, handle, label)
+	})
+	if err != nil {
+		return kms.KeyVersion{}, err
+	}
+
+	return kms.KeyVersion{
+		Version:   version,
+		Handle:    handle,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// DestroyKeyVersion - destroys every object of a key version.
+func (kP *KeyProvider) DestroyKeyVersion(ctx context.Context, version kms.KeyVersion) error {
+	return kP.withSession(ctx, func(session pkcs11.SessionHandle) error {
+		objects, err := kP.findObjects(session, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_ID, version.Handle)})
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			if err := kP.module.DestroyObject(session, object); err != nil {
+				return fmt.Errorf("hsm: failed to destroy key version %d: %w", version.Version, err)
+			}
+		}
+		return nil
+	})
+}
+
+// EncryptAES256GCM - encrypts the plaintext with CKM_AES_GCM inside the token.
+func (kP *KeyProvider) EncryptAES256GCM(ctx context.Context, version kms.KeyVersion, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	var ciphertext []byte
+	err := kP.withObject(ctx, version, pkcs11.CKO_SECRET_KEY, func(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) error {
+		params := pkcs11.NewGCMParams(nonce, additionalData, TAG_BITS)
+		defer params.Free()
+
+		err := kP.module.EncryptInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}, object)
+		if err != nil {
+			return err
+		}
+		ciphertext, err = kP.module.Encrypt(session, plaintext)
+		return err
+	})
+	return ciphertext, err
+}
+
+// DecryptAES256GCM - decrypts the ciphertext with CKM_AES_GCM inside the token.
+func (kP *KeyProvider) DecryptAES256GCM(ctx context.Context, version kms.KeyVersion, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 12 {
+		return nil, fmt.Errorf("%w: unexpected nonce size %d", kms.ErrInvalidCiphertext, len(nonce))
+	}
+
+	var plaintext []byte
+	err := kP.withObject(ctx, version, pkcs11.CKO_SECRET_KEY, func(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) error {
+		params := pkcs11.NewGCMParams(nonce, additionalData, TAG_BITS)
+		defer params.Free()
+
+		err := kP.module.DecryptInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}, object)
+		if err != nil {
+			return err
+		}
+
+		// Tokens disagree on the error returned for a wrong tag, any failure past initialization is a ciphertext
+		// that could not be authenticated.
+		//
+		plaintext, err = kP.module.Decrypt(session, ciphertext)
+		if err != nil {
+			return fmt.Errorf("%w: authentication failed", kms.ErrInvalidCiphertext)
+		}
+		return nil
+	})
+	return plaintext, err
+}
+
+// Signer - returns a signer using the private key object of an elliptic curve or RSA key version.
+func (kP *KeyProvider) Signer(ctx context.Context, spec string, version kms.KeyVersion) (crypto.Signer, error) {
+	return kP.newPrivateKey(ctx, spec, version)
+}
+
+// Decrypter - returns a decrypter using the private key object of an RSA key version.
+func (kP *KeyProvider) Decrypter(ctx context.Context, spec string, version kms.KeyVersion) (crypto.Decrypter, error) {
+	if _, ok := rsaModulusBits[spec]; !ok {
+		return nil, fmt.Errorf("%w: %s cannot decrypt", kms.ErrIncompatibleKey, spec)
+	}
+	return kP.newPrivateKey(ctx, spec, version)
+}
+
+// Mac - computes the HMAC of the message inside the token.
+func (kP *KeyProvider) Mac(ctx context.Context, algorithm string, version kms.KeyVersion, message []byte) ([]byte, error) {
+	mechanism, ok := macMechanisms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", kms.ErrUnsupportedMacAlgorithm, algorithm)
+	}
+
+	var mac []byte
+	err := kP.withObject(ctx, version, pkcs11.CKO_SECRET_KEY, func(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) error {
+		if err := kP.module.SignInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}, object); err != nil {
+			return err
+		}
+		var err error
+		mac, err = kP.module.Sign(session, message)
+		return err
+	})
+	return mac, err
+}
+
+// open - finds the token by its label, opens the session of the provider and logs in.
+func (kP *KeyProvider) open(tokenLabel, pin string) error {
+	slots, err := kP.module.GetSlotList(true)
+	if err != nil {
+		return fmt.Errorf("hsm: failed to list slots: %w", err)
+	}
+
+	found := false
+	for _, slot := range slots {
+		info, err := kP.module.GetTokenInfo(slot)
+		if err != nil {
+			return fmt.Errorf("hsm: failed to read token of slot %d: %w", slot, err)
+		}
+		if strings.TrimSpace(info.Label) == tokenLabel {
+			kP.slot = slot
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("hsm: no token labelled %q", tokenLabel)
+	}
+
+	kP.session, err = kP.module.OpenSession(kP.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("hsm: failed to open session: %w", err)
+	}
+
+	err = kP.module.Login(kP.session, pkcs11.CKU_USER, pin)
+	if err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		kP.module.CloseSession(kP.session)
+		return fmt.Errorf("hsm: failed to log in to token %q: %w", tokenLabel, err)
+	}
+
+	return nil
+}
+
+// generateSecretKey - generates the secret key object of an AES or HMAC key version.
+func (kP *KeyProvider) generateSecretKey(session pkcs11.SessionHandle, spec string, length int, handle []byte, label string) error {
+	mechanism := uint(pkcs11.CKM_GENERIC_SECRET_KEY_GEN)
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_GENERIC_SECRET),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+	}
+	if spec == kms.KEY_SPEC_SYMMETRIC_DEFAULT {
+		mechanism = pkcs11.CKM_AES_KEY_GEN
+		template = []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+			pkcs11.NewAttribute(pkcs11.CKA_ENCRYPT, true),
+			pkcs11.NewAttribute(pkcs11.CKA_DECRYPT, true),
+		}
+	}
+	template = append(template, pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, length))
+	template = append(template, privateAttributes(handle, label)...)
+
+	if _, err := kP.module.GenerateKey(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}, template); err != nil {
+		return fmt.Errorf("hsm: failed to generate %s key: %w", spec, err)
+	}
+	return nil
+}
+
+// generateKeyPair - generates the public and private key objects of an elliptic curve or RSA key version.
+func (kP *KeyProvider) generateKeyPair(session pkcs11.SessionHandle, spec string, handle []byte, label string) error {
+	var mechanism uint
+	publicTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, handle),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	privateTemplate := append([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+	}, privateAttributes(handle, label)...)
+
+	if curve, ok := curves[spec]; ok {
+		parameters, err := asn1.Marshal(curve)
+		if err != nil {
+			return err
+		}
+		mechanism = pkcs11.CKM_EC_KEY_PAIR_GEN
+		publicTemplate = append(publicTemplate,
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, parameters),
+		)
+		privateTemplate = append(privateTemplate, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC))
+	} else if bits, ok := rsaModulusBits[spec]; ok {
+		mechanism = pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN
+		publicTemplate = append(publicTemplate,
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_RSA),
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, bits),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{0x01, 0x00, 0x01}),
+			pkcs11.NewAttribute(pkcs11.CKA_ENCRYPT, true),
+		)
+		privateTemplate = append(privateTemplate,
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_RSA),
+			pkcs11.NewAttribute(pkcs11.CKA_DECRYPT, true),
+		)
+	} else {
+		return fmt.Errorf("%w: %s is not supported by the HSM key provider", kms.ErrUnsupportedKeySpec, spec)
+	}
+
+	_, _, err := kP.module.GenerateKeyPair(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}, publicTemplate, privateTemplate)
+	if err != nil {
+		return fmt.Errorf("hsm: failed to generate %s key pair: %w", spec, err)
+	}
+	return nil
+}
+
+// withSession - runs the function in a session of its own unless the request is done, sessions cannot be shared
+// by concurrent operations.
+func (kP *KeyProvider) withSession(ctx context.Context, run func(session pkcs11.SessionHandle) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	session, err := kP.module.OpenSession(kP.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("hsm: failed to open session: %w", err)
+	}
+	defer kP.module.CloseSession(session)
+
+	return run(session)
+}
+
+// withObject - runs the function in a session of its own with the object of the given class of a key version.
+func (kP *KeyProvider) withObject(ctx context.Context, version kms.KeyVersion, class uint, run func(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) error) error {
+	return kP.withSession(ctx, func(session pkcs11.SessionHandle) error {
+		objects, err := kP.findObjects(session, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+			pkcs11.NewAttribute(pkcs11.CKA_ID, version.Handle),
+		})
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return fmt.Errorf("%w: version %d", ErrObjectNotFound, version.Version)
+		}
+
+		return run(session, objects[0])
+	})
+}
+
+// findObjects - finds every object matching the template.
+func (kP *KeyProvider) findObjects(session pkcs11.SessionHandle, template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := kP.module.FindObjectsInit(session, template); err != nil {
+		return nil, fmt.Errorf("hsm: failed to find objects: %w", err)
+	}
+	defer kP.module.FindObjectsFinal(session)
+
+	found := []pkcs11.ObjectHandle{}
+	for {
+		objects, _, err := kP.module.FindObjects(session, 16)
+		if err != nil {
+			return nil, fmt.Errorf("hsm: failed to find objects: %w", err)
+		}
+		if len(objects) == 0 {
+			return found, nil
+		}
+		found = append(found, objects...)
+	}
+}
+
+// privateAttributes - attributes keeping the secret or private key object of a key version inside the token.
+func privateAttributes(handle []byte, label string) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, handle),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+}
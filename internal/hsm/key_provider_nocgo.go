@@ -0,0 +1,30 @@
+//go:build !cgo
+
+// Package hsm generates and uses the key versions of a KMS inside a PKCS #11 token, so their material never leaves
+// the HSM. The key store only keeps a handle to the objects of every version.
+package hsm
+
+import (
+	"errors"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+var (
+	ErrCgoRequired = errors.New("hsm: PKCS #11 support requires a build with cgo enabled")
+)
+
+// KeyProvider - placeholder for the PKCS #11 key provider in builds without cgo, it cannot be created.
+type KeyProvider struct {
+	kms.KeyProvider
+}
+
+// NewKeyProvider - always fails, loading a PKCS #11 module requires cgo.
+func NewKeyProvider(modulePath, tokenLabel, pin string) (*KeyProvider, error) {
+	return nil, ErrCgoRequired
+}
+
+// Close - does nothing, no provider can be created.
+func (kP *KeyProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,166 @@
+//go:build cgo
+
+package hsm_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/hsm"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+// newService - creates a service whose keys are held by the token configured in the environment, e.g. a SoftHSMv2
+// token initialized with softhsm2-util --init-token --free --label openkms. Skips the test without one.
+func newService(t *testing.T) (*kms.Service, *hsm.KeyProvider) {
+	t.Helper()
+
+	module := os.Getenv("OPENKMS_PKCS11_MODULE")
+	if module == "" {
+		t.Skip("OPENKMS_PKCS11_MODULE is not set, e.g. /usr/lib/softhsm/libsofthsm2.so")
+	}
+
+	provider, err := hsm.NewKeyProvider(module, os.Getenv("OPENKMS_PKCS11_TOKEN"), os.Getenv("OPENKMS_PKCS11_PIN"))
+	if err != nil {
+		t.Fatalf("Failed to load key provider: %v", err)
+	}
+	t.Cleanup(func() { provider.Close() })
+
+	providers := &kms.KeyProviders{
+		Default:   kms.KEY_PROVIDER_PKCS11,
+		Providers: map[string]kms.KeyProvider{kms.KEY_PROVIDER_PKCS11: provider},
+	}
+	return kmstest.OpenService(t, kms.NewKeyStoreStorage(kms.NewStorageBackendFile(t.TempDir()), nil, providers, nil), nil), provider
+}
+
+func TestKeyProvider_Encrypt(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newService(t)
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if key.Provider != kms.KEY_PROVIDER_PKCS11 || key.Versions[0].Material != nil {
+		t.Fatalf("Expected the key material to stay in the token, got %+v", key)
+	}
+
+	ciphertext, err := service.Encrypt(ctx, key.ID, []byte("secret"), map[string]string{"tenant": "acme"})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if _, err := service.KeyStore().RotateKey(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	if plaintext, err := service.Decrypt(ctx, ciphertext, map[string]string{"tenant": "acme"}); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Expected the plaintext, got %q %v", plaintext, err)
+	}
+	if _, err := service.Decrypt(ctx, ciphertext, map[string]string{"tenant": "other"}); !errors.Is(err, kms.ErrInvalidCiphertext) {
+		t.Errorf("Expected %v with another encryption context, got %v", kms.ErrInvalidCiphertext, err)
+	}
+}
+
+func TestKeyProvider_Sign(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newService(t)
+	digest256 := sha256.Sum256([]byte("message"))
+	digest384 := sha512.Sum384([]byte("message"))
+
+	scenarios := []struct {
+		spec      string
+		algorithm string
+		digest    []byte
+	}{
+		{kms.KEY_SPEC_ECC_NIST_P256, kms.SIGNING_ALGORITHM_ECDSA_SHA_256, digest256[:]},
+		{kms.KEY_SPEC_ECC_NIST_P384, kms.SIGNING_ALGORITHM_ECDSA_SHA_384, digest384[:]},
+		{kms.KEY_SPEC_RSA_2048, kms.SIGNING_ALGORITHM_RSASSA_PSS_SHA_256, digest256[:]},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.spec, func(t *testing.T) {
+			key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: scenario.spec})
+			if err != nil {
+				t.Fatalf("Failed to create key: %v", err)
+			}
+
+			// Signatures are verified in software with the public key read from the token.
+			//
+			signature, err := service.Sign(ctx, key.ID, scenario.digest, scenario.algorithm)
+			if err != nil {
+				t.Fatalf("Failed to sign: %v", err)
+			}
+			if valid, err := service.Verify(ctx, key.ID, scenario.digest, signature.Signature, scenario.algorithm); err != nil || !valid {
+				t.Errorf("Expected the signature to verify, got %t %v", valid, err)
+			}
+			if _, err := service.GetPublicKey(ctx, key.ID); err != nil {
+				t.Errorf("Expected the public key, got %v", err)
+			}
+		})
+	}
+
+	if _, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_ED25519}); !errors.Is(err, kms.ErrUnsupportedKeySpec) {
+		t.Errorf("Expected %v, got %v", kms.ErrUnsupportedKeySpec, err)
+	}
+}
+
+func TestKeyProvider_AsymmetricDecryptAndMac(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newService(t)
+
+	rsaKey, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_RSA_2048, Usage: kms.KEY_USAGE_ENCRYPT_DECRYPT})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	ciphertext, err := service.AsymmetricEncrypt(ctx, rsaKey.ID, []byte("secret"), kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if plaintext, err := service.AsymmetricDecrypt(ctx, rsaKey.ID, ciphertext, kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Expected the plaintext, got %q %v", plaintext, err)
+	}
+
+	macKey, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_HMAC_384})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	mac, err := service.GenerateMac(ctx, macKey.ID, []byte("message"), kms.MAC_ALGORITHM_HMAC_SHA_384)
+	if err != nil || len(mac.Mac) != sha512.Size384 {
+		t.Fatalf("Failed to generate MAC: %v", err)
+	}
+	if valid, err := service.VerifyMac(ctx, macKey.ID, []byte("message"), mac.Mac, kms.MAC_ALGORITHM_HMAC_SHA_384); err != nil || !valid {
+		t.Errorf("Expected the MAC to verify, got %t %v", valid, err)
+	}
+}
+
+func TestKeyProvider_DestroyKeyVersion(t *testing.T) {
+	ctx := context.Background()
+	_, provider := newService(t)
+
+	version, err := provider.GenerateKeyVersion(ctx, "destroyed", kms.KEY_SPEC_ECC_NIST_P256, 1)
+	if err != nil {
+		t.Fatalf("Failed to generate key version: %v", err)
+	}
+	if err := provider.DestroyKeyVersion(ctx, version); err != nil {
+		t.Fatalf("Failed to destroy key version: %v", err)
+	}
+
+	// Destruction is idempotent, a destroyed version cannot be used.
+	//
+	if err := provider.DestroyKeyVersion(ctx, version); err != nil {
+		t.Errorf("Expected destroying twice to succeed, got %v", err)
+	}
+	if _, err := provider.Signer(ctx, kms.KEY_SPEC_ECC_NIST_P256, version); !errors.Is(err, hsm.ErrObjectNotFound) {
+		t.Errorf("Expected %v, got %v", hsm.ErrObjectNotFound, err)
+	}
+}
+
+func TestNewKeyProvider_MissingModule(t *testing.T) {
+	if _, err := hsm.NewKeyProvider("/nonexistent/libpkcs11.so", "openkms", "1234"); err == nil {
+		t.Errorf("Expected loading a missing module to fail")
+	}
+}
@@ -0,0 +1,186 @@
+//go:build cgo
+
+package hsm
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/miekg/pkcs11"
+)
+
+var (
+	// hashMechanisms - digest mechanism and mask generation function of every hash used by RSA padding schemes.
+	hashMechanisms = map[crypto.Hash][2]uint{
+		crypto.SHA256: {pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256},
+		crypto.SHA384: {pkcs11.CKM_SHA384, pkcs11.CKG_MGF1_SHA384},
+		crypto.SHA512: {pkcs11.CKM_SHA512, pkcs11.CKG_MGF1_SHA512},
+	}
+
+	// ellipticCurves - curve of every elliptic curve key spec.
+	ellipticCurves = map[string]elliptic.Curve{
+		kms.KEY_SPEC_ECC_NIST_P256: elliptic.P256(),
+		kms.KEY_SPEC_ECC_NIST_P384: elliptic.P384(),
+	}
+)
+
+// privateKey - private key object of a key version, signing and decrypting inside the token.
+type privateKey struct {
+	ctx       context.Context // of the request the key was returned to, crypto.Signer taking none.
+	provider  *KeyProvider
+	version   kms.KeyVersion
+	publicKey crypto.PublicKey
+}
+
+// newPrivateKey - reads the public key of a key version from its public key object.
+func (kP *KeyProvider) newPrivateKey(ctx context.Context, spec string, version kms.KeyVersion) (*privateKey, error) {
+	var publicKey crypto.PublicKey
+	err := kP.withObject(ctx, version, pkcs11.CKO_PUBLIC_KEY, func(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) error {
+		var err error
+		if curve, ok := ellipticCurves[spec]; ok {
+			publicKey, err = kP.readECPublicKey(session, object, curve)
+		} else if _, ok := rsaModulusBits[spec]; ok {
+			publicKey, err = kP.readRSAPublicKey(session, object)
+		} else {
+			err = fmt.Errorf("%w: %s has no private key in the HSM", kms.ErrIncompatibleKey, spec)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &privateKey{ctx: ctx, provider: kP, version: version, publicKey: publicKey}, nil
+}
+
+// Public - returns the public key of the key version.
+func (p *privateKey) Public() crypto.PublicKey {
+	return p.publicKey
+}
+
+// Sign - signs the digest with CKM_ECDSA, or CKM_RSA_PKCS_PSS when the options are PSS options. ECDSA signatures are
+// converted to ASN.1 DER, as produced by the software provider.
+func (p *privateKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var mechanism *pkcs11.Mechanism
+	switch publicKey := p.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)
+	case *rsa.PublicKey:
+		pssOptions, ok := opts.(*rsa.PSSOptions)
+		if !ok {
+			return nil, fmt.Errorf("%w: the HSM only signs with RSASSA-PSS", kms.ErrUnsupportedSigningAlgorithm)
+		}
+		hash, ok := hashMechanisms[pssOptions.HashFunc()]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported hash %s", kms.ErrUnsupportedSigningAlgorithm, pssOptions.HashFunc())
+		}
+		saltLength := pssOptions.SaltLength
+		if saltLength == rsa.PSSSaltLengthEqualsHash {
+			saltLength = pssOptions.HashFunc().Size()
+		}
+		if saltLength <= 0 {
+			return nil, fmt.Errorf("%w: unsupported salt length %d", kms.ErrUnsupportedSigningAlgorithm, saltLength)
+		}
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_PSS, pkcs11.NewPSSParams(hash[0], hash[1], uint(saltLength)))
+	default:
+		return nil, fmt.Errorf("%w: unsupported public key %T", kms.ErrIncompatibleKey, publicKey)
+	}
+
+	var signature []byte
+	err := p.provider.withObject(p.ctx, p.version, pkcs11.CKO_PRIVATE_KEY, func(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) error {
+		if err := p.provider.module.SignInit(session, []*pkcs11.Mechanism{mechanism}, object); err != nil {
+			return err
+		}
+		var err error
+		signature, err = p.provider.module.Sign(session, digest)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.publicKey.(*ecdsa.PublicKey); ok {
+		return marshalECDSASignature(signature)
+	}
+	return signature, nil
+}
+
+// Decrypt - decrypts a ciphertext with CKM_RSA_PKCS_OAEP, the options must be OAEP options without a label.
+func (p *privateKey) Decrypt(_ io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	oaepOptions, ok := opts.(*rsa.OAEPOptions)
+	if !ok || len(oaepOptions.Label) != 0 || (oaepOptions.MGFHash != 0 && oaepOptions.MGFHash != oaepOptions.Hash) {
+		return nil, fmt.Errorf("%w: the HSM only decrypts with RSAES-OAEP", kms.ErrUnsupportedEncryptionAlgorithm)
+	}
+	hash, ok := hashMechanisms[oaepOptions.Hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported hash %s", kms.ErrUnsupportedEncryptionAlgorithm, oaepOptions.Hash)
+	}
+	mechanism := pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_OAEP, pkcs11.NewOAEPParams(hash[0], hash[1], pkcs11.CKZ_DATA_SPECIFIED, nil))
+
+	var plaintext []byte
+	err := p.provider.withObject(p.ctx, p.version, pkcs11.CKO_PRIVATE_KEY, func(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) error {
+		if err := p.provider.module.DecryptInit(session, []*pkcs11.Mechanism{mechanism}, object); err != nil {
+			return err
+		}
+		var err error
+		plaintext, err = p.provider.module.Decrypt(session, ciphertext)
+		return err
+	})
+	return plaintext, err
+}
+
+// readECPublicKey - reads the uncompressed point of an elliptic curve public key object.
+func (kP *KeyProvider) readECPublicKey(session pkcs11.SessionHandle, object pkcs11.ObjectHandle, curve elliptic.Curve) (*ecdsa.PublicKey, error) {
+	attributes, err := kP.module.GetAttributeValue(session, object, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil)})
+	if err != nil {
+		return nil, fmt.Errorf("hsm: failed to read public key: %w", err)
+	}
+
+	// CKA_EC_POINT holds the point wrapped in a DER octet string.
+	//
+	var point []byte
+	if _, err := asn1.Unmarshal(attributes[0].Value, &point); err != nil {
+		return nil, fmt.Errorf("hsm: malformed public key: %w", err)
+	}
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
+
+// readRSAPublicKey - reads the modulus and public exponent of an RSA public key object.
+func (kP *KeyProvider) readRSAPublicKey(session pkcs11.SessionHandle, object pkcs11.ObjectHandle) (*rsa.PublicKey, error) {
+	attributes, err := kP.module.GetAttributeValue(session, object, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hsm: failed to read public key: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(attributes[1].Value)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("hsm: unsupported public exponent %s", exponent)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(attributes[0].Value), E: int(exponent.Int64())}, nil
+}
+
+// marshalECDSASignature - converts the raw r || s signature of CKM_ECDSA to ASN.1 DER.
+func marshalECDSASignature(signature []byte) ([]byte, error) {
+	if len(signature) == 0 || len(signature)%2 != 0 {
+		return nil, fmt.Errorf("hsm: malformed ECDSA signature of %d bytes", len(signature))
+	}
+
+	half := len(signature) / 2
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(signature[:half]),
+		S: new(big.Int).SetBytes(signature[half:]),
+	})
+}
@@ -0,0 +1,165 @@
+// Package httpapi holds what the HTTP APIs of the KMS share: their Fiber application, the authentication of clients
+// by certificate, the audit of every request and the listeners they serve.
+package httpapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+const (
+	SHUTDOWN_TIMEOUT = 10 * time.Second
+
+	INTERNAL_ERROR_MESSAGE = "internal error"
+)
+
+// Auditing - where the requests of an API are recorded.
+type Auditing struct {
+	Auditor audit.Auditor // nil when auditing is disabled.
+	Group   string
+	Topic   string
+	Message string
+}
+
+// Middleware - runs every request of an API: assigns it an ID, authenticates the client, answers its error and
+// records its outcome.
+type Middleware struct {
+	RequestIDHeader string
+	Identities      *tlsconfig.IdentityMapper // maps client certificates to caller identities, nil accepting any subject.
+	HandleError     fiber.ErrorHandler        // answers an error with the error body of the API.
+	Auditing        Auditing
+}
+
+// NewApp - creates the Fiber application of an API, answering errors with the handler when not nil. Request values
+// are copied out of Fiber's buffers, as audit labels keep them after the handler returns.
+func NewApp(errorHandler fiber.ErrorHandler) *fiber.App {
+	config := fiber.Config{
+		AppName:               "OpenKMS",
+		DisableStartupMessage: true,
+		Immutable:             true,
+	}
+	if errorHandler != nil {
+		config.ErrorHandler = errorHandler
+	}
+	return fiber.New(config)
+}
+
+// Serve - listens on the address, over TLS when a configuration is given, until the context is done.
+func Serve(ctx context.Context, app *fiber.App, address string, tlsConfig *tls.Config) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	return ServeListener(ctx, app, listener)
+}
+
+// ServeListener - serves the connections accepted by the listener until the context is done, then shuts down
+// gracefully.
+func ServeListener(ctx context.Context, app *fiber.App, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		app.ShutdownWithTimeout(SHUTDOWN_TIMEOUT)
+	}()
+
+	err := app.Listener(listener)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// Authenticate - returns the context with the caller identity of a client presenting a certificate, recording its
+// subject in the labels. Subjects that are not mapped to an identity fail with tlsconfig.ErrUnknownSubject. Callers
+// without a certificate are anonymous, the daemon only serves the APIs on listeners requiring one.
+func Authenticate(ctx context.Context, c *fiber.Ctx, identities *tlsconfig.IdentityMapper, labels map[string]string) (context.Context, error) {
+	state := c.Context().TLSConnectionState()
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ctx, nil
+	}
+
+	certificate := state.PeerCertificates[0]
+	labels["clientSubject"] = certificate.Subject.String()
+
+	identity, err := identities.Identity(certificate)
+	if err != nil {
+		return ctx, err
+	}
+	return kms.WithCallerIdentity(ctx, identity), nil
+}
+
+// ErrorStatus - returns the HTTP status of an error, its own for Fiber errors, and the message answered for it. The
+// details of internal failures are only recorded in the audit log, never returned to clients.
+func ErrorStatus(err error, statuses map[string]int) (int, string) {
+	status := statuses[kms.ErrorCode(err)]
+
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		status = fiberError.Code
+	}
+
+	if status == fiber.StatusInternalServerError {
+		return status, INTERNAL_ERROR_MESSAGE
+	}
+	return status, err.Error()
+}
+
+// Record - records the outcome of a request, as a warning when it failed.
+func (a Auditing) Record(ctx context.Context, status int, labels map[string]string) {
+	if a.Auditor == nil {
+		return
+	}
+
+	level := audit.LEVEL_INFO
+	if status >= fiber.StatusBadRequest {
+		level = audit.LEVEL_WARN
+	}
+	labels["status"] = strconv.Itoa(status)
+	labels["callerIdentity"] = kms.CallerIdentity(ctx)
+
+	a.Auditor.RecordEvent(audit.NewEvent(level, a.Group, a.Topic, a.Message, labels))
+}
+
+// ServeRequest - runs the request through the next handlers. Errors are answered here rather than by the
+// application's error handler so their status can be recorded.
+func (m Middleware) ServeRequest(c *fiber.Ctx) error {
+	requestID := kms.ClientRequestID(c.Get(m.RequestIDHeader))
+	c.Set(m.RequestIDHeader, requestID)
+	c.Locals(m.RequestIDHeader, requestID)
+	c.SetUserContext(kms.WithRequestID(c.UserContext(), requestID))
+
+	labels := map[string]string{
+		"requestId":     requestID,
+		"method":        c.Method(),
+		"path":          c.Path(),
+		"remoteAddress": c.IP(),
+	}
+
+	ctx, err := Authenticate(c.UserContext(), c, m.Identities, labels)
+	if err != nil {
+		err = fiber.NewError(fiber.StatusForbidden, err.Error())
+	} else {
+		c.SetUserContext(ctx)
+		err = c.Next()
+	}
+	if err != nil {
+		if err := m.HandleError(c, err); err != nil {
+			return err
+		}
+		labels["error"] = err.Error()
+	}
+
+	m.Auditing.Record(c.UserContext(), c.Response().StatusCode(), labels)
+	return nil
+}
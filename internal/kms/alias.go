@@ -0,0 +1,54 @@
+package kms
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	ALIAS_PREFIX = "alias/"
+)
+
+var (
+	ErrAliasNotFound    = errors.New("alias not found")
+	ErrAliasExists      = errors.New("alias already exists")
+	ErrInvalidAliasName = errors.New("alias names must start with alias/ followed by letters, digits, /, _ or -")
+
+	aliasNamePattern = regexp.MustCompile(`^alias/[a-zA-Z0-9/_-]{1,250}$`)
+)
+
+// Alias - human friendly name pointing to a key.
+type Alias struct {
+	Name      string    `json:"name"`
+	KeyID     string    `json:"keyId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// IsAlias - reports whether the key identifier is an alias name rather than a key ID.
+func IsAlias(keyID string) bool {
+	return strings.HasPrefix(keyID, ALIAS_PREFIX)
+}
+
+// validateAliasName - makes sure the alias name is well formed.
+func validateAliasName(name string) error {
+	if !aliasNamePattern.MatchString(name) {
+		return ErrInvalidAliasName
+	}
+	return nil
+}
+
+// validateAliasTarget - makes sure a key can be the target of an alias, keeping the usage when retargeting.
+func validateAliasTarget(target Key, previous *Key) error {
+	if err := target.requireState("aliasing", KEY_STATE_ENABLED, KEY_STATE_DISABLED); err != nil {
+		return err
+	}
+
+	if previous != nil && previous.Usage != target.Usage {
+		return ErrIncompatibleKey
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package kms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+const (
+	SIGNING_ALGORITHM_ECDSA_SHA_256      = "ECDSA_SHA_256"
+	SIGNING_ALGORITHM_ECDSA_SHA_384      = "ECDSA_SHA_384"
+	SIGNING_ALGORITHM_ED25519            = "ED25519"
+	SIGNING_ALGORITHM_RSASSA_PSS_SHA_256 = "RSASSA_PSS_SHA_256"
+	SIGNING_ALGORITHM_RSASSA_PSS_SHA_384 = "RSASSA_PSS_SHA_384"
+	SIGNING_ALGORITHM_RSASSA_PSS_SHA_512 = "RSASSA_PSS_SHA_512"
+)
+
+var (
+	ErrUnsupportedSigningAlgorithm = errors.New("unsupported signing algorithm for key")
+	ErrInvalidDigest               = errors.New("digest length does not match the signing algorithm")
+
+	// signingAlgorithms - signing algorithms supported by every asymmetric key spec.
+	signingAlgorithms = map[string][]string{
+		KEY_SPEC_ECC_NIST_P256: {SIGNING_ALGORITHM_ECDSA_SHA_256},
+		KEY_SPEC_ECC_NIST_P384: {SIGNING_ALGORITHM_ECDSA_SHA_384},
+		KEY_SPEC_ED25519:       {SIGNING_ALGORITHM_ED25519},
+		KEY_SPEC_RSA_2048:      {SIGNING_ALGORITHM_RSASSA_PSS_SHA_256, SIGNING_ALGORITHM_RSASSA_PSS_SHA_384, SIGNING_ALGORITHM_RSASSA_PSS_SHA_512},
+		KEY_SPEC_RSA_3072:      {SIGNING_ALGORITHM_RSASSA_PSS_SHA_256, SIGNING_ALGORITHM_RSASSA_PSS_SHA_384, SIGNING_ALGORITHM_RSASSA_PSS_SHA_512},
+		KEY_SPEC_RSA_4096:      {SIGNING_ALGORITHM_RSASSA_PSS_SHA_256, SIGNING_ALGORITHM_RSASSA_PSS_SHA_384, SIGNING_ALGORITHM_RSASSA_PSS_SHA_512},
+	}
+
+	// signingHashes - hash function whose digest each signing algorithm expects.
+	signingHashes = map[string]crypto.Hash{
+		SIGNING_ALGORITHM_ECDSA_SHA_256:      crypto.SHA256,
+		SIGNING_ALGORITHM_ECDSA_SHA_384:      crypto.SHA384,
+		SIGNING_ALGORITHM_RSASSA_PSS_SHA_256: crypto.SHA256,
+		SIGNING_ALGORITHM_RSASSA_PSS_SHA_384: crypto.SHA384,
+		SIGNING_ALGORITHM_RSASSA_PSS_SHA_512: crypto.SHA512,
+	}
+)
+
+// Signature - signature produced by a version of an asymmetric key.
+type Signature struct {
+	KeyID      string
+	KeyVersion int
+	Algorithm  string
+	Signature  []byte
+}
+
+// PublicKey - public half of an asymmetric key version.
+type PublicKey struct {
+	KeyID      string
+	KeyVersion int
+	Spec       string
+	Usage      string
+	DER        []byte // PKIX, ASN.1 DER encoded.
+	PEM        []byte
+	JWK        []byte
+}
+
+// Sign - signs a digest with the primary version of the given key. Ed25519 signs the input as the message itself.
+func (s *Service) Sign(ctx context.Context, keyID string, digest []byte, algorithm string) (Signature, error) {
+	key, err := s.signingKey(ctx, keyID, algorithm)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	if err := validateDigest(algorithm, digest); err != nil {
+		return Signature{}, err
+	}
+
+	version, err := key.Primary()
+	if err != nil {
+		return Signature{}, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	signer, err := provider.Signer(ctx, key.Spec, version)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	var signature []byte
+	switch algorithm {
+	case SIGNING_ALGORITHM_ED25519:
+		signature, err = signer.Sign(rand.Reader, digest, crypto.Hash(0))
+	case SIGNING_ALGORITHM_RSASSA_PSS_SHA_256, SIGNING_ALGORITHM_RSASSA_PSS_SHA_384, SIGNING_ALGORITHM_RSASSA_PSS_SHA_512:
+		signature, err = signer.Sign(rand.Reader, digest, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+			Hash:       signingHashes[algorithm],
+		})
+	default:
+		signature, err = signer.Sign(rand.Reader, digest, signingHashes[algorithm])
+	}
+	if err != nil {
+		return Signature{}, err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Digest signed", map[string]string{
+		"keyId":            key.ID,
+		"keyVersion":       strconv.Itoa(version.Version),
+		"signingAlgorithm": algorithm,
+	})
+
+	return Signature{
+		KeyID:      key.ID,
+		KeyVersion: version.Version,
+		Algorithm:  algorithm,
+		Signature:  signature,
+	}, nil
+}
+
+// Verify - reports whether the signature over the digest was produced by any version of the given key.
+func (s *Service) Verify(ctx context.Context, keyID string, digest, signature []byte, algorithm string) (bool, error) {
+	key, err := s.signingKey(ctx, keyID, algorithm)
+	if err != nil {
+		return false, err
+	}
+
+	if err := validateDigest(algorithm, digest); err != nil {
+		return false, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return false, err
+	}
+
+	valid := false
+	verifiedVersion := 0
+	for _, version := range key.Versions {
+		signer, err := provider.Signer(ctx, key.Spec, version)
+		if err != nil {
+			return false, err
+		}
+
+		if verifySignature(signer.Public(), algorithm, digest, signature) {
+			valid = true
+			verifiedVersion = version.Version
+			break
+		}
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Signature verified", map[string]string{
+		"keyId":            key.ID,
+		"keyVersion":       strconv.Itoa(verifiedVersion),
+		"signingAlgorithm": algorithm,
+		"valid":            strconv.FormatBool(valid),
+	})
+
+	return valid, nil
+}
+
+// GetPublicKey - returns the public key of the primary version of an asymmetric key.
+func (s *Service) GetPublicKey(ctx context.Context, keyID string) (PublicKey, error) {
+	key, err := s.enabledKey(ctx, keyID, "getting the public key")
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	if _, asymmetric := signingAlgorithms[key.Spec]; !asymmetric {
+		return PublicKey{}, fmt.Errorf("%w: %s has no public key", ErrIncompatibleKey, key.Spec)
+	}
+
+	version, err := key.Primary()
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	signer, err := provider.Signer(ctx, key.Spec, version)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	jwk, err := marshalJWK(key.ID, key.Usage, signer.Public())
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	return PublicKey{
+		KeyID:      key.ID,
+		KeyVersion: version.Version,
+		Spec:       key.Spec,
+		Usage:      key.Usage,
+		DER:        der,
+		PEM:        pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		JWK:        jwk,
+	}, nil
+}
+
+// signingKey - retrieves a key and makes sure it can sign with the given algorithm.
+func (s *Service) signingKey(ctx context.Context, keyID, algorithm string) (Key, error) {
+	key, err := s.enabledKey(ctx, keyID, "signing")
+	if err != nil {
+		return Key{}, err
+	}
+
+	if key.Usage != KEY_USAGE_SIGN_VERIFY {
+		return Key{}, fmt.Errorf("%w: %s is not a signing key", ErrIncompatibleKey, key.ID)
+	}
+
+	supported := false
+	for _, candidate := range signingAlgorithms[key.Spec] {
+		supported = supported || candidate == algorithm
+	}
+	if !supported {
+		return Key{}, fmt.Errorf("%w: %s cannot sign with %s", ErrUnsupportedSigningAlgorithm, key.Spec, algorithm)
+	}
+
+	return key, nil
+}
+
+// SigningAlgorithms - signing algorithms supported by a key spec, none for keys that cannot sign.
+func SigningAlgorithms(spec string) []string {
+	return slices.Clone(signingAlgorithms[spec])
+}
+
+// SigningHash - hash function whose digest the signing algorithm expects, zero for algorithms signing the message
+// itself.
+func SigningHash(algorithm string) crypto.Hash {
+	return signingHashes[algorithm]
+}
+
+// validateDigest - makes sure the digest has the length produced by the algorithm's hash.
+func validateDigest(algorithm string, digest []byte) error {
+	hash, ok := signingHashes[algorithm]
+	if ok && len(digest) != hash.Size() {
+		return fmt.Errorf("%w: %s expects %d bytes", ErrInvalidDigest, algorithm, hash.Size())
+	}
+	return nil
+}
+
+// verifySignature - verifies a signature with the given public key.
+func verifySignature(publicKey crypto.PublicKey, algorithm string, digest, signature []byte) bool {
+	switch publicKey := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(publicKey, digest, signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(publicKey, digest, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPSS(publicKey, signingHashes[algorithm], digest, signature, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		}) == nil
+	}
+	return false
+}
+
+// generatePrivateKey - generates a private key for an asymmetric key spec, encoded as PKCS #8 DER.
+func generatePrivateKey(spec string) ([]byte, error) {
+	var privateKey any
+	var err error
+
+	switch spec {
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKeySpec, spec)
+	case KEY_SPEC_ECC_NIST_P256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KEY_SPEC_ECC_NIST_P384:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case KEY_SPEC_ED25519:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	case KEY_SPEC_RSA_2048:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case KEY_SPEC_RSA_3072:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 3072)
+	case KEY_SPEC_RSA_4096:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 4096)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.MarshalPKCS8PrivateKey(privateKey)
+}
+
+// parsePrivateKey - parses PKCS #8 DER key material into a signer.
+func parsePrivateKey(material []byte) (crypto.Signer, error) {
+	privateKey, err := x509.ParsePKCS8PrivateKey(material)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: key material is not a signing key", ErrIncompatibleKey)
+	}
+	return signer, nil
+}
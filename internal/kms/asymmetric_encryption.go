@@ -0,0 +1,132 @@
+package kms
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+const (
+	ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256 = "RSAES_OAEP_SHA_256"
+)
+
+var (
+	ErrUnsupportedEncryptionAlgorithm = errors.New("unsupported encryption algorithm for key")
+)
+
+// AsymmetricEncrypt - encrypts the plaintext with the public key of the primary version of an RSA key.
+func (s *Service) AsymmetricEncrypt(ctx context.Context, keyID string, plaintext []byte, algorithm string) ([]byte, error) {
+	key, err := s.asymmetricEncryptionKey(ctx, keyID, algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := key.Primary()
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypter, err := provider.Decrypter(ctx, key.Spec, version)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := decrypter.Public().(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not an RSA key", ErrIncompatibleKey, key.ID)
+	}
+
+	ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, plaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Plaintext encrypted", map[string]string{
+		"keyId":               key.ID,
+		"keyVersion":          strconv.Itoa(version.Version),
+		"encryptionAlgorithm": algorithm,
+	})
+
+	return ciphertext, nil
+}
+
+// AsymmetricDecrypt - decrypts a ciphertext produced with the public key of any version of an RSA key.
+func (s *Service) AsymmetricDecrypt(ctx context.Context, keyID string, ciphertext []byte, algorithm string) ([]byte, error) {
+	key, err := s.asymmetricEncryptionKey(ctx, keyID, algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ciphertext does not reference a key version, try the newest versions first.
+	//
+	for i := len(key.Versions) - 1; i >= 0; i-- {
+		decrypter, err := provider.Decrypter(ctx, key.Spec, key.Versions[i])
+		if err != nil {
+			return nil, err
+		}
+
+		plaintext, err := decrypter.Decrypt(nil, ciphertext, &rsa.OAEPOptions{Hash: crypto.SHA256})
+		if err != nil {
+			continue
+		}
+
+		recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Ciphertext decrypted", map[string]string{
+			"keyId":               key.ID,
+			"keyVersion":          strconv.Itoa(key.Versions[i].Version),
+			"encryptionAlgorithm": algorithm,
+		})
+
+		return plaintext, nil
+	}
+
+	return nil, fmt.Errorf("%w: decryption failed", ErrInvalidCiphertext)
+}
+
+// asymmetricEncryptionKey - retrieves a key and makes sure it can encrypt with the given algorithm.
+func (s *Service) asymmetricEncryptionKey(ctx context.Context, keyID, algorithm string) (Key, error) {
+	key, err := s.enabledKey(ctx, keyID, "asymmetric encryption")
+	if err != nil {
+		return Key{}, err
+	}
+
+	if key.Usage != KEY_USAGE_ENCRYPT_DECRYPT || key.Spec == KEY_SPEC_SYMMETRIC_DEFAULT {
+		return Key{}, fmt.Errorf("%w: %s is not an asymmetric encryption key", ErrIncompatibleKey, key.ID)
+	}
+
+	if algorithm != ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256 {
+		return Key{}, fmt.Errorf("%w: %s cannot encrypt with %s", ErrUnsupportedEncryptionAlgorithm, key.Spec, algorithm)
+	}
+
+	return key, nil
+}
+
+// parseRSAPrivateKey - parses PKCS #8 DER key material into an RSA private key.
+func parseRSAPrivateKey(material []byte) (*rsa.PrivateKey, error) {
+	signer, err := parsePrivateKey(material)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := signer.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: key material is not an RSA key", ErrIncompatibleKey)
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,245 @@
+package kms_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+func TestSign_VerifiedWithStandardLibrary(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	message := []byte("openkms release artifact v1.2.3")
+	sha256Digest := sha256.Sum256(message)
+	sha384Digest := sha512.Sum384(message)
+	sha512Digest := sha512.Sum512(message)
+
+	scenarios := []struct {
+		name      string
+		spec      string
+		algorithm string
+		digest    []byte
+		verify    func(publicKey any, digest, signature []byte) bool
+	}{
+		{
+			name:      "ECDSA P-256",
+			spec:      kms.KEY_SPEC_ECC_NIST_P256,
+			algorithm: kms.SIGNING_ALGORITHM_ECDSA_SHA_256,
+			digest:    sha256Digest[:],
+			verify: func(publicKey any, digest, signature []byte) bool {
+				return ecdsa.VerifyASN1(publicKey.(*ecdsa.PublicKey), digest, signature)
+			},
+		},
+		{
+			name:      "ECDSA P-384",
+			spec:      kms.KEY_SPEC_ECC_NIST_P384,
+			algorithm: kms.SIGNING_ALGORITHM_ECDSA_SHA_384,
+			digest:    sha384Digest[:],
+			verify: func(publicKey any, digest, signature []byte) bool {
+				return ecdsa.VerifyASN1(publicKey.(*ecdsa.PublicKey), digest, signature)
+			},
+		},
+		{
+			name:      "Ed25519",
+			spec:      kms.KEY_SPEC_ED25519,
+			algorithm: kms.SIGNING_ALGORITHM_ED25519,
+			digest:    message,
+			verify: func(publicKey any, digest, signature []byte) bool {
+				return ed25519.Verify(publicKey.(ed25519.PublicKey), digest, signature)
+			},
+		},
+		{
+			name:      "RSA-PSS SHA-256",
+			spec:      kms.KEY_SPEC_RSA_2048,
+			algorithm: kms.SIGNING_ALGORITHM_RSASSA_PSS_SHA_256,
+			digest:    sha256Digest[:],
+			verify: func(publicKey any, digest, signature []byte) bool {
+				return rsa.VerifyPSS(publicKey.(*rsa.PublicKey), crypto.SHA256, digest, signature, nil) == nil
+			},
+		},
+		{
+			name:      "RSA-PSS SHA-512",
+			spec:      kms.KEY_SPEC_RSA_2048,
+			algorithm: kms.SIGNING_ALGORITHM_RSASSA_PSS_SHA_512,
+			digest:    sha512Digest[:],
+			verify: func(publicKey any, digest, signature []byte) bool {
+				return rsa.VerifyPSS(publicKey.(*rsa.PublicKey), crypto.SHA512, digest, signature, nil) == nil
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: scenario.spec})
+			if err != nil {
+				t.Fatalf("Failed to create key: %v", err)
+			}
+			if key.Usage != kms.KEY_USAGE_SIGN_VERIFY {
+				t.Errorf("Expected usage %s, got %s", kms.KEY_USAGE_SIGN_VERIFY, key.Usage)
+			}
+
+			signature, err := service.Sign(ctx, key.ID, scenario.digest, scenario.algorithm)
+			if err != nil {
+				t.Fatalf("Failed to sign: %v", err)
+			}
+
+			publicKey, err := service.GetPublicKey(ctx, key.ID)
+			if err != nil {
+				t.Fatalf("Failed to get public key: %v", err)
+			}
+
+			block, _ := pem.Decode(publicKey.PEM)
+			if block == nil || block.Type != "PUBLIC KEY" || string(block.Bytes) != string(publicKey.DER) {
+				t.Fatalf("Expected PEM to wrap the DER public key")
+			}
+
+			parsed, err := x509.ParsePKIXPublicKey(publicKey.DER)
+			if err != nil {
+				t.Fatalf("Failed to parse public key: %v", err)
+			}
+
+			if !scenario.verify(parsed, scenario.digest, signature.Signature) {
+				t.Errorf("Expected signature to verify with the standard library")
+			}
+
+			valid, err := service.Verify(ctx, key.ID, scenario.digest, signature.Signature, scenario.algorithm)
+			if err != nil || !valid {
+				t.Errorf("Expected KMS to verify the signature, got %v, %v", valid, err)
+			}
+
+			tampered := append([]byte{}, signature.Signature...)
+			tampered[len(tampered)-1] ^= 0xff
+			valid, err = service.Verify(ctx, key.ID, scenario.digest, tampered, scenario.algorithm)
+			if err != nil || valid {
+				t.Errorf("Expected tampered signature to be rejected, got %v, %v", valid, err)
+			}
+		})
+	}
+}
+
+func TestSign_Rejections(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	signingKey, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_ECC_NIST_P256})
+	symmetricKey, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	digest := sha256.Sum256([]byte("payload"))
+
+	if _, err := service.Sign(ctx, signingKey.ID, digest[:], kms.SIGNING_ALGORITHM_ECDSA_SHA_384); !errors.Is(err, kms.ErrUnsupportedSigningAlgorithm) {
+		t.Errorf("Expected ErrUnsupportedSigningAlgorithm, got %v", err)
+	}
+	if _, err := service.Sign(ctx, signingKey.ID, digest[:16], kms.SIGNING_ALGORITHM_ECDSA_SHA_256); !errors.Is(err, kms.ErrInvalidDigest) {
+		t.Errorf("Expected ErrInvalidDigest, got %v", err)
+	}
+	if _, err := service.Sign(ctx, symmetricKey.ID, digest[:], kms.SIGNING_ALGORITHM_ECDSA_SHA_256); !errors.Is(err, kms.ErrIncompatibleKey) {
+		t.Errorf("Expected ErrIncompatibleKey, got %v", err)
+	}
+	if _, err := service.Encrypt(ctx, signingKey.ID, []byte("payload"), nil); !errors.Is(err, kms.ErrIncompatibleKey) {
+		t.Errorf("Expected ErrIncompatibleKey, got %v", err)
+	}
+	if _, err := service.GetPublicKey(ctx, symmetricKey.ID); !errors.Is(err, kms.ErrIncompatibleKey) {
+		t.Errorf("Expected ErrIncompatibleKey, got %v", err)
+	}
+	if _, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_ED25519, Usage: kms.KEY_USAGE_ENCRYPT_DECRYPT}); !errors.Is(err, kms.ErrUnsupportedKeyUsage) {
+		t.Errorf("Expected ErrUnsupportedKeyUsage, got %v", err)
+	}
+}
+
+func TestAsymmetricDecrypt_RSAOAEP(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_RSA_2048, Usage: kms.KEY_USAGE_ENCRYPT_DECRYPT})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	publicKey, err := service.GetPublicKey(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Failed to get public key: %v", err)
+	}
+
+	// Encrypt offline with the exported PEM public key.
+	//
+	block, _ := pem.Decode(publicKey.PEM)
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+	ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, parsed.(*rsa.PublicKey), []byte("partner secret"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// Encrypt offline with the exported JWK public key.
+	//
+	var jwk struct {
+		KeyType   string `json:"kty"`
+		KeyID     string `json:"kid"`
+		Use       string `json:"use"`
+		Algorithm string `json:"alg"`
+		N         string `json:"n"`
+		E         string `json:"e"`
+	}
+	if err := json.Unmarshal(publicKey.JWK, &jwk); err != nil {
+		t.Fatalf("Failed to unmarshal JWK: %v", err)
+	}
+	if jwk.KeyType != "RSA" || jwk.KeyID != key.ID || jwk.Use != "enc" || jwk.Algorithm != "RSA-OAEP-256" {
+		t.Errorf("Unexpected JWK %s", publicKey.JWK)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(jwk.N)
+	e, _ := base64.RawURLEncoding.DecodeString(jwk.E)
+	jwkPublicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	jwkCiphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, jwkPublicKey, []byte("jwk secret"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// Rotation must keep ciphertexts produced for older versions decryptable.
+	//
+	if _, err := service.KeyStore().RotateKey(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+
+	plaintext, err := service.AsymmetricDecrypt(ctx, key.ID, ciphertext, kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256)
+	if err != nil || string(plaintext) != "partner secret" {
+		t.Errorf("Expected %q, got %q, %v", "partner secret", plaintext, err)
+	}
+	plaintext, err = service.AsymmetricDecrypt(ctx, key.ID, jwkCiphertext, kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256)
+	if err != nil || string(plaintext) != "jwk secret" {
+		t.Errorf("Expected %q, got %q, %v", "jwk secret", plaintext, err)
+	}
+
+	ciphertext, err = service.AsymmetricEncrypt(ctx, key.ID, []byte("round trip"), kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	plaintext, err = service.AsymmetricDecrypt(ctx, key.ID, ciphertext, kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256)
+	if err != nil || string(plaintext) != "round trip" {
+		t.Errorf("Expected %q, got %q, %v", "round trip", plaintext, err)
+	}
+
+	digest := sha256.Sum256([]byte("payload"))
+	if _, err := service.Sign(ctx, key.ID, digest[:], kms.SIGNING_ALGORITHM_RSASSA_PSS_SHA_256); !errors.Is(err, kms.ErrIncompatibleKey) {
+		t.Errorf("Expected ErrIncompatibleKey, got %v", err)
+	}
+	if _, err := service.AsymmetricDecrypt(ctx, key.ID, []byte("garbage"), kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256); !errors.Is(err, kms.ErrInvalidCiphertext) {
+		t.Errorf("Expected ErrInvalidCiphertext, got %v", err)
+	}
+}
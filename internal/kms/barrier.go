@@ -0,0 +1,320 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperplane-sh/openkms/internal/shamir"
+)
+
+// Values sealed by the barrier. The format version and nonce are authenticated by AES-GCM together with the
+// caller's additional data, which binds a value to its place in storage.
+//
+//	+---------+----------+--------------------+
+//	| version | nonce    | ciphertext and tag |
+//	| 1 byte  | 12 bytes | remaining bytes    |
+//	+---------+----------+--------------------+
+const (
+	BARRIER_FORMAT_V1 = 0x01
+
+	ROOT_KEY_SIZE = 32
+
+	SEAL_TYPE_SHAMIR  = "shamir"  // the root key is split into unseal shares held by operators.
+	SEAL_TYPE_TRANSIT = "transit" // the root key is wrapped by a key of another KMS, which unseals automatically.
+
+	// SEAL_VERIFICATION - value sealed under the root key at initialization, opened to check unseal shares.
+	SEAL_VERIFICATION = "openkms-root-key-verification"
+)
+
+var (
+	ErrSealed             = errors.New("kms is sealed")
+	ErrNotInitialized     = errors.New("kms is not initialized")
+	ErrAlreadyInitialized = errors.New("kms is already initialized")
+	ErrUnsealFailed       = errors.New("unsealing did not recover the root key")
+	ErrSealUnsupported    = errors.New("key store does not seal key material")
+	ErrSealMismatch       = errors.New("operation does not apply to the seal type")
+)
+
+// SealConfiguration - how the root key was split or wrapped, persisted in plaintext next to the keys.
+type SealConfiguration struct {
+	Type           string `json:"type"`
+	Shares         int    `json:"shares,omitempty"`
+	Threshold      int    `json:"threshold,omitempty"`
+	WrappedRootKey []byte `json:"wrappedRootKey,omitempty"`
+	Verification   []byte `json:"verification"`
+}
+
+// SealStatus - seal state of the KMS.
+type SealStatus struct {
+	Type        string
+	Initialized bool
+	Sealed      bool
+	Shares      int
+	Threshold   int
+	Progress    int // unseal shares submitted towards the threshold.
+}
+
+// KeyWrapper - wraps the root key under a key held outside of the KMS, so the KMS can unseal without operators.
+type KeyWrapper interface {
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// Barrier - encrypts stored keys under a root key held only in memory. A sealed barrier holds no root key and
+// refuses to encrypt or decrypt anything.
+type Barrier struct {
+	lock     sync.RWMutex
+	aead     cipher.AEAD // nil while sealed.
+	pending  [][]byte    // unseal shares submitted so far.
+	unsealed chan struct{}
+
+	// Key wrapper of the transit seal, nil when the root key is split into unseal shares.
+	//
+	wrapper KeyWrapper
+}
+
+// NewBarrier - barrier whose root key is split into unseal shares.
+func NewBarrier() *Barrier {
+	return &Barrier{unsealed: make(chan struct{})}
+}
+
+// NewTransitBarrier - barrier whose root key is wrapped by the key wrapper.
+func NewTransitBarrier(wrapper KeyWrapper) *Barrier {
+	return &Barrier{unsealed: make(chan struct{}), wrapper: wrapper}
+}
+
+// Type - returns the seal type of the barrier.
+func (b *Barrier) Type() string {
+	if b.wrapper != nil {
+		return SEAL_TYPE_TRANSIT
+	}
+	return SEAL_TYPE_SHAMIR
+}
+
+// Sealed - reports whether the barrier is sealed.
+func (b *Barrier) Sealed() bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.aead == nil
+}
+
+// WaitUnsealed - blocks until the barrier is unsealed or the context is done.
+func (b *Barrier) WaitUnsealed(ctx context.Context) error {
+	b.lock.RLock()
+	unsealed := b.unsealed
+	b.lock.RUnlock()
+
+	select {
+	case <-unsealed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Encrypt - seals a value under the root key, bound to the additional data.
+func (b *Barrier) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if b.aead == nil {
+		return nil, ErrSealed
+	}
+
+	sealed := make([]byte, 1+b.aead.NonceSize(), 1+b.aead.NonceSize()+len(plaintext)+b.aead.Overhead())
+	sealed[0] = BARRIER_FORMAT_V1
+	if _, err := rand.Read(sealed[1:]); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(sealed, sealed[1:], plaintext, append(sealed[:1:1], additionalData...)), nil
+}
+
+// Decrypt - opens a value sealed under the root key, failing unless the additional data is the one it was sealed
+// with.
+func (b *Barrier) Decrypt(sealed, additionalData []byte) ([]byte, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if b.aead == nil {
+		return nil, ErrSealed
+	}
+	if len(sealed) < 1+b.aead.NonceSize() || sealed[0] != BARRIER_FORMAT_V1 {
+		return nil, errors.New("malformed sealed value")
+	}
+
+	nonce := sealed[1 : 1+b.aead.NonceSize()]
+	plaintext, err := b.aead.Open(nil, nonce, sealed[1+len(nonce):], append(sealed[:1:1], additionalData...))
+	if err != nil {
+		return nil, errors.New("sealed value failed authentication")
+	}
+	return plaintext, nil
+}
+
+// initialize - generates a root key, unseals the barrier with it and splits it into shares.
+func (b *Barrier) initialize(shares, threshold int) (SealConfiguration, [][]byte, error) {
+	rootKey := make([]byte, ROOT_KEY_SIZE)
+	defer clear(rootKey)
+	if _, err := rand.Read(rootKey); err != nil {
+		return SealConfiguration{}, nil, err
+	}
+
+	parts, err := shamir.Split(rootKey, shares, threshold)
+	if err != nil {
+		return SealConfiguration{}, nil, err
+	}
+
+	configuration := SealConfiguration{Type: SEAL_TYPE_SHAMIR, Shares: shares, Threshold: threshold}
+	if err := b.initializeWith(rootKey, &configuration); err != nil {
+		return SealConfiguration{}, nil, err
+	}
+	return configuration, parts, nil
+}
+
+// initializeTransit - generates a root key, unseals the barrier with it and wraps it with the key wrapper.
+func (b *Barrier) initializeTransit(ctx context.Context) (SealConfiguration, error) {
+	rootKey := make([]byte, ROOT_KEY_SIZE)
+	defer clear(rootKey)
+	if _, err := rand.Read(rootKey); err != nil {
+		return SealConfiguration{}, err
+	}
+
+	wrapped, err := b.wrapper.WrapKey(ctx, rootKey)
+	if err != nil {
+		return SealConfiguration{}, err
+	}
+
+	configuration := SealConfiguration{Type: SEAL_TYPE_TRANSIT, WrappedRootKey: wrapped}
+	if err := b.initializeWith(rootKey, &configuration); err != nil {
+		return SealConfiguration{}, err
+	}
+	return configuration, nil
+}
+
+// initializeWith - unseals the barrier with a new root key and adds the verification value to the configuration.
+func (b *Barrier) initializeWith(rootKey []byte, configuration *SealConfiguration) error {
+	if err := b.unsealWith(rootKey); err != nil {
+		return err
+	}
+
+	verification, err := b.Encrypt([]byte(SEAL_VERIFICATION), nil)
+	if err != nil {
+		b.seal()
+		return err
+	}
+	configuration.Verification = verification
+	return nil
+}
+
+// unseal - adds a share to the ones submitted so far, unsealing the barrier once the threshold is reached. On any
+// error, all the shares submitted so far are discarded.
+func (b *Barrier) unseal(configuration SealConfiguration, share []byte) (bool, error) {
+	b.lock.Lock()
+	shares := append(b.pending, bytes.Clone(share))
+	b.pending = nil
+	for _, pending := range shares[:len(shares)-1] {
+		if bytes.Equal(pending, share) {
+			b.lock.Unlock()
+			wipe(shares)
+			return false, fmt.Errorf("%w: share already submitted", shamir.ErrInvalidShares)
+		}
+	}
+	if len(shares) < configuration.Threshold {
+		b.pending = shares
+		b.lock.Unlock()
+		return false, nil
+	}
+	b.lock.Unlock()
+	defer wipe(shares)
+
+	rootKey, err := shamir.Combine(shares)
+	if err != nil {
+		return false, err
+	}
+	defer clear(rootKey)
+
+	if err := b.unsealVerified(rootKey, configuration); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// unsealTransit - unwraps the root key with the key wrapper and unseals the barrier with it.
+func (b *Barrier) unsealTransit(ctx context.Context, configuration SealConfiguration) error {
+	rootKey, err := b.wrapper.UnwrapKey(ctx, configuration.WrappedRootKey)
+	if err != nil {
+		return err
+	}
+	defer clear(rootKey)
+
+	return b.unsealVerified(rootKey, configuration)
+}
+
+// unsealVerified - unseals the barrier with the root key, unless it fails to open the verification value.
+func (b *Barrier) unsealVerified(rootKey []byte, configuration SealConfiguration) error {
+	if len(rootKey) != ROOT_KEY_SIZE {
+		return ErrUnsealFailed
+	}
+
+	candidate, err := newAES256GCM(rootKey, 12)
+	if err != nil {
+		return err
+	}
+	verification := configuration.Verification
+	if len(verification) < 1+candidate.NonceSize() {
+		return ErrUnsealFailed
+	}
+	nonce := verification[1 : 1+candidate.NonceSize()]
+	if _, err := candidate.Open(nil, nonce, verification[1+len(nonce):], verification[:1]); err != nil {
+		return ErrUnsealFailed
+	}
+
+	return b.unsealWith(rootKey)
+}
+
+// progress - number of unseal shares submitted so far.
+func (b *Barrier) progress() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return len(b.pending)
+}
+
+// unsealWith - unseals the barrier with the root key and wakes up the goroutines waiting for it.
+func (b *Barrier) unsealWith(rootKey []byte) error {
+	aead, err := newAES256GCM(rootKey, 12)
+	if err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.aead == nil {
+		b.aead = aead
+		close(b.unsealed)
+	}
+	return nil
+}
+
+// seal - forgets the root key and the submitted unseal shares.
+func (b *Barrier) seal() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	wipe(b.pending)
+	b.pending = nil
+	if b.aead != nil {
+		b.aead = nil
+		b.unsealed = make(chan struct{})
+	}
+}
+
+// wipe - overwrites unseal shares with zeros.
+func wipe(shares [][]byte) {
+	for _, share := range shares {
+		clear(share)
+	}
+}
@@ -0,0 +1,54 @@
+package kms
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CALLER_IDENTITY_ANONYMOUS = "anonymous"
+)
+
+type callerIdentityKey struct{}
+
+// WithCallerIdentity - returns a copy of the context carrying the identity of the caller.
+func WithCallerIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, callerIdentityKey{}, identity)
+}
+
+// CallerIdentity - returns the identity of the caller carried by the context.
+func CallerIdentity(ctx context.Context) string {
+	if identity, ok := ctx.Value(callerIdentityKey{}).(string); ok && identity != "" {
+		return identity
+	}
+	return CALLER_IDENTITY_ANONYMOUS
+}
+
+var (
+	// requestIDPattern - request IDs accepted from clients, anything else is replaced by a generated one.
+	requestIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+)
+
+type requestIDKey struct{}
+
+// ClientRequestID - returns the request ID sent by an API client, or a generated one when it is missing or malformed,
+// so IDs written to the audit log cannot be forged into something else.
+func ClientRequestID(requestID string) string {
+	if !requestIDPattern.MatchString(requestID) {
+		return uuid.NewString()
+	}
+	return requestID
+}
+
+// WithRequestID - returns a copy of the context carrying the ID of the API request being served.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID - returns the ID of the API request carried by the context, if any.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
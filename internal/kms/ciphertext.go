@@ -0,0 +1,109 @@
+package kms
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Ciphertext envelope produced by the KMS. All integers are big endian.
+//
+//	+---------+-----------+--------+-------------+--------------+-------+--------------------+
+//	| version | algorithm | key ID | key version | nonce length | nonce | ciphertext and tag |
+//	| 1 byte  | 1 byte    | 16     | 4 bytes     | 1 byte       | n     | remaining bytes    |
+//	+---------+-----------+--------+-------------+--------------+-------+--------------------+
+//
+// The whole header is authenticated as additional data together with the caller's AAD, so it
+// cannot be altered without decryption failing.
+const (
+	CIPHERTEXT_FORMAT_V1 = 0x01
+
+	ALGORITHM_AES_256_GCM = "AES_256_GCM"
+
+	ciphertextFixedHeaderLength = 1 + 1 + 16 + 4 + 1
+)
+
+var (
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+	// algorithmIdentifiers - maps algorithm names to their identifier inside the envelope.
+	algorithmIdentifiers = map[string]byte{
+		ALGORITHM_AES_256_GCM: 0x01,
+	}
+)
+
+type CiphertextHeader struct {
+	FormatVersion byte
+	Algorithm     string
+	KeyID         string
+	KeyVersion    int
+	Nonce         []byte
+}
+
+// Marshal - encodes the header into its binary representation.
+func (h CiphertextHeader) Marshal() ([]byte, error) {
+	algorithm, ok := algorithmIdentifiers[h.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown algorithm %s", ErrInvalidCiphertext, h.Algorithm)
+	}
+
+	keyID, err := uuid.Parse(h.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed key ID", ErrInvalidCiphertext)
+	}
+
+	if len(h.Nonce) > 255 {
+		return nil, fmt.Errorf("%w: nonce too long", ErrInvalidCiphertext)
+	}
+
+	header := make([]byte, 0, ciphertextFixedHeaderLength+len(h.Nonce))
+	header = append(header, h.FormatVersion, algorithm)
+	header = append(header, keyID[:]...)
+	header = binary.BigEndian.AppendUint32(header, uint32(h.KeyVersion))
+	header = append(header, byte(len(h.Nonce)))
+	header = append(header, h.Nonce...)
+
+	return header, nil
+}
+
+// ParseCiphertext - splits a ciphertext envelope into its header, raw header bytes and payload.
+func ParseCiphertext(ciphertext []byte) (CiphertextHeader, []byte, []byte, error) {
+	if len(ciphertext) < ciphertextFixedHeaderLength {
+		return CiphertextHeader{}, nil, nil, fmt.Errorf("%w: too short", ErrInvalidCiphertext)
+	}
+
+	if ciphertext[0] != CIPHERTEXT_FORMAT_V1 {
+		return CiphertextHeader{}, nil, nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidCiphertext, ciphertext[0])
+	}
+
+	algorithm := ""
+	for name, identifier := range algorithmIdentifiers {
+		if identifier == ciphertext[1] {
+			algorithm = name
+		}
+	}
+	if algorithm == "" {
+		return CiphertextHeader{}, nil, nil, fmt.Errorf("%w: unknown algorithm %d", ErrInvalidCiphertext, ciphertext[1])
+	}
+
+	keyID, _ := uuid.FromBytes(ciphertext[2:18])
+	keyVersion := binary.BigEndian.Uint32(ciphertext[18:22])
+	nonceLength := int(ciphertext[22])
+
+	headerLength := ciphertextFixedHeaderLength + nonceLength
+	if len(ciphertext) < headerLength {
+		return CiphertextHeader{}, nil, nil, fmt.Errorf("%w: truncated nonce", ErrInvalidCiphertext)
+	}
+
+	header := CiphertextHeader{
+		FormatVersion: ciphertext[0],
+		Algorithm:     algorithm,
+		KeyID:         keyID.String(),
+		KeyVersion:    int(keyVersion),
+		Nonce:         ciphertext[ciphertextFixedHeaderLength:headerLength],
+	}
+
+	return header, ciphertext[:headerLength], ciphertext[headerLength:], nil
+}
@@ -0,0 +1,92 @@
+package kms
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strconv"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+const (
+	DATA_KEY_SPEC_AES_128 = "AES_128"
+	DATA_KEY_SPEC_AES_256 = "AES_256"
+)
+
+var (
+	ErrUnsupportedDataKeySpec = errors.New("unsupported data key spec")
+)
+
+// DataKey - data encryption key wrapped under a KMS key.
+type DataKey struct {
+	KeyID      string
+	KeyVersion int
+	Plaintext  []byte // nil when generated without plaintext.
+	Ciphertext []byte
+}
+
+// GenerateDataKey - generates a data key and returns it both in plaintext and wrapped under the given key.
+func (s *Service) GenerateDataKey(ctx context.Context, keyID, spec string, encryptionContext map[string]string) (DataKey, error) {
+	dataKey, err := s.generateDataKey(ctx, keyID, spec, encryptionContext)
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Data key generated", encryptionContextLabels(map[string]string{
+		"keyId":       dataKey.KeyID,
+		"keyVersion":  strconv.Itoa(dataKey.KeyVersion),
+		"dataKeySpec": spec,
+	}, encryptionContext))
+
+	return dataKey, nil
+}
+
+// GenerateDataKeyWithoutPlaintext - generates a data key and returns it only wrapped under the given key.
+func (s *Service) GenerateDataKeyWithoutPlaintext(ctx context.Context, keyID, spec string, encryptionContext map[string]string) (DataKey, error) {
+	dataKey, err := s.generateDataKey(ctx, keyID, spec, encryptionContext)
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	clear(dataKey.Plaintext)
+	dataKey.Plaintext = nil
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Data key generated without plaintext", encryptionContextLabels(map[string]string{
+		"keyId":       dataKey.KeyID,
+		"keyVersion":  strconv.Itoa(dataKey.KeyVersion),
+		"dataKeySpec": spec,
+	}, encryptionContext))
+
+	return dataKey, nil
+}
+
+// generateDataKey - generates random data key material and wraps it under the given key.
+func (s *Service) generateDataKey(ctx context.Context, keyID, spec string, encryptionContext map[string]string) (DataKey, error) {
+	var plaintext []byte
+
+	switch spec {
+	default:
+		return DataKey{}, ErrUnsupportedDataKeySpec
+	case DATA_KEY_SPEC_AES_128:
+		plaintext = make([]byte, 16)
+	case DATA_KEY_SPEC_AES_256:
+		plaintext = make([]byte, 32)
+	}
+
+	if _, err := rand.Read(plaintext); err != nil {
+		return DataKey{}, err
+	}
+
+	ciphertext, key, version, err := s.encrypt(ctx, keyID, plaintext, encryptionContext)
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	return DataKey{
+		KeyID:      key.ID,
+		KeyVersion: version.Version,
+		Plaintext:  plaintext,
+		Ciphertext: ciphertext,
+	}, nil
+}
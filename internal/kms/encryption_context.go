@@ -0,0 +1,40 @@
+package kms
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+const (
+	ENCRYPTION_CONTEXT_LABEL_PREFIX = "encryptionContext."
+)
+
+// encodeEncryptionContext - encodes an encryption context into the additional authenticated data bound to a ciphertext.
+//
+// Pairs are sorted by key and every key and value is prefixed with its big endian uint32 length, so two different
+// contexts never share an encoding. An empty context encodes to no data at all.
+func encodeEncryptionContext(encryptionContext map[string]string) []byte {
+	keys := make([]string, 0, len(encryptionContext))
+	for key := range encryptionContext {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	aad := []byte{}
+	for _, key := range keys {
+		aad = binary.BigEndian.AppendUint32(aad, uint32(len(key)))
+		aad = append(aad, key...)
+		aad = binary.BigEndian.AppendUint32(aad, uint32(len(encryptionContext[key])))
+		aad = append(aad, encryptionContext[key]...)
+	}
+
+	return aad
+}
+
+// encryptionContextLabels - copies the encryption context into audit event labels.
+func encryptionContextLabels(labels map[string]string, encryptionContext map[string]string) map[string]string {
+	for key, value := range encryptionContext {
+		labels[ENCRYPTION_CONTEXT_LABEL_PREFIX+key] = value
+	}
+	return labels
+}
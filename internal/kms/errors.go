@@ -0,0 +1,64 @@
+package kms
+
+import (
+	"errors"
+
+	"github.com/hyperplane-sh/openkms/internal/shamir"
+)
+
+const (
+	ERROR_CODE_NOT_FOUND           = "NOT_FOUND"
+	ERROR_CODE_ALREADY_EXISTS      = "ALREADY_EXISTS"
+	ERROR_CODE_INVALID_ARGUMENT    = "INVALID_ARGUMENT"
+	ERROR_CODE_FAILED_PRECONDITION = "FAILED_PRECONDITION"
+	ERROR_CODE_ABORTED             = "ABORTED" // the request conflicted with a concurrent one and can be retried.
+	ERROR_CODE_INTERNAL            = "INTERNAL"
+)
+
+var (
+	// errorCodes - stable code of every error caused by the caller.
+	errorCodes = map[error]string{
+		ErrKeyNotFound:                    ERROR_CODE_NOT_FOUND,
+		ErrKeyVersionNotFound:             ERROR_CODE_NOT_FOUND,
+		ErrAliasNotFound:                  ERROR_CODE_NOT_FOUND,
+		ErrAliasExists:                    ERROR_CODE_ALREADY_EXISTS,
+		ErrUnsupportedKeySpec:             ERROR_CODE_INVALID_ARGUMENT,
+		ErrUnsupportedKeyUsage:            ERROR_CODE_INVALID_ARGUMENT,
+		ErrInvalidRotation:                ERROR_CODE_INVALID_ARGUMENT,
+		ErrInvalidPendingWindow:           ERROR_CODE_INVALID_ARGUMENT,
+		ErrInvalidAliasName:               ERROR_CODE_INVALID_ARGUMENT,
+		ErrInvalidCiphertext:              ERROR_CODE_INVALID_ARGUMENT,
+		ErrIncompatibleKey:                ERROR_CODE_INVALID_ARGUMENT,
+		ErrUnsupportedDataKeySpec:         ERROR_CODE_INVALID_ARGUMENT,
+		ErrUnsupportedSigningAlgorithm:    ERROR_CODE_INVALID_ARGUMENT,
+		ErrUnsupportedEncryptionAlgorithm: ERROR_CODE_INVALID_ARGUMENT,
+		ErrUnsupportedMacAlgorithm:        ERROR_CODE_INVALID_ARGUMENT,
+		ErrInvalidDigest:                  ERROR_CODE_INVALID_ARGUMENT,
+		ErrSealed:                         ERROR_CODE_FAILED_PRECONDITION,
+		ErrNotInitialized:                 ERROR_CODE_FAILED_PRECONDITION,
+		ErrAlreadyInitialized:             ERROR_CODE_FAILED_PRECONDITION,
+		ErrSealUnsupported:                ERROR_CODE_FAILED_PRECONDITION,
+		ErrSealMismatch:                   ERROR_CODE_FAILED_PRECONDITION,
+		ErrKeyProviderNotFound:            ERROR_CODE_FAILED_PRECONDITION,
+		ErrStorageConflict:                ERROR_CODE_ABORTED,
+		ErrUnsealFailed:                   ERROR_CODE_INVALID_ARGUMENT,
+		shamir.ErrInvalidParameters:       ERROR_CODE_INVALID_ARGUMENT,
+		shamir.ErrInvalidShares:           ERROR_CODE_INVALID_ARGUMENT,
+	}
+)
+
+// ErrorCode - classifies an error returned by the KMS into a stable code that API layers can expose.
+func ErrorCode(err error) string {
+	var stateError *KeyStateError
+	if errors.As(err, &stateError) {
+		return ERROR_CODE_FAILED_PRECONDITION
+	}
+
+	for target, code := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+
+	return ERROR_CODE_INTERNAL
+}
@@ -0,0 +1,142 @@
+package kms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+const (
+	MAC_ALGORITHM_HMAC_SHA_256 = "HMAC_SHA_256"
+	MAC_ALGORITHM_HMAC_SHA_384 = "HMAC_SHA_384"
+	MAC_ALGORITHM_HMAC_SHA_512 = "HMAC_SHA_512"
+)
+
+var (
+	ErrUnsupportedMacAlgorithm = errors.New("unsupported MAC algorithm for key")
+
+	// macAlgorithms - MAC algorithm supported by every HMAC key spec.
+	macAlgorithms = map[string]string{
+		KEY_SPEC_HMAC_256: MAC_ALGORITHM_HMAC_SHA_256,
+		KEY_SPEC_HMAC_384: MAC_ALGORITHM_HMAC_SHA_384,
+		KEY_SPEC_HMAC_512: MAC_ALGORITHM_HMAC_SHA_512,
+	}
+
+	// macHashes - hash function backing every MAC algorithm.
+	macHashes = map[string]func() hash.Hash{
+		MAC_ALGORITHM_HMAC_SHA_256: sha256.New,
+		MAC_ALGORITHM_HMAC_SHA_384: sha512.New384,
+		MAC_ALGORITHM_HMAC_SHA_512: sha512.New,
+	}
+)
+
+// Mac - message authentication code produced by a version of an HMAC key.
+type Mac struct {
+	KeyID      string
+	KeyVersion int
+	Algorithm  string
+	Mac        []byte
+}
+
+// GenerateMac - computes the MAC of the message with the primary version of the given key.
+func (s *Service) GenerateMac(ctx context.Context, keyID string, message []byte, algorithm string) (Mac, error) {
+	key, err := s.macKey(ctx, keyID, algorithm)
+	if err != nil {
+		return Mac{}, err
+	}
+
+	version, err := key.Primary()
+	if err != nil {
+		return Mac{}, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return Mac{}, err
+	}
+
+	mac, err := provider.Mac(ctx, algorithm, version, message)
+	if err != nil {
+		return Mac{}, err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "MAC generated", map[string]string{
+		"keyId":        key.ID,
+		"keyVersion":   strconv.Itoa(version.Version),
+		"macAlgorithm": algorithm,
+	})
+
+	return Mac{
+		KeyID:      key.ID,
+		KeyVersion: version.Version,
+		Algorithm:  algorithm,
+		Mac:        mac,
+	}, nil
+}
+
+// VerifyMac - reports, in constant time, whether the MAC of the message was produced by any version of the given key.
+func (s *Service) VerifyMac(ctx context.Context, keyID string, message, mac []byte, algorithm string) (bool, error) {
+	key, err := s.macKey(ctx, keyID, algorithm)
+	if err != nil {
+		return false, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return false, err
+	}
+
+	valid := false
+	verifiedVersion := 0
+	for _, version := range key.Versions {
+		computed, err := provider.Mac(ctx, algorithm, version, message)
+		if err != nil {
+			return false, err
+		}
+		if hmac.Equal(computed, mac) {
+			valid = true
+			verifiedVersion = version.Version
+		}
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "MAC verified", map[string]string{
+		"keyId":        key.ID,
+		"keyVersion":   strconv.Itoa(verifiedVersion),
+		"macAlgorithm": algorithm,
+		"valid":        strconv.FormatBool(valid),
+	})
+
+	return valid, nil
+}
+
+// macKey - retrieves a key and makes sure it can compute MACs with the given algorithm.
+func (s *Service) macKey(ctx context.Context, keyID, algorithm string) (Key, error) {
+	key, err := s.enabledKey(ctx, keyID, "MAC computation")
+	if err != nil {
+		return Key{}, err
+	}
+
+	if key.Usage != KEY_USAGE_GENERATE_VERIFY_MAC {
+		return Key{}, fmt.Errorf("%w: %s is not an HMAC key", ErrIncompatibleKey, key.ID)
+	}
+
+	if macAlgorithms[key.Spec] != algorithm {
+		return Key{}, fmt.Errorf("%w: %s cannot compute %s", ErrUnsupportedMacAlgorithm, key.Spec, algorithm)
+	}
+
+	return key, nil
+}
+
+// computeMac - computes the HMAC of the message with the given key material.
+func computeMac(algorithm string, material, message []byte) []byte {
+	mac := hmac.New(macHashes[algorithm], material)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
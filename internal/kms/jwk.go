@@ -0,0 +1,57 @@
+package kms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// jsonWebKey - public JSON Web Key as described by RFC 7517 and RFC 8037.
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// marshalJWK - encodes a public key as a JSON Web Key.
+func marshalJWK(keyID, usage string, publicKey crypto.PublicKey) ([]byte, error) {
+	jwk := jsonWebKey{KeyID: keyID, Use: "sig"}
+	if usage == KEY_USAGE_ENCRYPT_DECRYPT {
+		jwk.Use = "enc"
+	}
+
+	switch publicKey := publicKey.(type) {
+	default:
+		return nil, fmt.Errorf("%w: cannot encode %T as JWK", ErrIncompatibleKey, publicKey)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		if usage == KEY_USAGE_ENCRYPT_DECRYPT {
+			jwk.Algorithm = "RSA-OAEP-256"
+		}
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = publicKey.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	}
+
+	return json.Marshal(jwk)
+}
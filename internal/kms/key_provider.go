@@ -0,0 +1,177 @@
+package kms
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	KEY_PROVIDER_SOFTWARE = "software"
+	KEY_PROVIDER_PKCS11   = "pkcs11"
+)
+
+var (
+	ErrKeyProviderNotFound = errors.New("key provider not found")
+)
+
+// KeyProvider - generates key versions and performs every cryptographic operation needing their secret material, so
+// the material may live outside of the key store, e.g. inside an HSM.
+type KeyProvider interface {
+	// GenerateKeyVersion - generates the material of a new version of a key with the given spec.
+	GenerateKeyVersion(ctx context.Context, keyID, spec string, version int) (KeyVersion, error)
+	// DestroyKeyVersion - irreversibly erases the material of a key version, succeeding when it is already gone.
+	DestroyKeyVersion(ctx context.Context, version KeyVersion) error
+	// EncryptAES256GCM - encrypts the plaintext with a symmetric key version, returning the ciphertext and its tag.
+	EncryptAES256GCM(ctx context.Context, version KeyVersion, nonce, plaintext, additionalData []byte) ([]byte, error)
+	// DecryptAES256GCM - decrypts a ciphertext and its tag, failing with ErrInvalidCiphertext when it is not authentic.
+	DecryptAES256GCM(ctx context.Context, version KeyVersion, nonce, ciphertext, additionalData []byte) ([]byte, error)
+	// Signer - returns the signer of an asymmetric key version.
+	Signer(ctx context.Context, spec string, version KeyVersion) (crypto.Signer, error)
+	// Decrypter - returns the RSA OAEP decrypter of an asymmetric key version.
+	Decrypter(ctx context.Context, spec string, version KeyVersion) (crypto.Decrypter, error)
+	// Mac - computes the MAC of the message with an HMAC key version.
+	Mac(ctx context.Context, algorithm string, version KeyVersion, message []byte) ([]byte, error)
+}
+
+// KeyProviders - key providers a key store creates and uses keys with, by name. The software provider, keeping the
+// material in the key store itself, is always available.
+type KeyProviders struct {
+	Default   string // provider of keys created without one, the software provider when empty.
+	Providers map[string]KeyProvider
+}
+
+// resolve - returns the name of the provider of a new key, defaulting it when empty.
+func (kP *KeyProviders) resolve(name string) (string, error) {
+	if name == "" && kP != nil {
+		name = kP.Default
+	}
+	if name == "" {
+		name = KEY_PROVIDER_SOFTWARE
+	}
+
+	if _, err := kP.provider(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// provider - returns a provider by name, keys created before providers existed belong to the software provider.
+func (kP *KeyProviders) provider(name string) (KeyProvider, error) {
+	if name == "" || name == KEY_PROVIDER_SOFTWARE {
+		return softwareKeyProvider{}, nil
+	}
+
+	if kP != nil {
+		if provider, ok := kP.Providers[name]; ok {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyProviderNotFound, name)
+}
+
+// Close - closes every provider holding resources, such as the session of a PKCS #11 token.
+func (kP *KeyProviders) Close() error {
+	if kP == nil {
+		return nil
+	}
+
+	var errs []error
+	for name, provider := range kP.Providers {
+		if closer, ok := provider.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("key provider %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// softwareKeyProvider - key provider keeping the material in the key versions, protected by the key store's barrier.
+type softwareKeyProvider struct{}
+
+// GenerateKeyVersion - generates fresh key material for the given key spec.
+func (softwareKeyProvider) GenerateKeyVersion(ctx context.Context, keyID, spec string, version int) (KeyVersion, error) {
+	var material []byte
+	var err error
+
+	switch spec {
+	default:
+		return KeyVersion{}, fmt.Errorf("%w: %s", ErrUnsupportedKeySpec, spec)
+	case KEY_SPEC_SYMMETRIC_DEFAULT, KEY_SPEC_HMAC_256:
+		material = make([]byte, 32)
+		if _, err := rand.Read(material); err != nil {
+			return KeyVersion{}, err
+		}
+	case KEY_SPEC_HMAC_384:
+		material = make([]byte, 48)
+		if _, err := rand.Read(material); err != nil {
+			return KeyVersion{}, err
+		}
+	case KEY_SPEC_HMAC_512:
+		material = make([]byte, 64)
+		if _, err := rand.Read(material); err != nil {
+			return KeyVersion{}, err
+		}
+	case KEY_SPEC_ECC_NIST_P256, KEY_SPEC_ECC_NIST_P384, KEY_SPEC_ED25519, KEY_SPEC_RSA_2048, KEY_SPEC_RSA_3072, KEY_SPEC_RSA_4096:
+		material, err = generatePrivateKey(spec)
+		if err != nil {
+			return KeyVersion{}, err
+		}
+	}
+
+	return KeyVersion{
+		Version:   version,
+		Material:  material,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// DestroyKeyVersion - overwrites the material in memory, the key store drops it when writing the key back.
+func (softwareKeyProvider) DestroyKeyVersion(ctx context.Context, version KeyVersion) error {
+	clear(version.Material)
+	return nil
+}
+
+// EncryptAES256GCM - encrypts the plaintext with the material of the key version.
+func (softwareKeyProvider) EncryptAES256GCM(ctx context.Context, version KeyVersion, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAES256GCM(version.Material, len(nonce))
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// DecryptAES256GCM - decrypts the ciphertext with the material of the key version.
+func (softwareKeyProvider) DecryptAES256GCM(ctx context.Context, version KeyVersion, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAES256GCM(version.Material, len(nonce))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: authentication failed", ErrInvalidCiphertext)
+	}
+	return plaintext, nil
+}
+
+// Signer - parses the private key of the key version.
+func (softwareKeyProvider) Signer(ctx context.Context, spec string, version KeyVersion) (crypto.Signer, error) {
+	return parsePrivateKey(version.Material)
+}
+
+// Decrypter - parses the RSA private key of the key version.
+func (softwareKeyProvider) Decrypter(ctx context.Context, spec string, version KeyVersion) (crypto.Decrypter, error) {
+	return parseRSAPrivateKey(version.Material)
+}
+
+// Mac - computes the HMAC of the message with the material of the key version.
+func (softwareKeyProvider) Mac(ctx context.Context, algorithm string, version KeyVersion, message []byte) ([]byte, error) {
+	return computeMac(algorithm, version.Material, message), nil
+}
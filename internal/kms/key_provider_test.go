@@ -0,0 +1,375 @@
+package kms_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+// tokenProvider - key provider keeping the material of every key version in memory, as an HSM token would.
+type tokenProvider struct {
+	lock         sync.Mutex
+	objects      map[string]any // by handle.
+	closed       bool
+	destroyError error // returned instead of destroying objects when set.
+}
+
+func (tP *tokenProvider) GenerateKeyVersion(ctx context.Context, keyID, spec string, version int) (kms.KeyVersion, error) {
+	var object any
+	var err error
+	switch spec {
+	default:
+		return kms.KeyVersion{}, kms.ErrUnsupportedKeySpec
+	case kms.KEY_SPEC_SYMMETRIC_DEFAULT, kms.KEY_SPEC_HMAC_256:
+		secret := make([]byte, 32)
+		_, err = rand.Read(secret)
+		object = secret
+	case kms.KEY_SPEC_ECC_NIST_P256:
+		object, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case kms.KEY_SPEC_RSA_2048:
+		object, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return kms.KeyVersion{}, err
+	}
+
+	handle := []byte(keyID + "/" + strconv.Itoa(version))
+	tP.lock.Lock()
+	defer tP.lock.Unlock()
+	tP.objects[string(handle)] = object
+	return kms.KeyVersion{Version: version, Handle: handle, CreatedAt: time.Now().UTC()}, nil
+}
+
+func (tP *tokenProvider) DestroyKeyVersion(ctx context.Context, version kms.KeyVersion) error {
+	tP.lock.Lock()
+	defer tP.lock.Unlock()
+	if tP.destroyError != nil {
+		return tP.destroyError
+	}
+	delete(tP.objects, string(version.Handle))
+	return nil
+}
+
+func (tP *tokenProvider) EncryptAES256GCM(ctx context.Context, version kms.KeyVersion, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := tP.aead(version)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+func (tP *tokenProvider) DecryptAES256GCM(ctx context.Context, version kms.KeyVersion, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := tP.aead(version)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, kms.ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+func (tP *tokenProvider) Signer(ctx context.Context, spec string, version kms.KeyVersion) (crypto.Signer, error) {
+	signer, ok := tP.object(version).(crypto.Signer)
+	if !ok {
+		return nil, kms.ErrIncompatibleKey
+	}
+	return signer, nil
+}
+
+func (tP *tokenProvider) Decrypter(ctx context.Context, spec string, version kms.KeyVersion) (crypto.Decrypter, error) {
+	decrypter, ok := tP.object(version).(*rsa.PrivateKey)
+	if !ok {
+		return nil, kms.ErrIncompatibleKey
+	}
+	return decrypter, nil
+}
+
+func (tP *tokenProvider) Mac(ctx context.Context, algorithm string, version kms.KeyVersion, message []byte) ([]byte, error) {
+	secret, ok := tP.object(version).([]byte)
+	if !ok {
+		return nil, kms.ErrIncompatibleKey
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+func (tP *tokenProvider) Close() error {
+	tP.closed = true
+	return nil
+}
+
+func (tP *tokenProvider) aead(version kms.KeyVersion) (cipher.AEAD, error) {
+	secret, ok := tP.object(version).([]byte)
+	if !ok {
+		return nil, kms.ErrIncompatibleKey
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (tP *tokenProvider) object(version kms.KeyVersion) any {
+	tP.lock.Lock()
+	defer tP.lock.Unlock()
+	return tP.objects[string(version.Handle)]
+}
+
+// expireDeletion - moves the deletion date of a key stored in the directory to the past, so it can be destroyed.
+func expireDeletion(t *testing.T, directory string, key kms.Key) {
+	t.Helper()
+
+	path := filepath.Join(directory, "keys", key.ID+".json")
+	content, _ := os.ReadFile(path)
+	expired := strings.Replace(string(content), key.DeletionDate.Format(time.RFC3339Nano), "2000-01-01T00:00:00Z", 1)
+	if err := os.WriteFile(path, []byte(expired), 0600); err != nil {
+		t.Fatalf("Failed to expire deletion date: %v", err)
+	}
+}
+
+func TestKeyProvider_Operations(t *testing.T) {
+	ctx := context.Background()
+	auditor := &kmstest.Auditor{}
+	token := &tokenProvider{objects: map[string]any{}}
+	providers := &kms.KeyProviders{Providers: map[string]kms.KeyProvider{"token": token}}
+	service := kmstest.OpenService(t, kms.NewKeyStoreStorage(kms.NewStorageBackendFile(t.TempDir()), nil, providers, auditor), auditor)
+
+	keys := map[string]kms.Key{}
+	for _, spec := range []string{kms.KEY_SPEC_SYMMETRIC_DEFAULT, kms.KEY_SPEC_HMAC_256, kms.KEY_SPEC_ECC_NIST_P256, kms.KEY_SPEC_RSA_2048} {
+		usage := ""
+		if spec == kms.KEY_SPEC_RSA_2048 {
+			usage = kms.KEY_USAGE_ENCRYPT_DECRYPT
+		}
+		key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: spec, Usage: usage, Provider: "token"})
+		if err != nil {
+			t.Fatalf("Failed to create %s key: %v", spec, err)
+		}
+		if key.Provider != "token" || key.Versions[0].Material != nil || len(key.Versions[0].Handle) == 0 {
+			t.Errorf("Expected a %s key held by the provider, got %+v", spec, key.Versions[0])
+		}
+		keys[spec] = key
+	}
+	if len(token.objects) != 4 {
+		t.Errorf("Expected 4 objects in the token, got %d", len(token.objects))
+	}
+
+	// Aliases resolve to provider keys like to software keys.
+	//
+	if _, err := service.KeyStore().CreateAlias(ctx, "alias/token", keys[kms.KEY_SPEC_SYMMETRIC_DEFAULT].ID); err != nil {
+		t.Fatalf("Failed to create alias: %v", err)
+	}
+	ciphertext, err := service.Encrypt(ctx, "alias/token", []byte("secret"), map[string]string{"tenant": "acme"})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if _, err := service.KeyStore().RotateKey(ctx, "alias/token"); err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	if plaintext, err := service.Decrypt(ctx, ciphertext, map[string]string{"tenant": "acme"}); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Expected the plaintext after rotation, got %q %v", plaintext, err)
+	}
+	if _, err := service.Decrypt(ctx, ciphertext, nil); !errors.Is(err, kms.ErrInvalidCiphertext) {
+		t.Errorf("Expected %v without the encryption context, got %v", kms.ErrInvalidCiphertext, err)
+	}
+
+	mac, err := service.GenerateMac(ctx, keys[kms.KEY_SPEC_HMAC_256].ID, []byte("message"), kms.MAC_ALGORITHM_HMAC_SHA_256)
+	if err != nil {
+		t.Fatalf("Failed to generate MAC: %v", err)
+	}
+	if valid, err := service.VerifyMac(ctx, keys[kms.KEY_SPEC_HMAC_256].ID, []byte("message"), mac.Mac, kms.MAC_ALGORITHM_HMAC_SHA_256); err != nil || !valid {
+		t.Errorf("Expected the MAC to verify, got %t %v", valid, err)
+	}
+
+	digest := sha256.Sum256([]byte("message"))
+	signature, err := service.Sign(ctx, keys[kms.KEY_SPEC_ECC_NIST_P256].ID, digest[:], kms.SIGNING_ALGORITHM_ECDSA_SHA_256)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	if valid, err := service.Verify(ctx, keys[kms.KEY_SPEC_ECC_NIST_P256].ID, digest[:], signature.Signature, kms.SIGNING_ALGORITHM_ECDSA_SHA_256); err != nil || !valid {
+		t.Errorf("Expected the signature to verify, got %t %v", valid, err)
+	}
+	if _, err := service.GetPublicKey(ctx, keys[kms.KEY_SPEC_ECC_NIST_P256].ID); err != nil {
+		t.Errorf("Expected the public key, got %v", err)
+	}
+
+	rsaKey := keys[kms.KEY_SPEC_RSA_2048].ID
+	rsaCiphertext, err := service.AsymmetricEncrypt(ctx, rsaKey, []byte("secret"), kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if plaintext, err := service.AsymmetricDecrypt(ctx, rsaKey, rsaCiphertext, kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Expected the plaintext, got %q %v", plaintext, err)
+	}
+
+	// Operations are audited as for software keys.
+	//
+	messages := map[string]bool{}
+	for _, event := range auditor.Events() {
+		messages[event.Message] = true
+	}
+	for _, message := range []string{"Key created", "Key rotated", "Plaintext encrypted", "Ciphertext decrypted", "MAC generated", "Digest signed", "Signature verified"} {
+		if !messages[message] {
+			t.Errorf("Expected a %q audit event, got %v", message, messages)
+		}
+	}
+}
+
+func TestKeyProvider_DefaultAndDestruction(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	token := &tokenProvider{objects: map[string]any{}}
+	providers := &kms.KeyProviders{Default: "token", Providers: map[string]kms.KeyProvider{"token": token}}
+	service := kmstest.OpenService(t, kms.NewKeyStoreStorage(kms.NewStorageBackendFile(directory), nil, providers, nil), nil)
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil || key.Provider != "token" {
+		t.Fatalf("Expected the default provider to hold the key, got %+v %v", key, err)
+	}
+	software, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Provider: kms.KEY_PROVIDER_SOFTWARE})
+	if err != nil || software.Provider != kms.KEY_PROVIDER_SOFTWARE || software.Versions[0].Material == nil {
+		t.Errorf("Expected a software key, got %+v %v", software, err)
+	}
+	if _, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Provider: "missing"}); !errors.Is(err, kms.ErrKeyProviderNotFound) || kms.ErrorCode(err) != kms.ERROR_CODE_FAILED_PRECONDITION {
+		t.Errorf("Expected %v, got %v", kms.ErrKeyProviderNotFound, err)
+	}
+
+	// Keys of a provider missing after a restart cannot be used.
+	//
+	restarted := kmstest.OpenService(t, kms.NewKeyStoreFile(directory, nil), nil)
+	if _, err := restarted.Encrypt(ctx, key.ID, []byte("secret"), nil); !errors.Is(err, kms.ErrKeyProviderNotFound) {
+		t.Errorf("Expected %v, got %v", kms.ErrKeyProviderNotFound, err)
+	}
+
+	// Destroying the key destroys its objects in the provider.
+	//
+	key, _ = service.KeyStore().ScheduleKeyDeletion(ctx, key.ID, kms.MINIMUM_PENDING_WINDOW)
+	expireDeletion(t, directory, key)
+	key, err = service.KeyStore().DestroyKey(ctx, key.ID)
+	if err != nil || key.Versions[0].Handle != nil {
+		t.Errorf("Expected the key to be destroyed, got %+v %v", key, err)
+	}
+	if len(token.objects) != 0 {
+		t.Errorf("Expected the token objects to be destroyed, got %d", len(token.objects))
+	}
+}
+
+func TestKeyProvider_OrphanedVersions(t *testing.T) {
+	ctx := context.Background()
+	token := &tokenProvider{objects: map[string]any{}}
+	providers := &kms.KeyProviders{Default: "token", Providers: map[string]kms.KeyProvider{"token": token}}
+	backend := &conflictingBackend{StorageBackend: kms.NewStorageBackendFile(t.TempDir())}
+	keyStore := kms.NewKeyStoreStorage(backend, nil, providers, nil)
+	if err := keyStore.Open(); err != nil {
+		t.Fatalf("Failed to open key store: %v", err)
+	}
+	defer keyStore.Close()
+
+	// The versions generated by transactions that failed are destroyed, only the stored ones are left in the token.
+	//
+	backend.conflicts = kms.STORAGE_CONFLICT_ATTEMPTS
+	if _, err := keyStore.CreateKey(ctx, kms.CreateKeyOptions{}); !errors.Is(err, kms.ErrStorageConflict) {
+		t.Fatalf("Expected %v, got %v", kms.ErrStorageConflict, err)
+	}
+	if len(token.objects) != 0 {
+		t.Errorf("Expected no object in the token, got %d", len(token.objects))
+	}
+
+	key, err := keyStore.CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	backend.conflicts = kms.STORAGE_CONFLICT_ATTEMPTS - 1
+	if _, err := keyStore.RotateKey(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	backend.conflicts = kms.STORAGE_CONFLICT_ATTEMPTS
+	if _, err := keyStore.RotateKey(ctx, key.ID); !errors.Is(err, kms.ErrStorageConflict) {
+		t.Fatalf("Expected %v, got %v", kms.ErrStorageConflict, err)
+	}
+	if len(token.objects) != 2 {
+		t.Errorf("Expected 2 objects in the token, got %d", len(token.objects))
+	}
+
+	if err := providers.Close(); err != nil || !token.closed {
+		t.Errorf("Expected the token to be closed, got %v %v", token.closed, err)
+	}
+}
+
+func TestKeyProvider_DestructionFailures(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	auditor := &kmstest.Auditor{}
+	token := &tokenProvider{objects: map[string]any{}}
+	providers := &kms.KeyProviders{Default: "token", Providers: map[string]kms.KeyProvider{"token": token}}
+	backend := &conflictingBackend{StorageBackend: kms.NewStorageBackendFile(directory)}
+	keyStore := kms.NewKeyStoreStorage(backend, nil, providers, auditor)
+	if err := keyStore.Open(); err != nil {
+		t.Fatalf("Failed to open key store: %v", err)
+	}
+	defer keyStore.Close()
+
+	key, err := keyStore.CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	key, _ = keyStore.ScheduleKeyDeletion(ctx, key.ID, kms.MINIMUM_PENDING_WINDOW)
+	expireDeletion(t, directory, key)
+
+	// Objects are only destroyed once the destroyed state is stored, a failed transaction leaves them in the token.
+	//
+	backend.conflicts = kms.STORAGE_CONFLICT_ATTEMPTS
+	if _, err := keyStore.DestroyKey(ctx, key.ID); !errors.Is(err, kms.ErrStorageConflict) {
+		t.Fatalf("Expected %v, got %v", kms.ErrStorageConflict, err)
+	}
+	if stored, err := keyStore.GetKey(ctx, key.ID); err != nil || stored.State != kms.KEY_STATE_PENDING_DELETION || len(token.objects) != 1 {
+		t.Errorf("Expected the key to be left pending deletion with its object, got %+v %d %v", stored, len(token.objects), err)
+	}
+
+	// A provider failing to destroy the objects leaves the key destroyed, and every failure is audited.
+	//
+	token.destroyError = errors.New("token unreachable")
+	backend.conflicts = kms.STORAGE_CONFLICT_ATTEMPTS
+	if _, err := keyStore.CreateKey(ctx, kms.CreateKeyOptions{}); !errors.Is(err, kms.ErrStorageConflict) {
+		t.Fatalf("Expected %v, got %v", kms.ErrStorageConflict, err)
+	}
+	destroyed, err := keyStore.DestroyKey(ctx, key.ID)
+	if err != nil || destroyed.State != kms.KEY_STATE_DESTROYED || destroyed.Versions[0].Handle != nil {
+		t.Errorf("Expected the key to be destroyed, got %+v %v", destroyed, err)
+	}
+	if stored, err := keyStore.GetKey(ctx, key.ID); err != nil || stored.State != kms.KEY_STATE_DESTROYED {
+		t.Errorf("Expected the destroyed state to be stored, got %+v %v", stored, err)
+	}
+
+	failures := 0
+	for _, event := range auditor.Events() {
+		if event.Message == "Failed to destroy key version material" && event.Level == audit.LEVEL_ERROR && event.Labels["error"] == "token unreachable" {
+			failures++
+		}
+	}
+	if failures != 2 {
+		t.Errorf("Expected the orphaned and the destroyed versions to be audited, got %d failures", failures)
+	}
+}
@@ -0,0 +1,106 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	KEY_STATE_ENABLED          = "ENABLED"
+	KEY_STATE_DISABLED         = "DISABLED"
+	KEY_STATE_PENDING_DELETION = "PENDING_DELETION"
+	KEY_STATE_DESTROYED        = "DESTROYED"
+
+	MINIMUM_PENDING_WINDOW = 7 * 24 * time.Hour
+	MAXIMUM_PENDING_WINDOW = 30 * 24 * time.Hour
+	DEFAULT_PENDING_WINDOW = MAXIMUM_PENDING_WINDOW
+)
+
+var (
+	ErrInvalidPendingWindow = errors.New("pending deletion window must be between 7 and 30 days")
+)
+
+// KeyStateError - returned when an operation is not permitted in the current state of a key.
+type KeyStateError struct {
+	KeyID     string
+	State     string
+	Operation string
+}
+
+func (e *KeyStateError) Error() string {
+	return fmt.Sprintf("key %s is %s, %s is not permitted", e.KeyID, e.State, e.Operation)
+}
+
+// requireState - returns a KeyStateError unless the key is in one of the given states.
+func (k Key) requireState(operation string, states ...string) error {
+	for _, state := range states {
+		if k.State == state {
+			return nil
+		}
+	}
+	return &KeyStateError{KeyID: k.ID, State: k.State, Operation: operation}
+}
+
+// DeletionDue - reports whether a key pending deletion reached the end of its waiting period.
+func (k Key) DeletionDue(now time.Time) bool {
+	return k.State == KEY_STATE_PENDING_DELETION && k.DeletionDate != nil && !now.Before(*k.DeletionDate)
+}
+
+// validatePendingWindow - makes sure the pending deletion window is within the permitted range.
+func validatePendingWindow(window time.Duration) error {
+	if window < MINIMUM_PENDING_WINDOW || window > MAXIMUM_PENDING_WINDOW {
+		return ErrInvalidPendingWindow
+	}
+	return nil
+}
+
+// ScheduleKeyDeletion - schedules a key for destruction once the pending window elapsed, the default pending window
+// applying when zero.
+func (s *Service) ScheduleKeyDeletion(ctx context.Context, keyID string, pendingWindow time.Duration) (Key, error) {
+	if pendingWindow == 0 {
+		pendingWindow = DEFAULT_PENDING_WINDOW
+	}
+	return s.keyStore.ScheduleKeyDeletion(ctx, keyID, pendingWindow)
+}
+
+// DestroyDueKeys - destroys every key whose pending deletion window elapsed, carrying on past the keys that fail.
+func (s *Service) DestroyDueKeys(ctx context.Context) ([]Key, error) {
+	keys, err := s.keyStore.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	destroyed := []Key{}
+	var errs []error
+	for _, key := range keys {
+		if !key.DeletionDue(now) {
+			continue
+		}
+
+		destroyedKey, err := s.keyStore.DestroyKey(ctx, key.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("destruction of key %s failed: %w", key.ID, err))
+			continue
+		}
+		destroyed = append(destroyed, destroyedKey)
+	}
+
+	return destroyed, errors.Join(errs...)
+}
+
+// enabledKey - retrieves a key and makes sure it is enabled for cryptographic operations.
+func (s *Service) enabledKey(ctx context.Context, keyID, operation string) (Key, error) {
+	key, err := s.keyStore.GetKey(ctx, keyID)
+	if err != nil {
+		return Key{}, err
+	}
+
+	if err := key.requireState(operation, KEY_STATE_ENABLED); err != nil {
+		return Key{}, err
+	}
+
+	return key, nil
+}
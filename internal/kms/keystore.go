@@ -0,0 +1,244 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+const (
+	KMS_AUDIT_GROUP = "KMS"
+
+	STORAGE_TYPE_FILE     = "file"
+	STORAGE_TYPE_BOLT     = "bolt"
+	STORAGE_TYPE_POSTGRES = "postgres"
+
+	KEY_SPEC_SYMMETRIC_DEFAULT = "SYMMETRIC_DEFAULT"
+	KEY_SPEC_ECC_NIST_P256     = "ECC_NIST_P256"
+	KEY_SPEC_ECC_NIST_P384     = "ECC_NIST_P384"
+	KEY_SPEC_ED25519           = "ED25519"
+	KEY_SPEC_RSA_2048          = "RSA_2048"
+	KEY_SPEC_RSA_3072          = "RSA_3072"
+	KEY_SPEC_RSA_4096          = "RSA_4096"
+	KEY_SPEC_HMAC_256          = "HMAC_256"
+	KEY_SPEC_HMAC_384          = "HMAC_384"
+	KEY_SPEC_HMAC_512          = "HMAC_512"
+
+	KEY_USAGE_ENCRYPT_DECRYPT     = "ENCRYPT_DECRYPT"
+	KEY_USAGE_SIGN_VERIFY         = "SIGN_VERIFY"
+	KEY_USAGE_GENERATE_VERIFY_MAC = "GENERATE_VERIFY_MAC"
+
+	MINIMUM_ROTATION_PERIOD = 24 * time.Hour
+)
+
+var (
+	ErrKeyNotFound         = errors.New("key not found")
+	ErrUnsupportedKeySpec  = errors.New("unsupported key spec")
+	ErrUnsupportedKeyUsage = errors.New("unsupported key usage for key spec")
+	ErrInvalidRotation     = errors.New("rotation period must be zero or at least 24 hours")
+
+	// keySpecUsages - usages supported by every key spec, the first one being the default.
+	keySpecUsages = map[string][]string{
+		KEY_SPEC_SYMMETRIC_DEFAULT: {KEY_USAGE_ENCRYPT_DECRYPT},
+		KEY_SPEC_ECC_NIST_P256:     {KEY_USAGE_SIGN_VERIFY},
+		KEY_SPEC_ECC_NIST_P384:     {KEY_USAGE_SIGN_VERIFY},
+		KEY_SPEC_ED25519:           {KEY_USAGE_SIGN_VERIFY},
+		KEY_SPEC_RSA_2048:          {KEY_USAGE_SIGN_VERIFY, KEY_USAGE_ENCRYPT_DECRYPT},
+		KEY_SPEC_RSA_3072:          {KEY_USAGE_SIGN_VERIFY, KEY_USAGE_ENCRYPT_DECRYPT},
+		KEY_SPEC_RSA_4096:          {KEY_USAGE_SIGN_VERIFY, KEY_USAGE_ENCRYPT_DECRYPT},
+		KEY_SPEC_HMAC_256:          {KEY_USAGE_GENERATE_VERIFY_MAC},
+		KEY_SPEC_HMAC_384:          {KEY_USAGE_GENERATE_VERIFY_MAC},
+		KEY_SPEC_HMAC_512:          {KEY_USAGE_GENERATE_VERIFY_MAC},
+	}
+)
+
+type KeyStore interface {
+	// Open - opens the key store and prepares it for use.
+	Open() error
+	// Close - closes the key store and releases any resources.
+	Close() error
+	// CreateKey - creates a new key with freshly generated key material.
+	CreateKey(ctx context.Context, options CreateKeyOptions) (Key, error)
+	// GetKey - retrieves a key by its ID or alias.
+	GetKey(ctx context.Context, keyID string) (Key, error)
+	// ListKeys - lists all keys held by the store.
+	ListKeys(ctx context.Context) ([]Key, error)
+	// UpdateKeyMetadata - replaces the metadata of a key.
+	UpdateKeyMetadata(ctx context.Context, keyID string, metadata KeyMetadata) (Key, error)
+	// EnableKey - enables a disabled key.
+	EnableKey(ctx context.Context, keyID string) (Key, error)
+	// DisableKey - disables a key, refusing any cryptographic operation with it.
+	DisableKey(ctx context.Context, keyID string) (Key, error)
+	// ScheduleKeyDeletion - schedules a key for destruction once the pending window elapsed.
+	ScheduleKeyDeletion(ctx context.Context, keyID string, pendingWindow time.Duration) (Key, error)
+	// CancelKeyDeletion - cancels a scheduled deletion, leaving the key disabled.
+	CancelKeyDeletion(ctx context.Context, keyID string) (Key, error)
+	// DestroyKey - irreversibly erases the material of a key whose pending window elapsed.
+	DestroyKey(ctx context.Context, keyID string) (Key, error)
+	// RotateKey - adds a new key version and makes it the primary version.
+	RotateKey(ctx context.Context, keyID string) (Key, error)
+	// RotateKeys - rotates several keys atomically, either every key is rotated or none is.
+	RotateKeys(ctx context.Context, keyIDs []string) ([]Key, error)
+	// RotateKeyIfDue - rotates a key when its automatic rotation is due at the given time, checked within the
+	// rotation's own transaction, and reports whether it was rotated.
+	RotateKeyIfDue(ctx context.Context, keyID string, now time.Time) (Key, bool, error)
+	// UpdateKeyRotationPeriod - sets the automatic rotation period of a key, zero disables it.
+	UpdateKeyRotationPeriod(ctx context.Context, keyID string, period time.Duration) (Key, error)
+	// CreateAlias - creates an alias pointing to a key.
+	CreateAlias(ctx context.Context, name, keyID string) (Alias, error)
+	// UpdateAlias - atomically retargets an alias to another key.
+	UpdateAlias(ctx context.Context, name, keyID string) (Alias, error)
+	// DeleteAlias - deletes an alias, leaving its key untouched.
+	DeleteAlias(ctx context.Context, name string) error
+	// ListAliases - lists all aliases, ordered by name.
+	ListAliases(ctx context.Context) ([]Alias, error)
+	// Barrier - returns the barrier sealing the key material, nil when it is stored in plaintext.
+	Barrier() *Barrier
+	// ReadSealConfiguration - reads the seal configuration, failing with ErrNotInitialized before initialization.
+	ReadSealConfiguration(ctx context.Context) (SealConfiguration, error)
+	// InitializeSeal - persists the seal configuration and seals the key material stored before.
+	InitializeSeal(ctx context.Context, configuration SealConfiguration) error
+	// KeyProvider - returns the provider holding the material of keys created with it.
+	KeyProvider(name string) (KeyProvider, error)
+}
+
+type Key struct {
+	ID             string        `json:"id"`
+	Spec           string        `json:"spec"`
+	Usage          string        `json:"usage"`
+	Provider       string        `json:"provider,omitempty"` // defaulted to the software provider when read.
+	State          string        `json:"state"`
+	Metadata       KeyMetadata   `json:"metadata"`
+	Versions       []KeyVersion  `json:"versions"` // ordered by version number, oldest first.
+	PrimaryVersion int           `json:"primaryVersion"`
+	RotationPeriod time.Duration `json:"rotationPeriod,omitempty"`
+	NextRotationAt *time.Time    `json:"nextRotationAt,omitempty"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+	DeletionDate   *time.Time    `json:"deletionDate,omitempty"`
+}
+
+type KeyMetadata struct {
+	Description string            `json:"description"`
+	Tags        map[string]string `json:"tags"`
+}
+
+type KeyVersion struct {
+	Version   int       `json:"version"`
+	Material  []byte    `json:"material"`         // nil when the provider keeps the material, or once destroyed.
+	Handle    []byte    `json:"handle,omitempty"` // reference to the material kept by the provider.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreateKeyOptions struct {
+	Spec           string
+	Usage          string
+	Provider       string // defaults to the key store's default provider.
+	Metadata       KeyMetadata
+	RotationPeriod time.Duration
+	Alias          string // alias created with the key in the same transaction, none when empty.
+}
+
+// UpdateKeyOptions - fields of a key to update, nil fields are left unchanged.
+type UpdateKeyOptions struct {
+	Description    *string
+	Tags           map[string]string // replaces every tag, an empty map removes them all.
+	RotationPeriod *time.Duration    // zero disables automatic rotation.
+}
+
+// Primary - returns the key version used for new cryptographic operations.
+func (k Key) Primary() (KeyVersion, error) {
+	return k.Version(k.PrimaryVersion)
+}
+
+// Version - returns the given version of the key.
+func (k Key) Version(version int) (KeyVersion, error) {
+	for _, keyVersion := range k.Versions {
+		if keyVersion.Version == version {
+			return keyVersion, nil
+		}
+	}
+	return KeyVersion{}, fmt.Errorf("%w: %s version %d", ErrKeyVersionNotFound, k.ID, version)
+}
+
+// RotationDue - reports whether automatic rotation of the key is due at the given time.
+func (k Key) RotationDue(now time.Time) bool {
+	return k.RotationPeriod > 0 && k.NextRotationAt != nil && !now.Before(*k.NextRotationAt) && k.State == KEY_STATE_ENABLED
+}
+
+// rotate - appends a new key version generated by the key's provider and promotes it to primary.
+func (k *Key) rotate(ctx context.Context, provider KeyProvider, now time.Time) error {
+	if err := k.requireState("rotation", KEY_STATE_ENABLED); err != nil {
+		return err
+	}
+
+	version, err := provider.GenerateKeyVersion(ctx, k.ID, k.Spec, k.Versions[len(k.Versions)-1].Version+1)
+	if err != nil {
+		return err
+	}
+
+	k.Versions = append(k.Versions, version)
+	k.PrimaryVersion = version.Version
+	k.scheduleRotation(now)
+
+	return nil
+}
+
+// scheduleRotation - computes the next automatic rotation from the rotation period.
+func (k *Key) scheduleRotation(now time.Time) {
+	if k.RotationPeriod <= 0 {
+		k.NextRotationAt = nil
+		return
+	}
+
+	next := now.Add(k.RotationPeriod).UTC()
+	k.NextRotationAt = &next
+}
+
+// validateRotationPeriod - makes sure the rotation period is either disabled or long enough.
+func validateRotationPeriod(period time.Duration) error {
+	if period != 0 && period < MINIMUM_ROTATION_PERIOD {
+		return ErrInvalidRotation
+	}
+	return nil
+}
+
+// resolveKeyUsage - validates the usage requested for a key spec, defaulting it when empty.
+func resolveKeyUsage(spec, usage string) (string, error) {
+	usages, ok := keySpecUsages[spec]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedKeySpec, spec)
+	}
+
+	if usage == "" {
+		return usages[0], nil
+	}
+
+	for _, supported := range usages {
+		if supported == usage {
+			return usage, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s cannot be used for %s", ErrUnsupportedKeyUsage, spec, usage)
+}
+
+// recordEvent - records an auditing event labelled with the caller identity when an auditor is configured.
+func recordEvent(ctx context.Context, auditor audit.Auditor, level, topic, message string, labels map[string]string) {
+	if auditor == nil {
+		return
+	}
+
+	// The labels are copied, so the caller's map is left untouched and may be nil.
+	//
+	eventLabels := make(map[string]string, len(labels)+2)
+	maps.Copy(eventLabels, labels)
+	eventLabels["callerIdentity"] = CallerIdentity(ctx)
+	if requestID := RequestID(ctx); requestID != "" {
+		eventLabels["requestId"] = requestID
+	}
+	auditor.RecordEvent(audit.NewEvent(level, KMS_AUDIT_GROUP, topic, message, eventLabels))
+}
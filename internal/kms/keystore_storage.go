@@ -0,0 +1,889 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+const (
+	// ALIASES_ADDITIONAL_DATA - additional data sealing the aliases record, key records are sealed with their key ID.
+	ALIASES_ADDITIONAL_DATA = "aliases"
+)
+
+// KeyStoreStorage - key store persisting every key as a JSON record of a storage backend, and all aliases in a single
+// record. With a barrier, every record is sealed under the root key, except the seal configuration.
+type KeyStoreStorage struct {
+	KeyStore
+	auditor   audit.Auditor
+	backend   StorageBackend
+	barrier   *Barrier      // nil when records are stored in plaintext.
+	providers *KeyProviders // nil when every key is a software key.
+}
+
+// sealedRecord - content of a record sealed by the barrier.
+type sealedRecord struct {
+	Sealed []byte `json:"sealed"`
+}
+
+func NewKeyStoreStorage(backend StorageBackend, barrier *Barrier, providers *KeyProviders, auditor audit.Auditor) *KeyStoreStorage {
+	return &KeyStoreStorage{
+		auditor:   auditor,
+		backend:   backend,
+		barrier:   barrier,
+		providers: providers,
+	}
+}
+
+// Open - opens the storage backend.
+func (kS *KeyStoreStorage) Open() error {
+	return kS.backend.Open()
+}
+
+// Close - closes the storage backend.
+func (kS *KeyStoreStorage) Close() error {
+	return kS.backend.Close()
+}
+
+// Barrier - returns the barrier sealing the records, nil when they are stored in plaintext.
+func (kS *KeyStoreStorage) Barrier() *Barrier {
+	return kS.barrier
+}
+
+// KeyProvider - returns a key provider by name, the software provider for keys created before key providers.
+func (kS *KeyStoreStorage) KeyProvider(name string) (KeyProvider, error) {
+	return kS.providers.provider(name)
+}
+
+// ReadSealConfiguration - reads the seal configuration, failing with ErrNotInitialized before the seal is initialized.
+func (kS *KeyStoreStorage) ReadSealConfiguration(ctx context.Context) (SealConfiguration, error) {
+	var configuration SealConfiguration
+	err := kS.backend.View(ctx, func(tx StorageTransaction) error {
+		var err error
+		configuration, err = kS.readSealConfiguration(tx)
+		return err
+	})
+	return configuration, err
+}
+
+// InitializeSeal - seals the records written in plaintext before, then persists the seal configuration. The barrier
+// must already hold the root key.
+func (kS *KeyStoreStorage) InitializeSeal(ctx context.Context, configuration SealConfiguration) error {
+	if kS.barrier == nil {
+		return ErrSealUnsupported
+	}
+
+	return kS.update(ctx, func(tx StorageTransaction) error {
+		if _, err := kS.readSealConfiguration(tx); !errors.Is(err, ErrNotInitialized) {
+			if err == nil {
+				return ErrAlreadyInitialized
+			}
+			return err
+		}
+
+		// Records are collected before being rewritten, as backends do not support writing while iterating. The
+		// configuration is written last, within the same transaction: on failure nothing is written, and the store
+		// is left uninitialized with its records in plaintext, so initializing can be attempted again.
+		//
+		records := map[string][]byte{}
+		err := tx.ForEach(STORAGE_BUCKET_KEYS, func(name string, value []byte) error {
+			records[name] = bytes.Clone(value)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for keyID, content := range records {
+			if err := kS.sealRecord(tx, STORAGE_BUCKET_KEYS, keyID, keyID, content); err != nil {
+				return err
+			}
+		}
+
+		content, err := tx.Get(STORAGE_BUCKET_SYSTEM, STORAGE_RECORD_ALIASES)
+		if err != nil && !errors.Is(err, ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			if err := kS.sealRecord(tx, STORAGE_BUCKET_SYSTEM, STORAGE_RECORD_ALIASES, ALIASES_ADDITIONAL_DATA, content); err != nil {
+				return err
+			}
+		}
+
+		content, err = json.Marshal(configuration)
+		if err != nil {
+			return err
+		}
+		return tx.Put(STORAGE_BUCKET_SYSTEM, STORAGE_RECORD_SEAL, content)
+	})
+}
+
+// CreateKey - creates a new key with freshly generated key material.
+func (kS *KeyStoreStorage) CreateKey(ctx context.Context, options CreateKeyOptions) (Key, error) {
+	if options.Spec == "" {
+		options.Spec = KEY_SPEC_SYMMETRIC_DEFAULT
+	}
+
+	usage, err := resolveKeyUsage(options.Spec, options.Usage)
+	if err != nil {
+		return Key{}, err
+	}
+
+	if err := validateRotationPeriod(options.RotationPeriod); err != nil {
+		return Key{}, err
+	}
+
+	if options.Alias != "" {
+		if err := validateAliasName(options.Alias); err != nil {
+			return Key{}, err
+		}
+	}
+
+	providerName, err := kS.providers.resolve(options.Provider)
+	if err != nil {
+		return Key{}, err
+	}
+	provider, err := kS.providers.provider(providerName)
+	if err != nil {
+		return Key{}, err
+	}
+
+	keyID := uuid.NewString()
+	version, err := provider.GenerateKeyVersion(ctx, keyID, options.Spec, 1)
+	if err != nil {
+		return Key{}, err
+	}
+
+	now := time.Now().UTC()
+	key := Key{
+		ID:             keyID,
+		Spec:           options.Spec,
+		Usage:          usage,
+		Provider:       providerName,
+		State:          KEY_STATE_ENABLED,
+		Metadata:       options.Metadata,
+		Versions:       []KeyVersion{version},
+		PrimaryVersion: version.Version,
+		RotationPeriod: options.RotationPeriod,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	key.scheduleRotation(now)
+
+	var alias Alias
+	err = kS.update(ctx, func(tx StorageTransaction) error {
+		if options.Alias != "" {
+			aliases, err := kS.readAliases(tx)
+			if err != nil {
+				return err
+			}
+			if _, exists := aliases[options.Alias]; exists {
+				return ErrAliasExists
+			}
+
+			alias = Alias{Name: options.Alias, KeyID: key.ID, CreatedAt: now, UpdatedAt: now}
+			aliases[alias.Name] = alias
+			if err := kS.writeAliases(tx, aliases); err != nil {
+				return err
+			}
+		}
+
+		return kS.writeKey(tx, key)
+	})
+	if err != nil {
+		// The key was never stored, destroy its version rather than leaving orphaned material behind in the provider.
+		kS.destroyKeyVersion(ctx, provider, keyID, version)
+		return Key{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Key created", map[string]string{
+		"keyId":    key.ID,
+		"spec":     key.Spec,
+		"usage":    key.Usage,
+		"provider": key.Provider,
+	})
+	if alias.Name != "" {
+		recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Alias created", map[string]string{
+			"alias": alias.Name,
+			"keyId": alias.KeyID,
+		})
+	}
+
+	return key, nil
+}
+
+// GetKey - retrieves a key by its ID.
+func (kS *KeyStoreStorage) GetKey(ctx context.Context, keyID string) (Key, error) {
+	var key Key
+	err := kS.backend.View(ctx, func(tx StorageTransaction) error {
+		var err error
+		key, err = kS.readKey(tx, keyID)
+		return err
+	})
+	return key, err
+}
+
+// ListKeys - lists all keys held by the store, ordered by creation time.
+func (kS *KeyStoreStorage) ListKeys(ctx context.Context) ([]Key, error) {
+	if err := kS.requireUnsealed(); err != nil {
+		return nil, err
+	}
+
+	keys := []Key{}
+	err := kS.backend.View(ctx, func(tx StorageTransaction) error {
+		return tx.ForEach(STORAGE_BUCKET_KEYS, func(name string, value []byte) error {
+			key, err := kS.decodeKey(name, value)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// UpdateKeyMetadata - replaces the metadata of a key.
+func (kS *KeyStoreStorage) UpdateKeyMetadata(ctx context.Context, keyID string, metadata KeyMetadata) (Key, error) {
+	key, err := kS.updateKey(ctx, keyID, func(key *Key) error {
+		if err := key.requireState("updating metadata", KEY_STATE_ENABLED, KEY_STATE_DISABLED, KEY_STATE_PENDING_DELETION); err != nil {
+			return err
+		}
+		key.Metadata = metadata
+		return nil
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Key metadata updated", map[string]string{
+		"keyId": key.ID,
+	})
+
+	return key, nil
+}
+
+// EnableKey - enables a disabled key.
+func (kS *KeyStoreStorage) EnableKey(ctx context.Context, keyID string) (Key, error) {
+	key, err := kS.updateKey(ctx, keyID, func(key *Key) error {
+		if err := key.requireState("enabling", KEY_STATE_ENABLED, KEY_STATE_DISABLED); err != nil {
+			return err
+		}
+		key.State = KEY_STATE_ENABLED
+		return nil
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Key enabled", map[string]string{
+		"keyId": key.ID,
+	})
+
+	return key, nil
+}
+
+// DisableKey - disables a key, refusing any cryptographic operation with it.
+func (kS *KeyStoreStorage) DisableKey(ctx context.Context, keyID string) (Key, error) {
+	key, err := kS.updateKey(ctx, keyID, func(key *Key) error {
+		if err := key.requireState("disabling", KEY_STATE_ENABLED, KEY_STATE_DISABLED); err != nil {
+			return err
+		}
+		key.State = KEY_STATE_DISABLED
+		return nil
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Key disabled", map[string]string{
+		"keyId": key.ID,
+	})
+
+	return key, nil
+}
+
+// ScheduleKeyDeletion - schedules a key for destruction once the pending window elapsed.
+func (kS *KeyStoreStorage) ScheduleKeyDeletion(ctx context.Context, keyID string, pendingWindow time.Duration) (Key, error) {
+	if err := validatePendingWindow(pendingWindow); err != nil {
+		return Key{}, err
+	}
+
+	key, err := kS.updateKey(ctx, keyID, func(key *Key) error {
+		if err := key.requireState("scheduling deletion", KEY_STATE_ENABLED, KEY_STATE_DISABLED); err != nil {
+			return err
+		}
+		date := time.Now().Add(pendingWindow).UTC()
+		key.State = KEY_STATE_PENDING_DELETION
+		key.DeletionDate = &date
+		return nil
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_WARN, audit.TOPIC_KEY_MANAGEMENT, "Key deletion scheduled", map[string]string{
+		"keyId":        key.ID,
+		"deletionDate": key.DeletionDate.Format(time.RFC3339),
+	})
+
+	return key, nil
+}
+
+// CancelKeyDeletion - cancels a scheduled deletion, leaving the key disabled.
+func (kS *KeyStoreStorage) CancelKeyDeletion(ctx context.Context, keyID string) (Key, error) {
+	key, err := kS.updateKey(ctx, keyID, func(key *Key) error {
+		if err := key.requireState("cancelling deletion", KEY_STATE_PENDING_DELETION); err != nil {
+			return err
+		}
+		key.State = KEY_STATE_DISABLED
+		key.DeletionDate = nil
+		return nil
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Key deletion cancelled", map[string]string{
+		"keyId": key.ID,
+	})
+
+	return key, nil
+}
+
+// DestroyKey - irreversibly erases the material of a key whose pending window elapsed. The destroyed state is stored
+// first and the material destroyed in the provider once it is, as the transaction may be retried or aborted while
+// destroyed material cannot be restored.
+func (kS *KeyStoreStorage) DestroyKey(ctx context.Context, keyID string) (Key, error) {
+	var provider KeyProvider
+	var versions []KeyVersion
+	key, err := kS.updateKey(ctx, keyID, func(key *Key) error {
+		if !key.DeletionDue(time.Now()) {
+			return &KeyStateError{KeyID: key.ID, State: key.State, Operation: "destruction before the end of the pending window"}
+		}
+		var err error
+		provider, err = kS.providers.provider(key.Provider)
+		if err != nil {
+			return err
+		}
+
+		versions = append([]KeyVersion(nil), key.Versions...)
+		for i := range key.Versions {
+			key.Versions[i].Material = nil
+			key.Versions[i].Handle = nil
+		}
+		key.State = KEY_STATE_DESTROYED
+		key.RotationPeriod = 0
+		key.NextRotationAt = nil
+		return nil
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	for _, version := range versions {
+		kS.destroyKeyVersion(ctx, provider, key.ID, version)
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_WARN, audit.TOPIC_KEY_MANAGEMENT, "Key destroyed", map[string]string{
+		"keyId": key.ID,
+	})
+
+	return key, nil
+}
+
+// destroyKeyVersion - destroys the material of a key version in its provider, recording an error event when it is
+// left behind.
+func (kS *KeyStoreStorage) destroyKeyVersion(ctx context.Context, provider KeyProvider, keyID string, version KeyVersion) {
+	if err := provider.DestroyKeyVersion(ctx, version); err != nil {
+		recordEvent(ctx, kS.auditor, audit.LEVEL_ERROR, audit.TOPIC_KEY_MANAGEMENT, "Failed to destroy key version material", map[string]string{
+			"keyId":      keyID,
+			"keyVersion": strconv.Itoa(version.Version),
+			"error":      err.Error(),
+		})
+	}
+}
+
+// RotateKey - adds a new key version and makes it the primary version.
+func (kS *KeyStoreStorage) RotateKey(ctx context.Context, keyID string) (Key, error) {
+	keys, err := kS.RotateKeys(ctx, []string{keyID})
+	if err != nil {
+		return Key{}, err
+	}
+	return keys[0], nil
+}
+
+// RotateKeys - rotates several keys within a single transaction, either every key is rotated or none is.
+func (kS *KeyStoreStorage) RotateKeys(ctx context.Context, keyIDs []string) ([]Key, error) {
+	return kS.rotateKeys(ctx, keyIDs, time.Now(), false)
+}
+
+// RotateKeyIfDue - rotates a key when its automatic rotation is due at the given time. The key is read again within
+// the transaction, so of several daemons sharing the storage, only the first one rotates it.
+func (kS *KeyStoreStorage) RotateKeyIfDue(ctx context.Context, keyID string, now time.Time) (Key, bool, error) {
+	keys, err := kS.rotateKeys(ctx, []string{keyID}, now, true)
+	if err != nil || len(keys) == 0 {
+		return Key{}, false, err
+	}
+	return keys[0], true, nil
+}
+
+// rotateKeys - rotates keys within a single transaction, skipping the ones whose rotation is not due when onlyDue is
+// set.
+func (kS *KeyStoreStorage) rotateKeys(ctx context.Context, keyIDs []string, now time.Time, onlyDue bool) ([]Key, error) {
+	type generatedVersion struct {
+		provider KeyProvider
+		keyID    string
+		version  KeyVersion
+	}
+
+	// The versions generated by a transaction were never stored when it failed, they are destroyed rather than left
+	// behind as orphaned material in the providers.
+	//
+	var generated []generatedVersion
+	destroyGenerated := func() {
+		for _, orphan := range generated {
+			kS.destroyKeyVersion(ctx, orphan.provider, orphan.keyID, orphan.version)
+		}
+		generated = nil
+	}
+
+	var keys []Key
+	err := kS.update(ctx, func(tx StorageTransaction) error {
+		destroyGenerated()
+		keys = make([]Key, 0, len(keyIDs))
+		for _, keyID := range keyIDs {
+			key, err := kS.readKey(tx, keyID)
+			if err != nil {
+				return err
+			}
+			if onlyDue && !key.RotationDue(now) {
+				continue
+			}
+			provider, err := kS.providers.provider(key.Provider)
+			if err != nil {
+				return err
+			}
+			if err := key.rotate(ctx, provider, now); err != nil {
+				return err
+			}
+			generated = append(generated, generatedVersion{provider: provider, keyID: key.ID, version: key.Versions[len(key.Versions)-1]})
+
+			key.UpdatedAt = now.UTC()
+			if err := kS.writeKey(tx, key); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		destroyGenerated()
+		return nil, err
+	}
+
+	for _, key := range keys {
+		recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_ROTATED, "Key rotated", map[string]string{
+			"keyId":          key.ID,
+			"primaryVersion": strconv.Itoa(key.PrimaryVersion),
+		})
+	}
+
+	return keys, nil
+}
+
+// UpdateKeyRotationPeriod - sets the automatic rotation period of a key, zero disables it.
+func (kS *KeyStoreStorage) UpdateKeyRotationPeriod(ctx context.Context, keyID string, period time.Duration) (Key, error) {
+	if err := validateRotationPeriod(period); err != nil {
+		return Key{}, err
+	}
+
+	key, err := kS.updateKey(ctx, keyID, func(key *Key) error {
+		if err := key.requireState("updating the rotation period", KEY_STATE_ENABLED, KEY_STATE_DISABLED); err != nil {
+			return err
+		}
+		key.RotationPeriod = period
+		key.scheduleRotation(time.Now())
+		return nil
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Key rotation period updated", map[string]string{
+		"keyId":          key.ID,
+		"rotationPeriod": period.String(),
+	})
+
+	return key, nil
+}
+
+// CreateAlias - creates an alias pointing to a key.
+func (kS *KeyStoreStorage) CreateAlias(ctx context.Context, name, keyID string) (Alias, error) {
+	if err := validateAliasName(name); err != nil {
+		return Alias{}, err
+	}
+
+	var alias Alias
+	err := kS.update(ctx, func(tx StorageTransaction) error {
+		aliases, err := kS.readAliases(tx)
+		if err != nil {
+			return err
+		}
+
+		if _, exists := aliases[name]; exists {
+			return ErrAliasExists
+		}
+
+		target, err := kS.readKey(tx, keyID)
+		if err != nil {
+			return err
+		}
+		if err := validateAliasTarget(target, nil); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		alias = Alias{Name: name, KeyID: target.ID, CreatedAt: now, UpdatedAt: now}
+		aliases[name] = alias
+
+		return kS.writeAliases(tx, aliases)
+	})
+	if err != nil {
+		return Alias{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Alias created", map[string]string{
+		"alias": alias.Name,
+		"keyId": alias.KeyID,
+	})
+
+	return alias, nil
+}
+
+// UpdateAlias - atomically retargets an alias to another key.
+func (kS *KeyStoreStorage) UpdateAlias(ctx context.Context, name, keyID string) (Alias, error) {
+	var alias Alias
+	var previous Key
+	err := kS.update(ctx, func(tx StorageTransaction) error {
+		aliases, err := kS.readAliases(tx)
+		if err != nil {
+			return err
+		}
+
+		var exists bool
+		alias, exists = aliases[name]
+		if !exists {
+			return ErrAliasNotFound
+		}
+
+		previous, err = kS.readKey(tx, alias.KeyID)
+		if err != nil {
+			return err
+		}
+
+		target, err := kS.readKey(tx, keyID)
+		if err != nil {
+			return err
+		}
+		if err := validateAliasTarget(target, &previous); err != nil {
+			return err
+		}
+
+		alias.KeyID = target.ID
+		alias.UpdatedAt = time.Now().UTC()
+		aliases[name] = alias
+
+		return kS.writeAliases(tx, aliases)
+	})
+	if err != nil {
+		return Alias{}, err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Alias updated", map[string]string{
+		"alias":         alias.Name,
+		"keyId":         alias.KeyID,
+		"previousKeyId": previous.ID,
+	})
+
+	return alias, nil
+}
+
+// DeleteAlias - deletes an alias, leaving its key untouched.
+func (kS *KeyStoreStorage) DeleteAlias(ctx context.Context, name string) error {
+	var alias Alias
+	err := kS.update(ctx, func(tx StorageTransaction) error {
+		aliases, err := kS.readAliases(tx)
+		if err != nil {
+			return err
+		}
+
+		var exists bool
+		alias, exists = aliases[name]
+		if !exists {
+			return ErrAliasNotFound
+		}
+		delete(aliases, name)
+
+		return kS.writeAliases(tx, aliases)
+	})
+	if err != nil {
+		return err
+	}
+
+	recordEvent(ctx, kS.auditor, audit.LEVEL_INFO, audit.TOPIC_KEY_MANAGEMENT, "Alias deleted", map[string]string{
+		"alias": alias.Name,
+		"keyId": alias.KeyID,
+	})
+
+	return nil
+}
+
+// ListAliases - lists all aliases, ordered by name.
+func (kS *KeyStoreStorage) ListAliases(ctx context.Context) ([]Alias, error) {
+	var aliases map[string]Alias
+	err := kS.backend.View(ctx, func(tx StorageTransaction) error {
+		var err error
+		aliases, err = kS.readAliases(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Alias, 0, len(aliases))
+	for _, alias := range aliases {
+		list = append(list, alias)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// update - runs a read-write transaction, running it again when it conflicts with a concurrent one, up to
+// STORAGE_CONFLICT_ATTEMPTS times. fn must undo the effects of its previous run outside of the transaction, if any.
+func (kS *KeyStoreStorage) update(ctx context.Context, fn func(tx StorageTransaction) error) error {
+	var err error
+	for attempt := 0; attempt < STORAGE_CONFLICT_ATTEMPTS; attempt++ {
+		if err = kS.backend.Update(ctx, fn); !errors.Is(err, ErrStorageConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// updateKey - reads, modifies and writes back a key within a single transaction.
+func (kS *KeyStoreStorage) updateKey(ctx context.Context, keyID string, update func(key *Key) error) (Key, error) {
+	var key Key
+	err := kS.update(ctx, func(tx StorageTransaction) error {
+		var err error
+		key, err = kS.readKey(tx, keyID)
+		if err != nil {
+			return err
+		}
+
+		if err := update(&key); err != nil {
+			return err
+		}
+		key.UpdatedAt = time.Now().UTC()
+
+		return kS.writeKey(tx, key)
+	})
+	if err != nil {
+		return Key{}, err
+	}
+
+	return key, nil
+}
+
+// readKey - reads a key from its record, resolving aliases.
+func (kS *KeyStoreStorage) readKey(tx StorageTransaction, keyID string) (Key, error) {
+	if err := kS.requireUnsealed(); err != nil {
+		return Key{}, err
+	}
+
+	if IsAlias(keyID) {
+		aliases, err := kS.readAliases(tx)
+		if err != nil {
+			return Key{}, err
+		}
+
+		alias, exists := aliases[keyID]
+		if !exists {
+			return Key{}, ErrAliasNotFound
+		}
+		keyID = alias.KeyID
+	}
+
+	if _, err := uuid.Parse(keyID); err != nil {
+		return Key{}, ErrKeyNotFound
+	}
+
+	content, err := tx.Get(STORAGE_BUCKET_KEYS, keyID)
+	if errors.Is(err, ErrRecordNotFound) {
+		return Key{}, ErrKeyNotFound
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	return kS.decodeKey(keyID, content)
+}
+
+// decodeKey - opens and decodes the record of a key.
+func (kS *KeyStoreStorage) decodeKey(keyID string, content []byte) (Key, error) {
+	content, err := kS.openRecord(keyID, keyID, content)
+	if err != nil {
+		return Key{}, err
+	}
+
+	var key Key
+	if err := json.Unmarshal(content, &key); err != nil {
+		return Key{}, err
+	}
+	if key.Provider == "" {
+		key.Provider = KEY_PROVIDER_SOFTWARE
+	}
+
+	return key, nil
+}
+
+// writeKey - writes a key to its record.
+func (kS *KeyStoreStorage) writeKey(tx StorageTransaction, key Key) error {
+	content, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	return kS.writeRecord(tx, STORAGE_BUCKET_KEYS, key.ID, key.ID, content)
+}
+
+// readAliases - reads all aliases from the aliases record.
+func (kS *KeyStoreStorage) readAliases(tx StorageTransaction) (map[string]Alias, error) {
+	if err := kS.requireUnsealed(); err != nil {
+		return nil, err
+	}
+
+	aliases := map[string]Alias{}
+
+	content, err := tx.Get(STORAGE_BUCKET_SYSTEM, STORAGE_RECORD_ALIASES)
+	if errors.Is(err, ErrRecordNotFound) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err = kS.openRecord(STORAGE_RECORD_ALIASES, ALIASES_ADDITIONAL_DATA, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &aliases); err != nil {
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+// writeAliases - writes all aliases to the aliases record.
+func (kS *KeyStoreStorage) writeAliases(tx StorageTransaction, aliases map[string]Alias) error {
+	content, err := json.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+
+	return kS.writeRecord(tx, STORAGE_BUCKET_SYSTEM, STORAGE_RECORD_ALIASES, ALIASES_ADDITIONAL_DATA, content)
+}
+
+// readSealConfiguration - reads the seal configuration record.
+func (kS *KeyStoreStorage) readSealConfiguration(tx StorageTransaction) (SealConfiguration, error) {
+	content, err := tx.Get(STORAGE_BUCKET_SYSTEM, STORAGE_RECORD_SEAL)
+	if errors.Is(err, ErrRecordNotFound) {
+		return SealConfiguration{}, ErrNotInitialized
+	}
+	if err != nil {
+		return SealConfiguration{}, err
+	}
+
+	var configuration SealConfiguration
+	if err := json.Unmarshal(content, &configuration); err != nil {
+		return SealConfiguration{}, err
+	}
+
+	return configuration, nil
+}
+
+// requireUnsealed - fails with ErrSealed while the barrier is sealed, even when there is no record to open.
+func (kS *KeyStoreStorage) requireUnsealed() error {
+	if kS.barrier != nil && kS.barrier.Sealed() {
+		return ErrSealed
+	}
+	return nil
+}
+
+// openRecord - opens the content of a record with the barrier when there is one. The additional data binds the sealed
+// content to the record, so records cannot be swapped with one another.
+func (kS *KeyStoreStorage) openRecord(name, additionalData string, content []byte) ([]byte, error) {
+	if kS.barrier == nil {
+		return content, nil
+	}
+
+	var sealed sealedRecord
+	if err := json.Unmarshal(content, &sealed); err != nil {
+		return nil, err
+	}
+	if sealed.Sealed == nil {
+		return nil, fmt.Errorf("%s is not sealed", name)
+	}
+
+	return kS.barrier.Decrypt(sealed.Sealed, []byte(additionalData))
+}
+
+// writeRecord - writes a record, sealing it with the barrier when there is one.
+func (kS *KeyStoreStorage) writeRecord(tx StorageTransaction, bucket, name, additionalData string, content []byte) error {
+	if kS.barrier == nil {
+		return tx.Put(bucket, name, content)
+	}
+
+	sealed, err := kS.barrier.Encrypt(content, []byte(additionalData))
+	if err != nil {
+		return err
+	}
+	content, err = json.Marshal(sealedRecord{Sealed: sealed})
+	if err != nil {
+		return err
+	}
+
+	return tx.Put(bucket, name, content)
+}
+
+// sealRecord - seals a record written in plaintext, leaving sealed records untouched.
+func (kS *KeyStoreStorage) sealRecord(tx StorageTransaction, bucket, name, additionalData string, content []byte) error {
+	var sealed sealedRecord
+	if err := json.Unmarshal(content, &sealed); err != nil {
+		return err
+	}
+	if sealed.Sealed != nil {
+		return nil
+	}
+
+	return kS.writeRecord(tx, bucket, name, additionalData, content)
+}
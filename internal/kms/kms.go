@@ -0,0 +1,268 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+var (
+	ErrKeyVersionNotFound = errors.New("key version not found")
+	ErrIncompatibleKey    = errors.New("key is not compatible with the requested operation")
+)
+
+// Ciphertext - ciphertext envelope and the key version that produced it.
+type Ciphertext struct {
+	KeyID      string
+	KeyVersion int
+	Ciphertext []byte
+}
+
+// Plaintext - plaintext recovered from a ciphertext envelope and the key version that produced the envelope.
+type Plaintext struct {
+	KeyID      string
+	KeyVersion int
+	Plaintext  []byte
+}
+
+// Service - cryptographic operations performed with the keys of a key store.
+type Service struct {
+	keyStore KeyStore
+	auditor  audit.Auditor
+	sealLock sync.Mutex // serializes seal transitions.
+}
+
+func NewService(keyStore KeyStore, auditor audit.Auditor) *Service {
+	return &Service{
+		keyStore: keyStore,
+		auditor:  auditor,
+	}
+}
+
+// Open - opens the underlying key store.
+func (s *Service) Open() error {
+	return s.keyStore.Open()
+}
+
+// Close - closes the underlying key store.
+func (s *Service) Close() error {
+	return s.keyStore.Close()
+}
+
+// KeyStore - returns the key store backing the service.
+func (s *Service) KeyStore() KeyStore {
+	return s.keyStore
+}
+
+// keyProvider - returns the provider holding the material of the key.
+func (s *Service) keyProvider(key Key) (KeyProvider, error) {
+	return s.keyStore.KeyProvider(key.Provider)
+}
+
+// Encrypt - encrypts the plaintext under the primary version of the given key, binding it to the encryption context.
+func (s *Service) Encrypt(ctx context.Context, keyID string, plaintext []byte, encryptionContext map[string]string) ([]byte, error) {
+	ciphertext, err := s.EncryptPlaintext(ctx, keyID, plaintext, encryptionContext)
+	return ciphertext.Ciphertext, err
+}
+
+// EncryptPlaintext - encrypts like Encrypt, also returning the key version used.
+func (s *Service) EncryptPlaintext(ctx context.Context, keyID string, plaintext []byte, encryptionContext map[string]string) (Ciphertext, error) {
+	ciphertext, key, version, err := s.encrypt(ctx, keyID, plaintext, encryptionContext)
+	if err != nil {
+		return Ciphertext{}, err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Plaintext encrypted", encryptionContextLabels(map[string]string{
+		"keyId":      key.ID,
+		"keyVersion": strconv.Itoa(version.Version),
+	}, encryptionContext))
+
+	return Ciphertext{KeyID: key.ID, KeyVersion: version.Version, Ciphertext: ciphertext}, nil
+}
+
+// Decrypt - decrypts a ciphertext envelope, locating the key version from its header. Decryption fails unless the
+// encryption context is the one given at encryption.
+func (s *Service) Decrypt(ctx context.Context, ciphertext []byte, encryptionContext map[string]string) ([]byte, error) {
+	plaintext, err := s.DecryptCiphertext(ctx, ciphertext, encryptionContext)
+	return plaintext.Plaintext, err
+}
+
+// DecryptCiphertext - decrypts like Decrypt, also returning the key version that produced the ciphertext.
+func (s *Service) DecryptCiphertext(ctx context.Context, ciphertext []byte, encryptionContext map[string]string) (Plaintext, error) {
+	header, rawHeader, payload, err := ParseCiphertext(ciphertext)
+	if err != nil {
+		return Plaintext{}, err
+	}
+
+	key, err := s.enabledKey(ctx, header.KeyID, "decryption")
+	if err != nil {
+		return Plaintext{}, err
+	}
+
+	version, err := key.Version(header.KeyVersion)
+	if err != nil {
+		return Plaintext{}, err
+	}
+
+	if header.Algorithm != ALGORITHM_AES_256_GCM || key.Spec != KEY_SPEC_SYMMETRIC_DEFAULT || key.Usage != KEY_USAGE_ENCRYPT_DECRYPT {
+		return Plaintext{}, fmt.Errorf("%w: %s cannot decrypt %s", ErrIncompatibleKey, key.Spec, header.Algorithm)
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return Plaintext{}, err
+	}
+
+	plaintext, err := provider.DecryptAES256GCM(ctx, version, header.Nonce, payload, append(rawHeader[:len(rawHeader):len(rawHeader)], encodeEncryptionContext(encryptionContext)...))
+	if err != nil {
+		return Plaintext{}, err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_CRYPTOGRAPHY, "Ciphertext decrypted", encryptionContextLabels(map[string]string{
+		"keyId":      key.ID,
+		"keyVersion": strconv.Itoa(version.Version),
+	}, encryptionContext))
+
+	return Plaintext{KeyID: key.ID, KeyVersion: version.Version, Plaintext: plaintext}, nil
+}
+
+// UpdateKey - updates the fields set in the options, leaving the others unchanged.
+func (s *Service) UpdateKey(ctx context.Context, keyID string, options UpdateKeyOptions) (Key, error) {
+	key, err := s.keyStore.GetKey(ctx, keyID)
+	if err != nil {
+		return Key{}, err
+	}
+
+	if options.Description != nil || options.Tags != nil {
+		metadata := key.Metadata
+		if options.Description != nil {
+			metadata.Description = *options.Description
+		}
+		if options.Tags != nil {
+			metadata.Tags = options.Tags
+		}
+		key, err = s.keyStore.UpdateKeyMetadata(ctx, key.ID, metadata)
+		if err != nil {
+			return Key{}, err
+		}
+	}
+
+	if options.RotationPeriod != nil {
+		key, err = s.keyStore.UpdateKeyRotationPeriod(ctx, key.ID, *options.RotationPeriod)
+		if err != nil {
+			return Key{}, err
+		}
+	}
+
+	return key, nil
+}
+
+// RotateDueKeys - rotates every key whose automatic rotation is due, each within its own transaction. A key failing
+// to rotate does not stop the others, the errors of every failed key are returned together.
+func (s *Service) RotateDueKeys(ctx context.Context) ([]Key, error) {
+	keys, err := s.keyStore.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rotated := []Key{}
+	var errs []error
+	for _, key := range keys {
+		if !key.RotationDue(now) {
+			continue
+		}
+
+		rotatedKey, ok, err := s.keyStore.RotateKeyIfDue(ctx, key.ID, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rotation of key %s failed: %w", key.ID, err))
+			continue
+		}
+		if ok {
+			rotated = append(rotated, rotatedKey)
+		}
+	}
+
+	return rotated, errors.Join(errs...)
+}
+
+// encrypt - encrypts the plaintext under the primary version of the given key without auditing.
+func (s *Service) encrypt(ctx context.Context, keyID string, plaintext []byte, encryptionContext map[string]string) ([]byte, Key, KeyVersion, error) {
+	key, err := s.enabledKey(ctx, keyID, "encryption")
+	if err != nil {
+		return nil, Key{}, KeyVersion{}, err
+	}
+
+	if key.Spec != KEY_SPEC_SYMMETRIC_DEFAULT || key.Usage != KEY_USAGE_ENCRYPT_DECRYPT {
+		return nil, Key{}, KeyVersion{}, fmt.Errorf("%w: %s cannot encrypt", ErrIncompatibleKey, key.Spec)
+	}
+
+	version, err := key.Primary()
+	if err != nil {
+		return nil, Key{}, KeyVersion{}, err
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, Key{}, KeyVersion{}, err
+	}
+
+	provider, err := s.keyProvider(key)
+	if err != nil {
+		return nil, Key{}, KeyVersion{}, err
+	}
+
+	ciphertext, err := sealAES256GCM(ctx, provider, key.ID, version, nonce, plaintext, encodeEncryptionContext(encryptionContext))
+	if err != nil {
+		return nil, Key{}, KeyVersion{}, err
+	}
+
+	return ciphertext, key, version, nil
+}
+
+// sealAES256GCM - encrypts the plaintext into a ciphertext envelope using the given nonce.
+func sealAES256GCM(ctx context.Context, provider KeyProvider, keyID string, version KeyVersion, nonce, plaintext, aad []byte) ([]byte, error) {
+	header, err := CiphertextHeader{
+		FormatVersion: CIPHERTEXT_FORMAT_V1,
+		Algorithm:     ALGORITHM_AES_256_GCM,
+		KeyID:         keyID,
+		KeyVersion:    version.Version,
+		Nonce:         nonce,
+	}.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := provider.EncryptAES256GCM(ctx, version, nonce, plaintext, append(header[:len(header):len(header)], aad...))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, ciphertext...), nil
+}
+
+// newAES256GCM - creates an AES-256-GCM AEAD for the given key material.
+func newAES256GCM(material []byte, nonceSize int) (cipher.AEAD, error) {
+	if len(material) != 32 {
+		return nil, fmt.Errorf("%w: AES-256 requires 32 bytes of key material", ErrIncompatibleKey)
+	}
+
+	block, err := aes.NewCipher(material)
+	if err != nil {
+		return nil, err
+	}
+
+	if nonceSize != 12 {
+		return nil, fmt.Errorf("%w: unexpected nonce size %d", ErrInvalidCiphertext, nonceSize)
+	}
+
+	return cipher.NewGCM(block)
+}
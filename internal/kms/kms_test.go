@@ -0,0 +1,738 @@
+package kms_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+)
+
+const (
+	knownAnswerKeyID = "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"
+)
+
+// newKnownAnswerService - creates a service whose key store holds a key with material 0x00..0x1f.
+func newKnownAnswerService(t *testing.T) *kms.Service {
+	t.Helper()
+
+	directory := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(directory, "keys"), 0700); err != nil {
+		t.Fatalf("Failed to create keys directory: %v", err)
+	}
+
+	// Material is base64 of the bytes 0x00..0x1f.
+	//
+	key := `{"id":"` + knownAnswerKeyID + `","spec":"SYMMETRIC_DEFAULT","usage":"ENCRYPT_DECRYPT","state":"ENABLED","metadata":{"description":"known answer","tags":null},` +
+		`"versions":[{"version":1,"material":"AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=","createdAt":"2025-01-01T00:00:00Z"}],"primaryVersion":1,` +
+		`"createdAt":"2025-01-01T00:00:00Z","updatedAt":"2025-01-01T00:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(directory, "keys", knownAnswerKeyID+".json"), []byte(key), 0600); err != nil {
+		t.Fatalf("Failed to write known answer key: %v", err)
+	}
+
+	service := kmstest.OpenService(t, kms.NewKeyStoreFile(directory, nil), nil)
+	return service
+}
+
+func TestDecrypt_KnownAnswers(t *testing.T) {
+	service := newKnownAnswerService(t)
+
+	scenarios := []struct {
+		name       string
+		ciphertext string
+		context    map[string]string
+		assertions func(t *testing.T, plaintext []byte, err error)
+	}{
+		{
+			name:       "Plaintext With Encryption Context",
+			ciphertext: "01010f1e2d3c4b5a69788796a5b4c3d2e1f0000000010ccafebabefacedbaddecaf888c5d3c548e1371c3b2d6532aa153de8517e57a523ff6d0f073aa923261f45aff864ff141429a7c34009",
+			context:    map[string]string{"tenant": "acme"},
+			assertions: func(t *testing.T, plaintext []byte, err error) {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if string(plaintext) != "OpenKMS known answer test" {
+					t.Errorf("Expected plaintext %q, got %q", "OpenKMS known answer test", plaintext)
+				}
+			},
+		},
+		{
+			name:       "Empty Plaintext Without Encryption Context",
+			ciphertext: "01010f1e2d3c4b5a69788796a5b4c3d2e1f0000000010ccafebabefacedbaddecaf8886eca6eb13d2c3be7e047b12bc7044d97",
+			context:    nil,
+			assertions: func(t *testing.T, plaintext []byte, err error) {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(plaintext) != 0 {
+					t.Errorf("Expected empty plaintext, got %q", plaintext)
+				}
+			},
+		},
+		{
+			name:       "Mismatching Encryption Context",
+			ciphertext: "01010f1e2d3c4b5a69788796a5b4c3d2e1f0000000010ccafebabefacedbaddecaf888c5d3c548e1371c3b2d6532aa153de8517e57a523ff6d0f073aa923261f45aff864ff141429a7c34009",
+			context:    map[string]string{"tenant": "other"},
+			assertions: func(t *testing.T, plaintext []byte, err error) {
+				if !errors.Is(err, kms.ErrInvalidCiphertext) {
+					t.Errorf("Expected ErrInvalidCiphertext, got %v", err)
+				}
+			},
+		},
+		{
+			name:       "Tampered Key Version",
+			ciphertext: "01010f1e2d3c4b5a69788796a5b4c3d2e1f0000000020ccafebabefacedbaddecaf8886eca6eb13d2c3be7e047b12bc7044d97",
+			context:    nil,
+			assertions: func(t *testing.T, plaintext []byte, err error) {
+				if !errors.Is(err, kms.ErrKeyVersionNotFound) {
+					t.Errorf("Expected ErrKeyVersionNotFound, got %v", err)
+				}
+			},
+		},
+		{
+			name:       "Tampered Nonce",
+			ciphertext: "01010f1e2d3c4b5a69788796a5b4c3d2e1f0000000010ccafebabefacedbaddecaf8896eca6eb13d2c3be7e047b12bc7044d97",
+			context:    nil,
+			assertions: func(t *testing.T, plaintext []byte, err error) {
+				if !errors.Is(err, kms.ErrInvalidCiphertext) {
+					t.Errorf("Expected ErrInvalidCiphertext, got %v", err)
+				}
+			},
+		},
+		{
+			name:       "Unsupported Format Version",
+			ciphertext: "02010f1e2d3c4b5a69788796a5b4c3d2e1f0000000010ccafebabefacedbaddecaf8886eca6eb13d2c3be7e047b12bc7044d97",
+			context:    nil,
+			assertions: func(t *testing.T, plaintext []byte, err error) {
+				if !errors.Is(err, kms.ErrInvalidCiphertext) {
+					t.Errorf("Expected ErrInvalidCiphertext, got %v", err)
+				}
+			},
+		},
+		{
+			name:       "Truncated Header",
+			ciphertext: "01010f1e2d3c",
+			context:    nil,
+			assertions: func(t *testing.T, plaintext []byte, err error) {
+				if !errors.Is(err, kms.ErrInvalidCiphertext) {
+					t.Errorf("Expected ErrInvalidCiphertext, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			ciphertext, _ := hex.DecodeString(scenario.ciphertext)
+			plaintext, err := service.Decrypt(context.Background(), ciphertext, scenario.context)
+			scenario.assertions(t, plaintext, err)
+		})
+	}
+}
+
+func TestParseCiphertext(t *testing.T) {
+	ciphertext, _ := hex.DecodeString("01010f1e2d3c4b5a69788796a5b4c3d2e1f0000000010ccafebabefacedbaddecaf8886eca6eb13d2c3be7e047b12bc7044d97")
+
+	header, rawHeader, payload, err := kms.ParseCiphertext(ciphertext)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if header.FormatVersion != kms.CIPHERTEXT_FORMAT_V1 {
+		t.Errorf("Expected format version %d, got %d", kms.CIPHERTEXT_FORMAT_V1, header.FormatVersion)
+	}
+	if header.Algorithm != kms.ALGORITHM_AES_256_GCM {
+		t.Errorf("Expected algorithm %s, got %s", kms.ALGORITHM_AES_256_GCM, header.Algorithm)
+	}
+	if header.KeyID != knownAnswerKeyID {
+		t.Errorf("Expected key ID %s, got %s", knownAnswerKeyID, header.KeyID)
+	}
+	if header.KeyVersion != 1 {
+		t.Errorf("Expected key version 1, got %d", header.KeyVersion)
+	}
+	if hex.EncodeToString(header.Nonce) != "cafebabefacedbaddecaf888" {
+		t.Errorf("Expected nonce cafebabefacedbaddecaf888, got %x", header.Nonce)
+	}
+	if len(rawHeader) != 35 || len(payload) != 16 {
+		t.Errorf("Expected 35 header bytes and 16 payload bytes, got %d and %d", len(rawHeader), len(payload))
+	}
+
+	marshalled, err := header.Marshal()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Equal(marshalled, rawHeader) {
+		t.Errorf("Expected marshalled header %x, got %x", rawHeader, marshalled)
+	}
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	ciphertext, err := service.Encrypt(ctx, key.ID, []byte("secret payload"), map[string]string{"tenant": "acme"})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	header, _, _, err := kms.ParseCiphertext(ciphertext)
+	if err != nil {
+		t.Fatalf("Failed to parse ciphertext: %v", err)
+	}
+	if header.KeyID != key.ID || header.KeyVersion != 1 {
+		t.Errorf("Expected header to reference %s version 1, got %s version %d", key.ID, header.KeyID, header.KeyVersion)
+	}
+
+	plaintext, err := service.Decrypt(ctx, ciphertext, map[string]string{"tenant": "acme"})
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if string(plaintext) != "secret payload" {
+		t.Errorf("Expected plaintext %q, got %q", "secret payload", plaintext)
+	}
+}
+
+func TestDecrypt_KeyUsage(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	service := kmstest.OpenService(t, kms.NewKeyStoreFile(directory, nil), nil)
+
+	key, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	ciphertext, err := service.Encrypt(ctx, key.ID, []byte("secret payload"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// Only keys for encryption decrypt, whatever the spec of a key whose usage was changed in its record.
+	//
+	path := filepath.Join(directory, "keys", key.ID+".json")
+	content, _ := os.ReadFile(path)
+	changed := strings.Replace(string(content), `"usage":"`+kms.KEY_USAGE_ENCRYPT_DECRYPT+`"`, `"usage":"`+kms.KEY_USAGE_GENERATE_VERIFY_MAC+`"`, 1)
+	if changed == string(content) {
+		t.Fatalf("Expected the key record to hold its usage, got %s", content)
+	}
+	if err := os.WriteFile(path, []byte(changed), 0600); err != nil {
+		t.Fatalf("Failed to change key usage: %v", err)
+	}
+
+	if _, err := service.Decrypt(ctx, ciphertext, nil); !errors.Is(err, kms.ErrIncompatibleKey) {
+		t.Errorf("Expected %v, got %v", kms.ErrIncompatibleKey, err)
+	}
+}
+
+func TestEncryptPlaintext(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	key, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	service.KeyStore().RotateKey(ctx, key.ID)
+
+	ciphertext, err := service.EncryptPlaintext(ctx, key.ID, []byte("secret payload"), nil)
+	if err != nil || ciphertext.KeyID != key.ID || ciphertext.KeyVersion != 2 {
+		t.Fatalf("Expected ciphertext of %s version 2, got %s version %d, %v", key.ID, ciphertext.KeyID, ciphertext.KeyVersion, err)
+	}
+
+	plaintext, err := service.DecryptCiphertext(ctx, ciphertext.Ciphertext, nil)
+	if err != nil || plaintext.KeyID != key.ID || plaintext.KeyVersion != 2 || string(plaintext.Plaintext) != "secret payload" {
+		t.Errorf("Expected plaintext of %s version 2, got %+v, %v", key.ID, plaintext, err)
+	}
+}
+
+func TestUpdateKey(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	description := "payments v2"
+	rotationPeriod := 90 * 24 * time.Hour
+	scenarios := []struct {
+		name        string
+		options     kms.UpdateKeyOptions
+		description string
+		tags        map[string]string
+		rotation    time.Duration
+	}{
+		{name: "Nothing set", description: "payments", tags: map[string]string{"team": "billing"}},
+		{name: "Description", options: kms.UpdateKeyOptions{Description: &description}, description: description, tags: map[string]string{"team": "billing"}},
+		{name: "Tags replaced", options: kms.UpdateKeyOptions{Tags: map[string]string{"env": "prod"}}, description: "payments", tags: map[string]string{"env": "prod"}},
+		{name: "Empty tags", options: kms.UpdateKeyOptions{Tags: map[string]string{}}, description: "payments", tags: map[string]string{}},
+		{name: "Rotation period", options: kms.UpdateKeyOptions{RotationPeriod: &rotationPeriod}, description: "payments", tags: map[string]string{"team": "billing"}, rotation: rotationPeriod},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			key, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Metadata: kms.KeyMetadata{Description: "payments", Tags: map[string]string{"team": "billing"}}})
+
+			key, err := service.UpdateKey(ctx, key.ID, scenario.options)
+			if err != nil {
+				t.Fatalf("Failed to update key: %v", err)
+			}
+			if key.Metadata.Description != scenario.description || len(key.Metadata.Tags) != len(scenario.tags) || key.RotationPeriod != scenario.rotation {
+				t.Errorf("Expected %q, tags %v and rotation %s, got %q, tags %v and rotation %s", scenario.description, scenario.tags, scenario.rotation, key.Metadata.Description, key.Metadata.Tags, key.RotationPeriod)
+			}
+			for name, value := range scenario.tags {
+				if key.Metadata.Tags[name] != value {
+					t.Errorf("Expected tag %s=%s, got %v", name, value, key.Metadata.Tags)
+				}
+			}
+		})
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{RotationPeriod: 90 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if key.NextRotationAt == nil || key.RotationDue(time.Now()) {
+		t.Fatalf("Expected rotation to be scheduled in the future, got %v", key.NextRotationAt)
+	}
+	if !key.RotationDue(time.Now().Add(91 * 24 * time.Hour)) {
+		t.Errorf("Expected rotation to be due after the rotation period")
+	}
+
+	oldCiphertext, err := service.Encrypt(ctx, key.ID, []byte("before rotation"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	key, err = service.KeyStore().RotateKey(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	if key.PrimaryVersion != 2 || len(key.Versions) != 2 {
+		t.Fatalf("Expected primary version 2 out of 2 versions, got %d out of %d", key.PrimaryVersion, len(key.Versions))
+	}
+
+	newCiphertext, err := service.Encrypt(ctx, key.ID, []byte("after rotation"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	header, _, _, _ := kms.ParseCiphertext(newCiphertext)
+	if header.KeyVersion != 2 {
+		t.Errorf("Expected new ciphertext to use version 2, got %d", header.KeyVersion)
+	}
+
+	plaintext, err := service.Decrypt(ctx, oldCiphertext, nil)
+	if err != nil || string(plaintext) != "before rotation" {
+		t.Errorf("Expected old ciphertext to decrypt after rotation, got %q, %v", plaintext, err)
+	}
+
+	if _, err := service.KeyStore().UpdateKeyRotationPeriod(ctx, key.ID, time.Hour); !errors.Is(err, kms.ErrInvalidRotation) {
+		t.Errorf("Expected ErrInvalidRotation, got %v", err)
+	}
+}
+
+func TestRotateDueKeys(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	service := kmstest.OpenService(t, kms.NewKeyStoreFile(directory, nil), nil)
+
+	// Every key is made due, and the second one is moved to a key provider the daemon does not have, which fails its
+	// rotation.
+	//
+	keys := make([]kms.Key, 3)
+	for i := range keys {
+		key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{RotationPeriod: 90 * 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+		keys[i] = key
+
+		path := filepath.Join(directory, "keys", key.ID+".json")
+		content, _ := os.ReadFile(path)
+		due := strings.Replace(string(content), key.NextRotationAt.Format(time.RFC3339Nano), "2000-01-01T00:00:00Z", 1)
+		if i == 1 {
+			due = strings.Replace(due, `"state":`, `"provider":"pkcs11","state":`, 1)
+		}
+		if err := os.WriteFile(path, []byte(due), 0600); err != nil {
+			t.Fatalf("Failed to make rotation due: %v", err)
+		}
+	}
+
+	rotated, err := service.RotateDueKeys(ctx)
+	if !errors.Is(err, kms.ErrKeyProviderNotFound) || !strings.Contains(err.Error(), keys[1].ID) {
+		t.Errorf("Expected the failure of the second key, got %v", err)
+	}
+	if len(rotated) != 2 || rotated[0].ID != keys[0].ID || rotated[1].ID != keys[2].ID {
+		t.Fatalf("Expected the first and last keys to be rotated, got %d keys", len(rotated))
+	}
+	for _, key := range rotated {
+		if key.PrimaryVersion != 2 || key.RotationDue(time.Now()) {
+			t.Errorf("Expected %s rotated to version 2 and scheduled again, got %d %v", key.ID, key.PrimaryVersion, key.NextRotationAt)
+		}
+	}
+}
+
+func TestGenerateDataKey(t *testing.T) {
+	ctx := kms.WithCallerIdentity(context.Background(), "billing-service")
+	auditor := &kmstest.Auditor{}
+	service := kmstest.NewService(t, auditor)
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	dataKey, err := service.GenerateDataKey(ctx, key.ID, kms.DATA_KEY_SPEC_AES_256, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate data key: %v", err)
+	}
+	if len(dataKey.Plaintext) != 32 {
+		t.Errorf("Expected 32 byte data key, got %d", len(dataKey.Plaintext))
+	}
+
+	unwrapped, err := service.Decrypt(ctx, dataKey.Ciphertext, nil)
+	if err != nil || !bytes.Equal(unwrapped, dataKey.Plaintext) {
+		t.Errorf("Expected wrapped data key to decrypt to its plaintext, got %x, %v", unwrapped, err)
+	}
+
+	withoutPlaintext, err := service.GenerateDataKeyWithoutPlaintext(ctx, key.ID, kms.DATA_KEY_SPEC_AES_128, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate data key: %v", err)
+	}
+	if withoutPlaintext.Plaintext != nil {
+		t.Errorf("Expected no plaintext, got %x", withoutPlaintext.Plaintext)
+	}
+	unwrapped, err = service.Decrypt(ctx, withoutPlaintext.Ciphertext, nil)
+	if err != nil || len(unwrapped) != 16 {
+		t.Errorf("Expected wrapped data key to decrypt to 16 bytes, got %d, %v", len(unwrapped), err)
+	}
+
+	if _, err := service.GenerateDataKey(ctx, key.ID, "AES_512", nil); !errors.Is(err, kms.ErrUnsupportedDataKeySpec) {
+		t.Errorf("Expected ErrUnsupportedDataKeySpec, got %v", err)
+	}
+
+	generated := 0
+	for _, event := range auditor.Events() {
+		if event.Message == "Data key generated" || event.Message == "Data key generated without plaintext" {
+			generated++
+			if event.Labels["keyId"] != key.ID || event.Labels["callerIdentity"] != "billing-service" {
+				t.Errorf("Expected key ID and caller identity labels, got %v", event.Labels)
+			}
+		}
+	}
+	if generated != 2 {
+		t.Errorf("Expected 2 data key events, got %d", generated)
+	}
+}
+
+func TestGenerateMac(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	scenarios := []struct {
+		spec      string
+		algorithm string
+		hash      func() hash.Hash
+	}{
+		{spec: kms.KEY_SPEC_HMAC_256, algorithm: kms.MAC_ALGORITHM_HMAC_SHA_256, hash: sha256.New},
+		{spec: kms.KEY_SPEC_HMAC_384, algorithm: kms.MAC_ALGORITHM_HMAC_SHA_384, hash: sha512.New384},
+		{spec: kms.KEY_SPEC_HMAC_512, algorithm: kms.MAC_ALGORITHM_HMAC_SHA_512, hash: sha512.New},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.spec, func(t *testing.T) {
+			key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: scenario.spec})
+			if err != nil {
+				t.Fatalf("Failed to create key: %v", err)
+			}
+			if key.Usage != kms.KEY_USAGE_GENERATE_VERIFY_MAC {
+				t.Errorf("Expected usage %s, got %s", kms.KEY_USAGE_GENERATE_VERIFY_MAC, key.Usage)
+			}
+
+			message := []byte(`{"event":"invoice.paid"}`)
+			mac, err := service.GenerateMac(ctx, key.ID, message, scenario.algorithm)
+			if err != nil {
+				t.Fatalf("Failed to generate MAC: %v", err)
+			}
+
+			expected := hmac.New(scenario.hash, key.Versions[0].Material)
+			expected.Write(message)
+			if !hmac.Equal(mac.Mac, expected.Sum(nil)) {
+				t.Errorf("Expected MAC %x, got %x", expected.Sum(nil), mac.Mac)
+			}
+
+			if _, err := service.KeyStore().RotateKey(ctx, key.ID); err != nil {
+				t.Fatalf("Failed to rotate key: %v", err)
+			}
+
+			valid, err := service.VerifyMac(ctx, key.ID, message, mac.Mac, scenario.algorithm)
+			if err != nil || !valid {
+				t.Errorf("Expected MAC of the previous version to verify, got %v, %v", valid, err)
+			}
+
+			valid, err = service.VerifyMac(ctx, key.ID, []byte("tampered"), mac.Mac, scenario.algorithm)
+			if err != nil || valid {
+				t.Errorf("Expected MAC of another message to be rejected, got %v, %v", valid, err)
+			}
+		})
+	}
+
+	key, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_HMAC_256})
+	if _, err := service.GenerateMac(ctx, key.ID, []byte("message"), kms.MAC_ALGORITHM_HMAC_SHA_512); !errors.Is(err, kms.ErrUnsupportedMacAlgorithm) {
+		t.Errorf("Expected ErrUnsupportedMacAlgorithm, got %v", err)
+	}
+	if _, err := service.Encrypt(ctx, key.ID, []byte("message"), nil); !errors.Is(err, kms.ErrIncompatibleKey) {
+		t.Errorf("Expected ErrIncompatibleKey, got %v", err)
+	}
+}
+
+func TestKeyLifecycle(t *testing.T) {
+	ctx := context.Background()
+	auditor := &kmstest.Auditor{}
+	directory := t.TempDir()
+	service := kmstest.OpenService(t, kms.NewKeyStoreFile(directory, auditor), auditor)
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if key.State != kms.KEY_STATE_ENABLED {
+		t.Errorf("Expected state %s, got %s", kms.KEY_STATE_ENABLED, key.State)
+	}
+
+	ciphertext, _ := service.Encrypt(ctx, key.ID, []byte("payload"), nil)
+
+	// Disabled keys refuse cryptographic operations with a typed error.
+	//
+	if _, err := service.KeyStore().DisableKey(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to disable key: %v", err)
+	}
+	var stateError *kms.KeyStateError
+	if _, err := service.Decrypt(ctx, ciphertext, nil); !errors.As(err, &stateError) || stateError.State != kms.KEY_STATE_DISABLED {
+		t.Errorf("Expected KeyStateError for a disabled key, got %v", err)
+	}
+	if _, err := service.KeyStore().EnableKey(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to enable key: %v", err)
+	}
+	if _, err := service.Decrypt(ctx, ciphertext, nil); err != nil {
+		t.Errorf("Expected re-enabled key to decrypt, got %v", err)
+	}
+
+	// Deletion windows are limited to 7 to 30 days and can be cancelled.
+	//
+	if _, err := service.KeyStore().ScheduleKeyDeletion(ctx, key.ID, 24*time.Hour); !errors.Is(err, kms.ErrInvalidPendingWindow) {
+		t.Errorf("Expected ErrInvalidPendingWindow, got %v", err)
+	}
+	key, err = service.KeyStore().ScheduleKeyDeletion(ctx, key.ID, kms.MINIMUM_PENDING_WINDOW)
+	if err != nil || key.State != kms.KEY_STATE_PENDING_DELETION {
+		t.Fatalf("Expected key pending deletion, got %s, %v", key.State, err)
+	}
+	if _, err := service.Encrypt(ctx, key.ID, []byte("payload"), nil); !errors.As(err, &stateError) {
+		t.Errorf("Expected KeyStateError for a key pending deletion, got %v", err)
+	}
+	if _, err := service.KeyStore().DestroyKey(ctx, key.ID); !errors.As(err, &stateError) {
+		t.Errorf("Expected destruction before the end of the window to fail, got %v", err)
+	}
+	key, err = service.KeyStore().CancelKeyDeletion(ctx, key.ID)
+	if err != nil || key.State != kms.KEY_STATE_DISABLED || key.DeletionDate != nil {
+		t.Fatalf("Expected cancelled deletion to leave the key disabled, got %s, %v", key.State, err)
+	}
+
+	// The service applies the default pending window when none is given.
+	//
+	key, err = service.ScheduleKeyDeletion(ctx, key.ID, 0)
+	if err != nil || key.DeletionDate == nil || time.Until(*key.DeletionDate) <= kms.DEFAULT_PENDING_WINDOW-time.Minute {
+		t.Fatalf("Expected deletion after the default pending window, got %v, %v", key.DeletionDate, err)
+	}
+	key, _ = service.KeyStore().CancelKeyDeletion(ctx, key.ID)
+
+	// Once the window elapsed, the sweep destroys the key material.
+	//
+	key, _ = service.KeyStore().ScheduleKeyDeletion(ctx, key.ID, kms.MINIMUM_PENDING_WINDOW)
+	path := filepath.Join(directory, "keys", key.ID+".json")
+	content, _ := os.ReadFile(path)
+	expired := strings.Replace(string(content), key.DeletionDate.Format(time.RFC3339Nano), "2000-01-01T00:00:00Z", 1)
+	if err := os.WriteFile(path, []byte(expired), 0600); err != nil {
+		t.Fatalf("Failed to expire deletion date: %v", err)
+	}
+
+	destroyed, err := service.DestroyDueKeys(ctx)
+	if err != nil || len(destroyed) != 1 {
+		t.Fatalf("Expected 1 destroyed key, got %d, %v", len(destroyed), err)
+	}
+	key, _ = service.KeyStore().GetKey(ctx, key.ID)
+	if key.State != kms.KEY_STATE_DESTROYED || key.Versions[0].Material != nil {
+		t.Errorf("Expected destroyed key without material, got %s", key.State)
+	}
+	if _, err := service.KeyStore().CancelKeyDeletion(ctx, key.ID); !errors.As(err, &stateError) {
+		t.Errorf("Expected destroyed key to stay destroyed, got %v", err)
+	}
+
+	warned := false
+	for _, event := range auditor.Events() {
+		warned = warned || (event.Message == "Key destroyed" && event.Level == audit.LEVEL_WARN)
+	}
+	if !warned {
+		t.Errorf("Expected a WARN audit event for the destroyed key")
+	}
+}
+
+func TestDestroyDueKeys_Failures(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	token := &tokenProvider{objects: map[string]any{}}
+	providers := &kms.KeyProviders{Default: kms.KEY_PROVIDER_SOFTWARE, Providers: map[string]kms.KeyProvider{"token": token}}
+	service := kmstest.OpenService(t, kms.NewKeyStoreStorage(kms.NewStorageBackendFile(directory), nil, providers, nil), nil)
+
+	broken, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Provider: "token"})
+	key, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	for _, due := range []kms.Key{broken, key} {
+		due, err := service.KeyStore().ScheduleKeyDeletion(ctx, due.ID, kms.MINIMUM_PENDING_WINDOW)
+		if err != nil {
+			t.Fatalf("Failed to schedule deletion: %v", err)
+		}
+		expireDeletion(t, directory, due)
+	}
+
+	// A key that cannot be destroyed, its provider missing after a restart, does not hold back the other keys.
+	//
+	restarted := kmstest.OpenService(t, kms.NewKeyStoreFile(directory, nil), nil)
+	destroyed, err := restarted.DestroyDueKeys(ctx)
+	if !errors.Is(err, kms.ErrKeyProviderNotFound) {
+		t.Errorf("Expected %v, got %v", kms.ErrKeyProviderNotFound, err)
+	}
+	if len(destroyed) != 1 || destroyed[0].ID != key.ID || destroyed[0].State != kms.KEY_STATE_DESTROYED {
+		t.Errorf("Expected key %s to be destroyed, got %+v", key.ID, destroyed)
+	}
+	if stored, _ := restarted.KeyStore().GetKey(ctx, broken.ID); stored.State != kms.KEY_STATE_PENDING_DELETION {
+		t.Errorf("Expected key %s to be left pending deletion, got %s", broken.ID, stored.State)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	ctx := context.Background()
+	service := kmstest.NewService(t, nil)
+
+	oldKey, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	newKey, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	signingKey, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{Spec: kms.KEY_SPEC_ED25519})
+
+	if _, err := service.KeyStore().CreateAlias(ctx, "payments-db", oldKey.ID); !errors.Is(err, kms.ErrInvalidAliasName) {
+		t.Errorf("Expected ErrInvalidAliasName, got %v", err)
+	}
+	if _, err := service.KeyStore().CreateAlias(ctx, "alias/payments-db", oldKey.ID); err != nil {
+		t.Fatalf("Failed to create alias: %v", err)
+	}
+	if _, err := service.KeyStore().CreateAlias(ctx, "alias/payments-db", newKey.ID); !errors.Is(err, kms.ErrAliasExists) {
+		t.Errorf("Expected ErrAliasExists, got %v", err)
+	}
+
+	ciphertext, err := service.Encrypt(ctx, "alias/payments-db", []byte("card"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt with alias: %v", err)
+	}
+	header, _, _, _ := kms.ParseCiphertext(ciphertext)
+	if header.KeyID != oldKey.ID {
+		t.Errorf("Expected alias to resolve to %s, got %s", oldKey.ID, header.KeyID)
+	}
+
+	// Retarget the alias, old ciphertexts keep decrypting with their key.
+	//
+	if _, err := service.KeyStore().UpdateAlias(ctx, "alias/payments-db", signingKey.ID); !errors.Is(err, kms.ErrIncompatibleKey) {
+		t.Errorf("Expected retargeting to a key of another usage to fail, got %v", err)
+	}
+	if _, err := service.KeyStore().UpdateAlias(ctx, "alias/payments-db", newKey.ID); err != nil {
+		t.Fatalf("Failed to update alias: %v", err)
+	}
+	ciphertext2, _ := service.Encrypt(ctx, "alias/payments-db", []byte("card"), nil)
+	header, _, _, _ = kms.ParseCiphertext(ciphertext2)
+	if header.KeyID != newKey.ID {
+		t.Errorf("Expected alias to resolve to %s, got %s", newKey.ID, header.KeyID)
+	}
+	if _, err := service.Decrypt(ctx, ciphertext, nil); err != nil {
+		t.Errorf("Expected ciphertext of the previous target to decrypt, got %v", err)
+	}
+
+	key, err := service.KeyStore().DisableKey(ctx, "alias/payments-db")
+	if err != nil || key.ID != newKey.ID {
+		t.Errorf("Expected alias to be accepted by key management operations, got %v", err)
+	}
+
+	aliases, _ := service.KeyStore().ListAliases(ctx)
+	if len(aliases) != 1 || aliases[0].KeyID != newKey.ID {
+		t.Errorf("Expected one alias pointing to %s, got %v", newKey.ID, aliases)
+	}
+
+	if err := service.KeyStore().DeleteAlias(ctx, "alias/payments-db"); err != nil {
+		t.Fatalf("Failed to delete alias: %v", err)
+	}
+	if _, err := service.KeyStore().GetKey(ctx, "alias/payments-db"); !errors.Is(err, kms.ErrAliasNotFound) {
+		t.Errorf("Expected ErrAliasNotFound, got %v", err)
+	}
+}
+
+func TestEncryptionContext(t *testing.T) {
+	ctx := kms.WithCallerIdentity(context.Background(), "tenant-api")
+	auditor := &kmstest.Auditor{}
+	service := kmstest.NewService(t, auditor)
+
+	key, _ := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	encryptionContext := map[string]string{"tenant": "acme", "resource": "invoices/42"}
+
+	ciphertext, err := service.Encrypt(ctx, key.ID, []byte("invoice"), encryptionContext)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	scenarios := []struct {
+		name    string
+		context map[string]string
+		valid   bool
+	}{
+		{name: "Same Context", context: map[string]string{"resource": "invoices/42", "tenant": "acme"}, valid: true},
+		{name: "Other Tenant", context: map[string]string{"tenant": "globex", "resource": "invoices/42"}, valid: false},
+		{name: "Missing Pair", context: map[string]string{"tenant": "acme"}, valid: false},
+		{name: "Extra Pair", context: map[string]string{"tenant": "acme", "resource": "invoices/42", "extra": ""}, valid: false},
+		{name: "Concatenation Ambiguity", context: map[string]string{"tenant": "acmeresource", "": "invoices/42"}, valid: false},
+		{name: "No Context", context: nil, valid: false},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			plaintext, err := service.Decrypt(ctx, ciphertext, scenario.context)
+			if scenario.valid && (err != nil || string(plaintext) != "invoice") {
+				t.Errorf("Expected decryption to succeed, got %q, %v", plaintext, err)
+			}
+			if !scenario.valid && !errors.Is(err, kms.ErrInvalidCiphertext) {
+				t.Errorf("Expected ErrInvalidCiphertext, got %v", err)
+			}
+		})
+	}
+
+	decrypted := false
+	for _, event := range auditor.Events() {
+		if event.Message != "Ciphertext decrypted" {
+			continue
+		}
+		decrypted = true
+		if event.Labels["encryptionContext.tenant"] != "acme" || event.Labels["encryptionContext.resource"] != "invoices/42" {
+			t.Errorf("Expected encryption context in audit labels, got %v", event.Labels)
+		}
+		if event.Labels["callerIdentity"] != "tenant-api" {
+			t.Errorf("Expected caller identity in audit labels, got %v", event.Labels)
+		}
+	}
+	if !decrypted {
+		t.Errorf("Expected a decryption audit event")
+	}
+}
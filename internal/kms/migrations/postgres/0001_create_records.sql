@@ -0,0 +1,9 @@
+-- Records of the key store, the revision is incremented by every write so concurrent daemons detect conflicts.
+CREATE TABLE openkms_records (
+    bucket     TEXT        NOT NULL,
+    name       TEXT        NOT NULL,
+    value      BYTEA       NOT NULL,
+    revision   BIGINT      NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bucket, name)
+);
@@ -0,0 +1,217 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+// SealStatus - returns the seal state of the KMS. Key stores without a barrier are never sealed.
+func (s *Service) SealStatus(ctx context.Context) (SealStatus, error) {
+	barrier := s.keyStore.Barrier()
+	if barrier == nil {
+		return SealStatus{}, nil
+	}
+
+	status := SealStatus{Type: barrier.Type(), Sealed: barrier.Sealed(), Progress: barrier.progress()}
+	configuration, err := s.keyStore.ReadSealConfiguration(ctx)
+	if errors.Is(err, ErrNotInitialized) {
+		return status, nil
+	}
+	if err != nil {
+		return SealStatus{}, err
+	}
+
+	status.Initialized = true
+	status.Shares = configuration.Shares
+	status.Threshold = configuration.Threshold
+	return status, nil
+}
+
+// Initialize - generates the root key sealing the key material and splits it into unseal shares, any threshold of
+// which unseal the KMS after a restart. The KMS is left unsealed.
+func (s *Service) Initialize(ctx context.Context, shares, threshold int) ([][]byte, error) {
+	barrier := s.keyStore.Barrier()
+	if barrier == nil {
+		return nil, ErrSealUnsupported
+	}
+	if barrier.Type() != SEAL_TYPE_SHAMIR {
+		return nil, ErrSealMismatch
+	}
+
+	s.sealLock.Lock()
+	defer s.sealLock.Unlock()
+
+	if _, err := s.keyStore.ReadSealConfiguration(ctx); err == nil {
+		return nil, ErrAlreadyInitialized
+	} else if !errors.Is(err, ErrNotInitialized) {
+		return nil, err
+	}
+
+	configuration, parts, err := barrier.initialize(shares, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.keyStore.InitializeSeal(ctx, configuration); err != nil {
+		barrier.seal()
+		return nil, err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_WARN, audit.TOPIC_LIFECYCLE, "KMS initialized", map[string]string{
+		"sealType":  SEAL_TYPE_SHAMIR,
+		"shares":    strconv.Itoa(shares),
+		"threshold": strconv.Itoa(threshold),
+	})
+
+	return parts, nil
+}
+
+// Unseal - submits an unseal share, unsealing the KMS once a threshold of shares has been submitted.
+func (s *Service) Unseal(ctx context.Context, share []byte) (SealStatus, error) {
+	barrier := s.keyStore.Barrier()
+	if barrier == nil {
+		return SealStatus{}, ErrSealUnsupported
+	}
+
+	s.sealLock.Lock()
+	defer s.sealLock.Unlock()
+
+	configuration, err := s.keyStore.ReadSealConfiguration(ctx)
+	if err != nil {
+		return SealStatus{}, err
+	}
+	if barrier.Type() != SEAL_TYPE_SHAMIR || configuration.Type != SEAL_TYPE_SHAMIR {
+		return SealStatus{}, ErrSealMismatch
+	}
+
+	if barrier.Sealed() {
+		unsealed, err := barrier.unseal(configuration, share)
+		if err != nil {
+			recordEvent(ctx, s.auditor, audit.LEVEL_WARN, audit.TOPIC_LIFECYCLE, "KMS unseal failed", map[string]string{
+				"error": err.Error(),
+			})
+			return SealStatus{}, err
+		}
+		if unsealed {
+			recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_LIFECYCLE, "KMS unsealed", map[string]string{
+				"sealType": SEAL_TYPE_SHAMIR,
+			})
+		}
+	}
+
+	return SealStatus{
+		Type:        SEAL_TYPE_SHAMIR,
+		Initialized: true,
+		Sealed:      barrier.Sealed(),
+		Shares:      configuration.Shares,
+		Threshold:   configuration.Threshold,
+		Progress:    barrier.progress(),
+	}, nil
+}
+
+// AutoUnseal - unseals the KMS with the root key unwrapped by the key wrapper of the transit seal, generating and
+// wrapping a new root key on first use.
+func (s *Service) AutoUnseal(ctx context.Context) error {
+	barrier := s.keyStore.Barrier()
+	if barrier == nil {
+		return ErrSealUnsupported
+	}
+	if barrier.Type() != SEAL_TYPE_TRANSIT {
+		return ErrSealMismatch
+	}
+
+	s.sealLock.Lock()
+	defer s.sealLock.Unlock()
+
+	if !barrier.Sealed() {
+		return nil
+	}
+
+	configuration, err := s.keyStore.ReadSealConfiguration(ctx)
+	if errors.Is(err, ErrNotInitialized) {
+		return s.initializeTransit(ctx, barrier)
+	}
+	if err != nil {
+		return err
+	}
+	if configuration.Type != SEAL_TYPE_TRANSIT {
+		return ErrSealMismatch
+	}
+
+	if err := barrier.unsealTransit(ctx, configuration); err != nil {
+		recordEvent(ctx, s.auditor, audit.LEVEL_WARN, audit.TOPIC_LIFECYCLE, "KMS unseal failed", map[string]string{
+			"sealType": SEAL_TYPE_TRANSIT,
+			"error":    err.Error(),
+		})
+		return err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_INFO, audit.TOPIC_LIFECYCLE, "KMS unsealed", map[string]string{
+		"sealType": SEAL_TYPE_TRANSIT,
+	})
+
+	return nil
+}
+
+// initializeTransit - generates and wraps the root key of the transit seal. The caller must hold the seal lock.
+func (s *Service) initializeTransit(ctx context.Context, barrier *Barrier) error {
+	configuration, err := barrier.initializeTransit(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.keyStore.InitializeSeal(ctx, configuration); err != nil {
+		barrier.seal()
+		return err
+	}
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_WARN, audit.TOPIC_LIFECYCLE, "KMS initialized", map[string]string{
+		"sealType": SEAL_TYPE_TRANSIT,
+	})
+
+	return nil
+}
+
+// Seal - forgets the root key, refusing every operation until the KMS is unsealed again.
+func (s *Service) Seal(ctx context.Context) error {
+	barrier := s.keyStore.Barrier()
+	if barrier == nil {
+		return ErrSealUnsupported
+	}
+
+	s.sealLock.Lock()
+	defer s.sealLock.Unlock()
+
+	if barrier.Sealed() {
+		barrier.seal()
+		return nil
+	}
+	barrier.seal()
+
+	recordEvent(ctx, s.auditor, audit.LEVEL_WARN, audit.TOPIC_LIFECYCLE, "KMS sealed", map[string]string{})
+
+	return nil
+}
+
+// AutoUnsealing - reports whether the KMS unseals itself through the transit seal.
+func (s *Service) AutoUnsealing() bool {
+	barrier := s.keyStore.Barrier()
+	return barrier != nil && barrier.Type() == SEAL_TYPE_TRANSIT
+}
+
+// Sealed - reports whether the KMS is sealed.
+func (s *Service) Sealed() bool {
+	barrier := s.keyStore.Barrier()
+	return barrier != nil && barrier.Sealed()
+}
+
+// WaitUnsealed - blocks until the KMS is unsealed or the context is done.
+func (s *Service) WaitUnsealed(ctx context.Context) error {
+	barrier := s.keyStore.Barrier()
+	if barrier == nil {
+		return nil
+	}
+	return barrier.WaitUnsealed(ctx)
+}
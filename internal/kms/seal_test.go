@@ -0,0 +1,298 @@
+package kms_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+	"github.com/hyperplane-sh/openkms/internal/shamir"
+)
+
+// newSealedService - creates a service whose key store seals its files, reopening the directory after a restart.
+func newSealedService(t *testing.T, directory string, auditor audit.Auditor) *kms.Service {
+	t.Helper()
+
+	return kmstest.OpenService(t, kms.NewKeyStoreStorage(kms.NewStorageBackendFile(directory), kms.NewBarrier(), nil, auditor), auditor)
+}
+
+func TestSeal_InitializeUnseal(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	auditor := &kmstest.Auditor{}
+	service := newSealedService(t, directory, auditor)
+
+	if !service.Sealed() {
+		t.Fatalf("Expected a new service to be sealed")
+	}
+	if _, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{}); !errors.Is(err, kms.ErrSealed) {
+		t.Errorf("Expected %v before initialization, got %v", kms.ErrSealed, err)
+	}
+	if _, err := service.KeyStore().ListKeys(ctx); !errors.Is(err, kms.ErrSealed) {
+		t.Errorf("Expected %v before initialization, got %v", kms.ErrSealed, err)
+	}
+
+	shares, err := service.Initialize(ctx, 5, 3)
+	if err != nil {
+		t.Fatalf("Expected initialization to succeed, got %v", err)
+	}
+	if len(shares) != 5 || service.Sealed() {
+		t.Fatalf("Expected 5 shares and an unsealed service, got %d shares", len(shares))
+	}
+	if _, err := service.Initialize(ctx, 5, 3); !errors.Is(err, kms.ErrAlreadyInitialized) {
+		t.Errorf("Expected %v, got %v", kms.ErrAlreadyInitialized, err)
+	}
+
+	key, err := service.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if _, err := service.KeyStore().CreateAlias(ctx, "alias/sealed", key.ID); err != nil {
+		t.Fatalf("Failed to create alias: %v", err)
+	}
+	ciphertext, err := service.Encrypt(ctx, key.ID, []byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// Neither the key material nor the alias name are readable on disk.
+	//
+	for _, path := range []string{filepath.Join(directory, "keys", key.ID+".json"), filepath.Join(directory, "aliases.json")} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if bytes.Contains(content, []byte(key.ID)) || bytes.Contains(content, []byte("alias/sealed")) {
+			t.Errorf("Expected %s to be sealed, got %s", path, content)
+		}
+	}
+
+	// A restarted service is sealed until a threshold of shares is submitted.
+	//
+	restarted := newSealedService(t, directory, auditor)
+	if _, err := restarted.Decrypt(ctx, ciphertext, nil); !errors.Is(err, kms.ErrSealed) || kms.ErrorCode(err) != kms.ERROR_CODE_FAILED_PRECONDITION {
+		t.Errorf("Expected %v, got %v", kms.ErrSealed, err)
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		waited <- restarted.WaitUnsealed(waitCtx)
+	}()
+
+	for i, share := range []int{4, 0, 2} {
+		status, err := restarted.Unseal(ctx, shares[share])
+		if err != nil {
+			t.Fatalf("Expected share %d to be accepted, got %v", share, err)
+		}
+		if expected := i < 2; status.Sealed != expected || status.Threshold != 3 || status.Shares != 5 {
+			t.Errorf("Expected sealed %t after %d shares, got %+v", expected, i+1, status)
+		}
+	}
+	if err := <-waited; err != nil {
+		t.Errorf("Expected waiters to be released, got %v", err)
+	}
+
+	plaintext, err := restarted.Decrypt(ctx, ciphertext, nil)
+	if err != nil || string(plaintext) != "secret" {
+		t.Errorf("Expected the plaintext after unsealing, got %q %v", plaintext, err)
+	}
+	if alias, err := restarted.KeyStore().GetKey(ctx, "alias/sealed"); err != nil || alias.ID != key.ID {
+		t.Errorf("Expected the alias after unsealing, got %v", err)
+	}
+
+	if err := restarted.Seal(ctx); err != nil || !restarted.Sealed() {
+		t.Fatalf("Expected the service to be sealed again, got %v", err)
+	}
+	if _, err := restarted.KeyStore().GetKey(ctx, key.ID); !errors.Is(err, kms.ErrSealed) {
+		t.Errorf("Expected %v, got %v", kms.ErrSealed, err)
+	}
+
+	// Every transition is a lifecycle event.
+	//
+	messages := map[string]bool{}
+	for _, event := range auditor.Events() {
+		if event.Topic == audit.TOPIC_LIFECYCLE {
+			messages[event.Message] = true
+		}
+	}
+	for _, message := range []string{"KMS initialized", "KMS unsealed", "KMS sealed"} {
+		if !messages[message] {
+			t.Errorf("Expected a %q lifecycle event, got %v", message, messages)
+		}
+	}
+}
+
+func TestSeal_MigratesPlaintextFiles(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+
+	plaintext := kmstest.OpenService(t, kms.NewKeyStoreFile(directory, nil), nil)
+	key, err := plaintext.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if _, err := plaintext.KeyStore().CreateAlias(ctx, "alias/legacy", key.ID); err != nil {
+		t.Fatalf("Failed to create alias: %v", err)
+	}
+
+	service := newSealedService(t, directory, nil)
+	if _, err := service.Initialize(ctx, 1, 1); err != nil {
+		t.Fatalf("Expected initialization to succeed, got %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(directory, "keys", key.ID+".json"))
+	if err != nil || bytes.Contains(content, []byte(key.ID)) {
+		t.Errorf("Expected the key file to be sealed, got %s %v", content, err)
+	}
+	if got, err := service.KeyStore().GetKey(ctx, "alias/legacy"); err != nil || got.ID != key.ID {
+		t.Errorf("Expected the key through its alias, got %v", err)
+	}
+}
+
+// failingBackend - storage backend failing the write of a record, standing for a failure partway through a commit.
+type failingBackend struct {
+	kms.StorageBackend
+	failing string // name of the record whose write fails, none when empty.
+}
+
+type failingTransaction struct {
+	kms.StorageTransaction
+	failing string
+}
+
+func (fB *failingBackend) Update(ctx context.Context, fn func(tx kms.StorageTransaction) error) error {
+	return fB.StorageBackend.Update(ctx, func(tx kms.StorageTransaction) error {
+		return fn(&failingTransaction{StorageTransaction: tx, failing: fB.failing})
+	})
+}
+
+func (fT *failingTransaction) Put(bucket, name string, value []byte) error {
+	if name == fT.failing {
+		return errors.New("injected write failure")
+	}
+	return fT.StorageTransaction.Put(bucket, name, value)
+}
+
+func TestSeal_InitializeAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+
+	plaintext := kms.NewKeyStoreFile(directory, nil)
+	if err := plaintext.Open(); err != nil {
+		t.Fatalf("Failed to open key store: %v", err)
+	}
+	keys := make([]kms.Key, 3)
+	for i := range keys {
+		key, err := plaintext.CreateKey(ctx, kms.CreateKeyOptions{})
+		if err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+		keys[i] = key
+	}
+	if _, err := plaintext.CreateAlias(ctx, "alias/legacy", keys[0].ID); err != nil {
+		t.Fatalf("Failed to create alias: %v", err)
+	}
+
+	// The aliases are sealed after the keys, so the failure comes once every key was sealed.
+	//
+	backend := &failingBackend{StorageBackend: kms.NewStorageBackendFile(directory), failing: kms.STORAGE_RECORD_ALIASES}
+	service := kmstest.OpenService(t, kms.NewKeyStoreStorage(backend, kms.NewBarrier(), nil, nil), nil)
+	if _, err := service.Initialize(ctx, 1, 1); err == nil {
+		t.Fatalf("Expected initialization to fail")
+	}
+	if status, err := service.SealStatus(ctx); err != nil || status.Initialized || !status.Sealed {
+		t.Errorf("Expected the service to be left uninitialized and sealed, got %+v %v", status, err)
+	}
+	if got, err := plaintext.GetKey(ctx, "alias/legacy"); err != nil || got.ID != keys[0].ID {
+		t.Errorf("Expected the records to be left in plaintext, got %v", err)
+	}
+
+	backend.failing = ""
+	shares, err := service.Initialize(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("Expected initialization to succeed once the failure is gone, got %v", err)
+	}
+
+	restarted := newSealedService(t, directory, nil)
+	if _, err := restarted.Unseal(ctx, shares[0]); err != nil {
+		t.Fatalf("Failed to unseal: %v", err)
+	}
+	for _, key := range keys {
+		if _, err := restarted.KeyStore().GetKey(ctx, key.ID); err != nil {
+			t.Errorf("Expected key %s to be readable, got %v", key.ID, err)
+		}
+	}
+	if got, err := restarted.KeyStore().GetKey(ctx, "alias/legacy"); err != nil || got.ID != keys[0].ID {
+		t.Errorf("Expected the key through its alias, got %v", err)
+	}
+}
+
+func TestSeal_Errors(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	shares, err := newSealedService(t, directory, nil).Initialize(ctx, 3, 2)
+	if err != nil {
+		t.Fatalf("Expected initialization to succeed, got %v", err)
+	}
+	otherShares, err := newSealedService(t, t.TempDir(), nil).Initialize(ctx, 3, 2)
+	if err != nil {
+		t.Fatalf("Expected initialization to succeed, got %v", err)
+	}
+
+	scenarios := []struct {
+		name         string
+		shares       [][]byte
+		expected     error
+		expectedCode string
+	}{
+		{"shares of another root key", [][]byte{shares[0], otherShares[1]}, kms.ErrUnsealFailed, kms.ERROR_CODE_INVALID_ARGUMENT},
+		{"same share twice", [][]byte{shares[0], shares[0]}, shamir.ErrInvalidShares, kms.ERROR_CODE_INVALID_ARGUMENT},
+		{"truncated share", [][]byte{shares[0], shares[1][1:]}, shamir.ErrInvalidShares, kms.ERROR_CODE_INVALID_ARGUMENT},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			service := newSealedService(t, directory, nil)
+
+			var err error
+			for _, share := range scenario.shares {
+				if _, err = service.Unseal(ctx, share); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, scenario.expected) || kms.ErrorCode(err) != scenario.expectedCode {
+				t.Errorf("Expected %v, got %v", scenario.expected, err)
+			}
+			if !service.Sealed() {
+				t.Errorf("Expected the service to stay sealed")
+			}
+
+			// Rejected shares are discarded, the right ones still unseal.
+			//
+			if status, err := service.Unseal(ctx, shares[1]); err != nil || status.Progress != 1 {
+				t.Errorf("Expected a fresh unseal attempt, got %+v %v", status, err)
+			}
+			if status, err := service.Unseal(ctx, shares[2]); err != nil || status.Sealed {
+				t.Errorf("Expected the service to be unsealed, got %+v %v", status, err)
+			}
+		})
+	}
+
+	if _, err := newSealedService(t, t.TempDir(), nil).Unseal(ctx, shares[0]); !errors.Is(err, kms.ErrNotInitialized) {
+		t.Errorf("Expected %v, got %v", kms.ErrNotInitialized, err)
+	}
+	if _, err := newSealedService(t, t.TempDir(), nil).Initialize(ctx, 2, 3); !errors.Is(err, shamir.ErrInvalidParameters) {
+		t.Errorf("Expected %v, got %v", shamir.ErrInvalidParameters, err)
+	}
+	if _, err := kmstest.NewService(t, nil).Initialize(ctx, 3, 2); !errors.Is(err, kms.ErrSealUnsupported) {
+		t.Errorf("Expected %v, got %v", kms.ErrSealUnsupported, err)
+	}
+}
@@ -0,0 +1,52 @@
+package kms
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// STORAGE_BUCKET_KEYS - bucket holding every key, by key ID.
+	STORAGE_BUCKET_KEYS = "keys"
+	// STORAGE_BUCKET_SYSTEM - bucket holding the records of the key store itself.
+	STORAGE_BUCKET_SYSTEM = "system"
+
+	// STORAGE_RECORD_ALIASES - record of the system bucket holding all aliases.
+	STORAGE_RECORD_ALIASES = "aliases"
+	// STORAGE_RECORD_SEAL - record of the system bucket holding the seal configuration, never sealed.
+	STORAGE_RECORD_SEAL = "seal"
+
+	// STORAGE_CONFLICT_ATTEMPTS - how many times the key store runs a transaction conflicting with concurrent ones.
+	STORAGE_CONFLICT_ATTEMPTS = 3
+)
+
+var (
+	ErrRecordNotFound      = errors.New("storage record not found")
+	ErrReadOnlyTransaction = errors.New("storage transaction is read-only")
+	ErrStorageConflict     = errors.New("storage record was modified concurrently")
+)
+
+// StorageBackend - transactional record storage of a key store. Records are opaque values grouped in buckets, the key
+// store seals them with its barrier before they reach the backend.
+type StorageBackend interface {
+	// Open - opens the storage and prepares it for use.
+	Open() error
+	// Close - closes the storage and releases any resources.
+	Close() error
+	// View - runs a read-only transaction.
+	View(ctx context.Context, fn func(tx StorageTransaction) error) error
+	// Update - runs a read-write transaction, committing every write atomically when fn succeeds and discarding them
+	// when it fails. fn runs once, a transaction conflicting with a concurrent one fails with ErrStorageConflict
+	// and is retried by the key store.
+	Update(ctx context.Context, fn func(tx StorageTransaction) error) error
+}
+
+// StorageTransaction - records read and written within a transaction, reads observe the writes made before them.
+type StorageTransaction interface {
+	// Get - reads a record, failing with ErrRecordNotFound when it does not exist.
+	Get(bucket, name string) ([]byte, error)
+	// Put - creates or replaces a record.
+	Put(bucket, name string, value []byte) error
+	// ForEach - calls fn with every record of a bucket, ordered by name.
+	ForEach(bucket string, fn func(name string, value []byte) error) error
+}
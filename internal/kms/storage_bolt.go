@@ -0,0 +1,119 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// BOLT_OPEN_TIMEOUT - how long opening waits for another process to release the database file.
+	BOLT_OPEN_TIMEOUT = 5 * time.Second
+)
+
+// StorageBackendBolt - storage backend keeping every record inside a single bbolt database file, one bolt bucket per
+// bucket. Transactions are atomic and durable, a crash never leaves part of a transaction behind.
+type StorageBackendBolt struct {
+	StorageBackend
+	path     string
+	database *bbolt.DB
+}
+
+// storageTransactionBolt - transaction of the bolt storage backend.
+type storageTransactionBolt struct {
+	tx *bbolt.Tx
+}
+
+func NewStorageBackendBolt(path string) *StorageBackendBolt {
+	return &StorageBackendBolt{
+		path: path,
+	}
+}
+
+// Open - opens the database file, creating it and its directory when missing.
+func (sB *StorageBackendBolt) Open() error {
+	if err := os.MkdirAll(filepath.Dir(sB.path), 0700); err != nil {
+		return err
+	}
+
+	database, err := bbolt.Open(sB.path, 0600, &bbolt.Options{Timeout: BOLT_OPEN_TIMEOUT})
+	if err != nil {
+		return err
+	}
+	sB.database = database
+
+	return nil
+}
+
+// Close - closes the database file.
+func (sB *StorageBackendBolt) Close() error {
+	if sB.database == nil {
+		return nil
+	}
+	return sB.database.Close()
+}
+
+// View - runs a read-only transaction.
+func (sB *StorageBackendBolt) View(ctx context.Context, fn func(tx StorageTransaction) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return sB.database.View(func(tx *bbolt.Tx) error {
+		return fn(&storageTransactionBolt{tx: tx})
+	})
+}
+
+// Update - runs a read-write transaction, bolt serializes them.
+func (sB *StorageBackendBolt) Update(ctx context.Context, fn func(tx StorageTransaction) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return sB.database.Update(func(tx *bbolt.Tx) error {
+		return fn(&storageTransactionBolt{tx: tx})
+	})
+}
+
+// Get - reads a record, copying it as bolt values are only valid within their transaction.
+func (tB *storageTransactionBolt) Get(bucket, name string) ([]byte, error) {
+	boltBucket := tB.tx.Bucket([]byte(bucket))
+	if boltBucket == nil {
+		return nil, ErrRecordNotFound
+	}
+
+	value := boltBucket.Get([]byte(name))
+	if value == nil {
+		return nil, ErrRecordNotFound
+	}
+	return bytes.Clone(value), nil
+}
+
+// Put - creates or replaces a record, creating its bucket when missing.
+func (tB *storageTransactionBolt) Put(bucket, name string, value []byte) error {
+	if !tB.tx.Writable() {
+		return ErrReadOnlyTransaction
+	}
+
+	boltBucket, err := tB.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return boltBucket.Put([]byte(name), value)
+}
+
+// ForEach - calls fn with every record of a bucket, in the byte order of their names.
+func (tB *storageTransactionBolt) ForEach(bucket string, fn func(name string, value []byte) error) error {
+	boltBucket := tB.tx.Bucket([]byte(bucket))
+	if boltBucket == nil {
+		return nil
+	}
+
+	return boltBucket.ForEach(func(name, value []byte) error {
+		return fn(string(name), bytes.Clone(value))
+	})
+}
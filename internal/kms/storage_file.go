@@ -0,0 +1,326 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+)
+
+const (
+	// FILE_JOURNAL - file at the root of the storage directory holding the records of a transaction being committed.
+	FILE_JOURNAL = "journal.wal"
+)
+
+// StorageBackendFile - storage backend keeping every record as a JSON file, inside a directory named after its bucket.
+// The records of the system bucket are kept at the root of the storage directory. Transactions are serialized, and a
+// transaction commits by writing all its records to a journal first, then renaming its files into place one by one.
+// A commit interrupted by a crash or a failed write is replayed from the journal before anything is read again.
+type StorageBackendFile struct {
+	StorageBackend
+	storageDirectory string
+	lock             sync.RWMutex
+	interrupted      bool // a commit failed after writing its journal, which must be replayed first.
+}
+
+// fileJournal - records of a transaction being committed.
+type fileJournal struct {
+	Records []fileJournalRecord `json:"records"`
+}
+
+type fileJournalRecord struct {
+	Bucket string `json:"bucket"`
+	Name   string `json:"name"`
+	Value  []byte `json:"value"`
+}
+
+// storageTransactionFile - transaction of the file storage backend, buffering writes until it commits.
+type storageTransactionFile struct {
+	backend  *StorageBackendFile
+	writable bool
+	pending  []fileJournalRecord // records written by the transaction, in order, without their value.
+	writes   map[string][]byte   // content written by the transaction, by path.
+}
+
+func NewStorageBackendFile(storageDirectory string) *StorageBackendFile {
+	return &StorageBackendFile{
+		storageDirectory: storageDirectory,
+		lock:             sync.RWMutex{},
+	}
+}
+
+// NewKeyStoreFile - creates a key store of software keys persisting its records in plaintext as files inside the
+// storage directory, NewKeyStoreStorage seals them or uses other key providers.
+func NewKeyStoreFile(storageDirectory string, auditor audit.Auditor) *KeyStoreStorage {
+	return NewKeyStoreStorage(NewStorageBackendFile(storageDirectory), nil, nil, auditor)
+}
+
+// Open - makes sure the storage directory exists, and completes the commit interrupted by a crash, if any.
+func (sF *StorageBackendFile) Open() error {
+	if err := os.MkdirAll(sF.bucketDirectory(STORAGE_BUCKET_KEYS), 0700); err != nil {
+		return err
+	}
+
+	sF.lock.Lock()
+	defer sF.lock.Unlock()
+
+	return sF.replayJournal()
+}
+
+// Close - closes the storage and releases any resources.
+func (sF *StorageBackendFile) Close() error {
+	return nil
+}
+
+// View - runs a read-only transaction while holding the read lock.
+func (sF *StorageBackendFile) View(ctx context.Context, fn func(tx StorageTransaction) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sF.lock.RLock()
+	defer sF.lock.RUnlock()
+
+	// A transaction never reads the files of a partly applied commit, the write lock is taken to complete it first.
+	//
+	for sF.interrupted {
+		sF.lock.RUnlock()
+		sF.lock.Lock()
+		err := sF.replayJournal()
+		sF.lock.Unlock()
+		sF.lock.RLock()
+		if err != nil {
+			return err
+		}
+	}
+
+	return fn(&storageTransactionFile{backend: sF})
+}
+
+// Update - runs a read-write transaction while holding the write lock, writing its files once fn succeeded.
+func (sF *StorageBackendFile) Update(ctx context.Context, fn func(tx StorageTransaction) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sF.lock.Lock()
+	defer sF.lock.Unlock()
+
+	if sF.interrupted {
+		if err := sF.replayJournal(); err != nil {
+			return err
+		}
+	}
+
+	tx := &storageTransactionFile{backend: sF, writable: true, writes: map[string][]byte{}}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.commit()
+}
+
+// replayJournal - writes the records of the journal left behind by an interrupted commit, then removes it. The caller
+// must hold the write lock.
+func (sF *StorageBackendFile) replayJournal() error {
+	content, err := os.ReadFile(filepath.Join(sF.storageDirectory, FILE_JOURNAL))
+	if errors.Is(err, os.ErrNotExist) {
+		sF.interrupted = false
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var journal fileJournal
+	if err := json.Unmarshal(content, &journal); err != nil {
+		return err
+	}
+	return sF.applyJournal(journal)
+}
+
+// applyJournal - writes the records of a journal, removing it once every record is in place. On failure, the
+// journal is kept and the backend marked as interrupted.
+func (sF *StorageBackendFile) applyJournal(journal fileJournal) error {
+	sF.interrupted = true
+
+	for _, record := range journal.Records {
+		path := sF.recordPath(record.Bucket, record.Name)
+		if err := makeDirectory(filepath.Dir(path)); err != nil {
+			return err
+		}
+		if err := writeFileAtomically(path, record.Value); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(filepath.Join(sF.storageDirectory, FILE_JOURNAL)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	sF.interrupted = false
+
+	return nil
+}
+
+// bucketDirectory - returns the directory holding the files of a bucket.
+func (sF *StorageBackendFile) bucketDirectory(bucket string) string {
+	if bucket == STORAGE_BUCKET_SYSTEM {
+		return sF.storageDirectory
+	}
+	return filepath.Join(sF.storageDirectory, bucket)
+}
+
+// recordPath - returns the path of the file holding a record.
+func (sF *StorageBackendFile) recordPath(bucket, name string) string {
+	return filepath.Join(sF.bucketDirectory(bucket), name+".json")
+}
+
+// Get - reads a record, from the writes of the transaction first.
+func (tF *storageTransactionFile) Get(bucket, name string) ([]byte, error) {
+	path := tF.backend.recordPath(bucket, name)
+	if content, ok := tF.writes[path]; ok {
+		return bytes.Clone(content), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrRecordNotFound
+	}
+	return content, err
+}
+
+// Put - buffers the write of a record until the transaction commits.
+func (tF *storageTransactionFile) Put(bucket, name string, value []byte) error {
+	if !tF.writable {
+		return ErrReadOnlyTransaction
+	}
+
+	path := tF.backend.recordPath(bucket, name)
+	if _, ok := tF.writes[path]; !ok {
+		tF.pending = append(tF.pending, fileJournalRecord{Bucket: bucket, Name: name})
+	}
+	tF.writes[path] = bytes.Clone(value)
+
+	return nil
+}
+
+// ForEach - calls fn with every record of a bucket, including the ones written by the transaction.
+func (tF *storageTransactionFile) ForEach(bucket string, fn func(name string, value []byte) error) error {
+	directory := tF.backend.bucketDirectory(bucket)
+
+	entries, err := os.ReadDir(directory)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	names := map[string]bool{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names[strings.TrimSuffix(entry.Name(), ".json")] = true
+		}
+	}
+	for _, record := range tF.pending {
+		if record.Bucket == bucket {
+			names[record.Name] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		content, err := tF.Get(bucket, name)
+		if err != nil {
+			return err
+		}
+		if err := fn(name, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commit - writes every record of the transaction to the journal in one rename, which commits the transaction, then
+// writes the files of the records in the order they were written.
+func (tF *storageTransactionFile) commit() error {
+	if len(tF.pending) == 0 {
+		return nil
+	}
+
+	journal := fileJournal{Records: make([]fileJournalRecord, 0, len(tF.pending))}
+	for _, record := range tF.pending {
+		record.Value = tF.writes[tF.backend.recordPath(record.Bucket, record.Name)]
+		journal.Records = append(journal.Records, record)
+	}
+	content, err := json.Marshal(journal)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomically(filepath.Join(tF.backend.storageDirectory, FILE_JOURNAL), content); err != nil {
+		return err
+	}
+
+	// Once the journal is in place the transaction is committed, a failure to write the files is retried from the
+	// journal before the next transaction.
+	//
+	tF.backend.applyJournal(journal)
+	return nil
+}
+
+// writeFileAtomically - writes to a temporary file first, so a crash never leaves a half written file behind. The file
+// and its directory are synced before returning, so the write survives a power loss once it succeeded.
+func writeFileAtomically(path string, content []byte) error {
+	temporaryPath := path + ".tmp"
+	file, err := os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temporaryPath, path); err != nil {
+		return err
+	}
+	return syncDirectory(filepath.Dir(path))
+}
+
+// makeDirectory - creates a directory when it is missing, syncing its parent so it survives a power loss.
+func makeDirectory(path string) error {
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return err
+	}
+	return syncDirectory(filepath.Dir(path))
+}
+
+// syncDirectory - flushes the entries of a directory, the files created, renamed or removed in it, to the disk.
+func syncDirectory(path string) error {
+	directory, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer directory.Close()
+	return directory.Sync()
+}
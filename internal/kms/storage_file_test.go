@@ -0,0 +1,200 @@
+package kms_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+func TestKeyStoreFile_Reload(t *testing.T) {
+	ctx := context.Background()
+
+	scenarios := []struct {
+		name   string
+		update func(keyStore kms.KeyStore, keyID string) (kms.Key, error)
+	}{
+		{"created", func(keyStore kms.KeyStore, keyID string) (kms.Key, error) {
+			return keyStore.GetKey(ctx, keyID)
+		}},
+		{"metadata updated", func(keyStore kms.KeyStore, keyID string) (kms.Key, error) {
+			return keyStore.UpdateKeyMetadata(ctx, keyID, kms.KeyMetadata{Description: "billing", Tags: map[string]string{"team": "payments"}})
+		}},
+		{"disabled", func(keyStore kms.KeyStore, keyID string) (kms.Key, error) {
+			return keyStore.DisableKey(ctx, keyID)
+		}},
+		{"rotated", func(keyStore kms.KeyStore, keyID string) (kms.Key, error) {
+			return keyStore.RotateKey(ctx, keyID)
+		}},
+		{"rotation period updated", func(keyStore kms.KeyStore, keyID string) (kms.Key, error) {
+			return keyStore.UpdateKeyRotationPeriod(ctx, keyID, 30*24*time.Hour)
+		}},
+		{"deletion scheduled", func(keyStore kms.KeyStore, keyID string) (kms.Key, error) {
+			return keyStore.ScheduleKeyDeletion(ctx, keyID, 7*24*time.Hour)
+		}},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			directory := t.TempDir()
+			keyStore := kms.NewKeyStoreFile(directory, nil)
+			if err := keyStore.Open(); err != nil {
+				t.Fatalf("Failed to open key store: %v", err)
+			}
+
+			created, err := keyStore.CreateKey(ctx, kms.CreateKeyOptions{Metadata: kms.KeyMetadata{Description: "payments"}})
+			if err != nil {
+				t.Fatalf("Failed to create key: %v", err)
+			}
+			expected, err := scenario.update(keyStore, created.ID)
+			if err != nil {
+				t.Fatalf("Failed to update key: %v", err)
+			}
+
+			// A key store reopened over the same directory reads back the key as it was returned.
+			//
+			reloaded := kms.NewKeyStoreFile(directory, nil)
+			if err := reloaded.Open(); err != nil {
+				t.Fatalf("Failed to open key store: %v", err)
+			}
+			key, err := reloaded.GetKey(ctx, created.ID)
+			if err != nil {
+				t.Fatalf("Failed to get key: %v", err)
+			}
+			if !reflect.DeepEqual(key, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, key)
+			}
+
+			info, err := os.Stat(filepath.Join(directory, "keys", created.ID+".json"))
+			if err != nil || info.Mode().Perm() != 0600 {
+				t.Errorf("Expected a key file readable by its owner only, got %v %v", info, err)
+			}
+		})
+	}
+}
+
+func TestKeyStoreFile_IgnoresStrayFiles(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	keyStore := kms.NewKeyStoreFile(directory, nil)
+	if err := keyStore.Open(); err != nil {
+		t.Fatalf("Failed to open key store: %v", err)
+	}
+	key, err := keyStore.CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	// Leftovers of an interrupted write and unrelated files are not keys.
+	//
+	for _, name := range []string{key.ID + ".json.tmp", "README.txt"} {
+		if err := os.WriteFile(filepath.Join(directory, "keys", name), []byte("{"), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(directory, "keys", "archive.json"), 0700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	keys, err := kms.NewKeyStoreFile(directory, nil).ListKeys(ctx)
+	if err != nil || len(keys) != 1 || keys[0].ID != key.ID {
+		t.Errorf("Expected only the created key, got %v %v", keys, err)
+	}
+}
+
+func TestStorageBackendFile_InterruptedCommit(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	backend := kms.NewStorageBackendFile(directory)
+	if err := backend.Open(); err != nil {
+		t.Fatalf("Failed to open backend: %v", err)
+	}
+
+	// A directory in place of the second record fails its rename, after the first record was written.
+	//
+	blocked := filepath.Join(directory, "keys", "second.json")
+	if err := os.Mkdir(blocked, 0700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	err := backend.Update(ctx, func(tx kms.StorageTransaction) error {
+		for _, name := range []string{"first", "second"} {
+			if err := tx.Put(kms.STORAGE_BUCKET_KEYS, name, []byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the transaction to be committed by its journal, got %v", err)
+	}
+
+	// Nothing is read until the commit is completed, rather than only the first record.
+	//
+	read := func(backend kms.StorageBackend) ([]string, error) {
+		var names []string
+		err := backend.View(ctx, func(tx kms.StorageTransaction) error {
+			return tx.ForEach(kms.STORAGE_BUCKET_KEYS, func(name string, value []byte) error {
+				names = append(names, name)
+				return nil
+			})
+		})
+		return names, err
+	}
+	if names, err := read(backend); err == nil {
+		t.Errorf("Expected reads to fail while the commit is incomplete, got %v", names)
+	}
+	if reopened := kms.NewStorageBackendFile(directory); reopened.Open() == nil {
+		t.Errorf("Expected opening to fail while the commit is incomplete")
+	}
+
+	if err := os.Remove(blocked); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	if names, err := read(backend); err != nil || !reflect.DeepEqual(names, []string{"first", "second"}) {
+		t.Errorf("Expected both records once the commit is completed, got %v %v", names, err)
+	}
+	if _, err := os.Stat(filepath.Join(directory, kms.FILE_JOURNAL)); !os.IsNotExist(err) {
+		t.Errorf("Expected the journal to be removed, got %v", err)
+	}
+}
+
+func TestStorageBackendFile_ReplaysJournalOnOpen(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	backend := kms.NewStorageBackendFile(directory)
+	if err := backend.Open(); err != nil {
+		t.Fatalf("Failed to open backend: %v", err)
+	}
+
+	blocked := filepath.Join(directory, "keys", "second.json")
+	if err := os.Mkdir(blocked, 0700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	err := backend.Update(ctx, func(tx kms.StorageTransaction) error {
+		if err := tx.Put(kms.STORAGE_BUCKET_KEYS, "first", []byte("first")); err != nil {
+			return err
+		}
+		return tx.Put(kms.STORAGE_BUCKET_KEYS, "second", []byte("second"))
+	})
+	if err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if err := os.Remove(blocked); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+
+	// A daemon restarted after the crash completes the commit when opening the storage.
+	//
+	restarted := kms.NewStorageBackendFile(directory)
+	if err := restarted.Open(); err != nil {
+		t.Fatalf("Failed to open backend: %v", err)
+	}
+	content, err := os.ReadFile(blocked)
+	if err != nil || string(content) != "second" {
+		t.Errorf("Expected the second record to be written, got %q %v", content, err)
+	}
+}
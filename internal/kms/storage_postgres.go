@@ -0,0 +1,336 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// POSTGRES_MIGRATIONS_DIRECTORY - embedded directory of the schema migrations, applied in the order of their names.
+	POSTGRES_MIGRATIONS_DIRECTORY = "migrations/postgres"
+	// POSTGRES_MIGRATIONS_LOCK - advisory lock serializing the migrations of daemons starting together.
+	POSTGRES_MIGRATIONS_LOCK = 0x6f6b6d73
+
+	// SQLSTATE codes of the transactions PostgreSQL aborts because of a concurrent one, reported as
+	// ErrStorageConflict.
+	POSTGRES_SERIALIZATION_FAILURE = "40001"
+	POSTGRES_DEADLOCK_DETECTED     = "40P01"
+)
+
+var (
+	//go:embed migrations/postgres/*.sql
+	postgresMigrations embed.FS
+)
+
+// StorageBackendPostgres - storage backend keeping every record as a row of a PostgreSQL table, so several daemons
+// can share the same keys. Read-write transactions are serializable: a transaction fails with ErrStorageConflict when
+// a record it read, or found missing, was changed by another daemon before it committed.
+type StorageBackendPostgres struct {
+	StorageBackend
+	dsn  string
+	pool PostgresPool
+}
+
+// PostgresPool - connections the PostgreSQL storage backend runs its transactions on, e.g. a *pgxpool.Pool.
+type PostgresPool interface {
+	BeginTx(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error)
+	Close()
+}
+
+// postgresRecord - bucket and name identifying a record.
+type postgresRecord struct {
+	bucket string
+	name   string
+}
+
+// storageTransactionPostgres - transaction of the PostgreSQL storage backend, buffering writes until it commits.
+type storageTransactionPostgres struct {
+	ctx       context.Context
+	tx        pgx.Tx
+	writable  bool
+	revisions map[postgresRecord]int64 // revision of every record read, zero when it did not exist.
+	pending   []postgresRecord         // records written by the transaction, in order.
+	writes    map[postgresRecord][]byte
+}
+
+func NewStorageBackendPostgres(dsn string) *StorageBackendPostgres {
+	return &StorageBackendPostgres{
+		dsn: dsn,
+	}
+}
+
+// NewStorageBackendPostgresPool - creates a storage backend over connections opened by the caller, which it closes
+// when closed.
+func NewStorageBackendPostgresPool(pool PostgresPool) *StorageBackendPostgres {
+	return &StorageBackendPostgres{
+		pool: pool,
+	}
+}
+
+// Open - connects to the database, unless connections were given, and applies the schema migrations it is missing.
+func (sP *StorageBackendPostgres) Open() error {
+	ctx := context.Background()
+
+	pool := sP.pool
+	if pool == nil {
+		var err error
+		if pool, err = pgxpool.New(ctx, sP.dsn); err != nil {
+			return err
+		}
+	}
+	if err := migratePostgres(ctx, pool); err != nil {
+		pool.Close()
+		return err
+	}
+	sP.pool = pool
+
+	return nil
+}
+
+// Close - closes every connection to the database.
+func (sP *StorageBackendPostgres) Close() error {
+	if sP.pool != nil {
+		sP.pool.Close()
+	}
+	return nil
+}
+
+// View - runs a read-only transaction over a consistent snapshot.
+func (sP *StorageBackendPostgres) View(ctx context.Context, fn func(tx StorageTransaction) error) error {
+	tx, err := sP.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&storageTransactionPostgres{ctx: ctx, tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Update - runs a serializable read-write transaction. PostgreSQL aborts it when a concurrent transaction changed the
+// records it read, including the ones it found missing, which is reported as ErrStorageConflict.
+func (sP *StorageBackendPostgres) Update(ctx context.Context, fn func(tx StorageTransaction) error) error {
+	tx, err := sP.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	transaction := &storageTransactionPostgres{
+		ctx:       ctx,
+		tx:        tx,
+		writable:  true,
+		revisions: map[postgresRecord]int64{},
+		writes:    map[postgresRecord][]byte{},
+	}
+	if err := fn(transaction); err != nil {
+		return postgresConflict(err)
+	}
+	if err := transaction.commit(); err != nil {
+		return postgresConflict(err)
+	}
+
+	return postgresConflict(tx.Commit(ctx))
+}
+
+// Get - reads a record, from the writes of the transaction first.
+func (tP *storageTransactionPostgres) Get(bucket, name string) ([]byte, error) {
+	record := postgresRecord{bucket: bucket, name: name}
+	if value, ok := tP.writes[record]; ok {
+		return bytes.Clone(value), nil
+	}
+
+	var value []byte
+	var revision int64
+	err := tP.tx.QueryRow(tP.ctx, "SELECT value, revision FROM openkms_records WHERE bucket = $1 AND name = $2", bucket, name).Scan(&value, &revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		tP.observe(record, 0)
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tP.observe(record, revision)
+	return value, nil
+}
+
+// Put - buffers the write of a record until the transaction commits.
+func (tP *storageTransactionPostgres) Put(bucket, name string, value []byte) error {
+	if !tP.writable {
+		return ErrReadOnlyTransaction
+	}
+
+	record := postgresRecord{bucket: bucket, name: name}
+	if _, ok := tP.writes[record]; !ok {
+		tP.pending = append(tP.pending, record)
+	}
+	tP.writes[record] = bytes.Clone(value)
+
+	return nil
+}
+
+// ForEach - calls fn with every record of a bucket, including the ones written by the transaction. The rows are read
+// entirely first, as the connection cannot run other queries while they are open.
+func (tP *storageTransactionPostgres) ForEach(bucket string, fn func(name string, value []byte) error) error {
+	rows, err := tP.tx.Query(tP.ctx, "SELECT name, value, revision FROM openkms_records WHERE bucket = $1", bucket)
+	if err != nil {
+		return err
+	}
+
+	values := map[string][]byte{}
+	for rows.Next() {
+		var name string
+		var value []byte
+		var revision int64
+		if err := rows.Scan(&name, &value, &revision); err != nil {
+			rows.Close()
+			return err
+		}
+		values[name] = value
+		tP.observe(postgresRecord{bucket: bucket, name: name}, revision)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, record := range tP.pending {
+		if record.bucket == bucket {
+			values[record.name] = bytes.Clone(tP.writes[record])
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := fn(name, values[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// observe - remembers the revision of a record the first time the transaction reads it.
+func (tP *storageTransactionPostgres) observe(record postgresRecord, revision int64) {
+	if tP.revisions == nil {
+		return
+	}
+	if _, ok := tP.revisions[record]; !ok {
+		tP.revisions[record] = revision
+	}
+}
+
+// commit - writes the records of the transaction. A record read is only written when it still has the revision read,
+// and a record read as missing is only inserted when it still does not exist.
+func (tP *storageTransactionPostgres) commit() error {
+	for _, record := range tP.pending {
+		value := tP.writes[record]
+		revision, read := tP.revisions[record]
+
+		var tag pgconn.CommandTag
+		var err error
+		switch {
+		case !read:
+			tag, err = tP.tx.Exec(tP.ctx, `INSERT INTO openkms_records (bucket, name, value, revision) VALUES ($1, $2, $3, 1)
+				ON CONFLICT (bucket, name) DO UPDATE SET value = EXCLUDED.value, revision = openkms_records.revision + 1, updated_at = now()`,
+				record.bucket, record.name, value)
+		case revision == 0:
+			tag, err = tP.tx.Exec(tP.ctx, `INSERT INTO openkms_records (bucket, name, value, revision) VALUES ($1, $2, $3, 1)
+				ON CONFLICT (bucket, name) DO NOTHING`,
+				record.bucket, record.name, value)
+		default:
+			tag, err = tP.tx.Exec(tP.ctx, `UPDATE openkms_records SET value = $3, revision = revision + 1, updated_at = now()
+				WHERE bucket = $1 AND name = $2 AND revision = $4`,
+				record.bucket, record.name, value, revision)
+		}
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: %s/%s", ErrStorageConflict, record.bucket, record.name)
+		}
+	}
+
+	return nil
+}
+
+// postgresConflict - reports the transactions PostgreSQL aborted because of a concurrent one as ErrStorageConflict.
+func postgresConflict(err error) error {
+	var pgError *pgconn.PgError
+	if errors.As(err, &pgError) && (pgError.Code == POSTGRES_SERIALIZATION_FAILURE || pgError.Code == POSTGRES_DEADLOCK_DETECTED) {
+		return fmt.Errorf("%w: %v", ErrStorageConflict, err)
+	}
+	return err
+}
+
+// migratePostgres - applies the embedded migrations missing from the database within a single transaction, holding
+// an advisory lock so daemons starting together do not apply them twice.
+func migratePostgres(ctx context.Context, pool PostgresPool) error {
+	entries, err := fs.ReadDir(postgresMigrations, POSTGRES_MIGRATIONS_DIRECTORY)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", POSTGRES_MIGRATIONS_LOCK); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS openkms_schema_migrations (
+		version    TEXT        PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		version := strings.TrimSuffix(entry.Name(), ".sql")
+
+		var applied bool
+		err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM openkms_schema_migrations WHERE version = $1)", version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		statements, err := postgresMigrations.ReadFile(path.Join(POSTGRES_MIGRATIONS_DIRECTORY, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, string(statements)); err != nil {
+			return fmt.Errorf("migration %s failed: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO openkms_schema_migrations (version) VALUES ($1)", version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
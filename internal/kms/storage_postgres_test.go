@@ -0,0 +1,248 @@
+package kms_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+const (
+	mockSelectRecord  = `^SELECT value, revision FROM openkms_records WHERE bucket = \$1 AND name = \$2$`
+	mockSelectBucket  = `^SELECT name, value, revision FROM openkms_records WHERE bucket = \$1$`
+	mockUpsertRecord  = `^INSERT INTO openkms_records .* DO UPDATE SET`
+	mockInsertRecord  = `^INSERT INTO openkms_records .* DO NOTHING$`
+	mockUpdateRecord  = `^UPDATE openkms_records SET .* AND revision = \$4$`
+	mockSelectApplied = `^SELECT EXISTS \(SELECT 1 FROM openkms_schema_migrations WHERE version = \$1\)$`
+)
+
+// expectMigrations - expects the migrations of a backend being opened, reporting the first one as already applied or
+// not.
+func expectMigrations(mock pgxmock.PgxPoolIface, applied bool) {
+	mock.ExpectBeginTx(pgx.TxOptions{})
+	mock.ExpectExec(`^SELECT pg_advisory_xact_lock\(\$1\)$`).WithArgs(kms.POSTGRES_MIGRATIONS_LOCK).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec(`^CREATE TABLE IF NOT EXISTS openkms_schema_migrations`).WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectQuery(mockSelectApplied).WithArgs("0001_create_records").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(applied))
+	if !applied {
+		mock.ExpectExec(`CREATE TABLE openkms_records`).WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+		mock.ExpectExec(`^INSERT INTO openkms_schema_migrations \(version\) VALUES \(\$1\)$`).WithArgs("0001_create_records").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mock.ExpectCommit()
+}
+
+// openPostgresMock - opens a PostgreSQL storage backend over a mock of its connections, with its migrations applied.
+func openPostgresMock(t *testing.T) (*kms.StorageBackendPostgres, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	expectMigrations(mock, true)
+
+	backend := kms.NewStorageBackendPostgresPool(mock)
+	if err := backend.Open(); err != nil {
+		t.Fatalf("Failed to open backend: %v", err)
+	}
+
+	return backend, mock
+}
+
+func TestStorageBackendPostgres_Open(t *testing.T) {
+	for _, applied := range []bool{false, true} {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("Failed to create mock: %v", err)
+		}
+		expectMigrations(mock, applied)
+
+		if err := kms.NewStorageBackendPostgresPool(mock).Open(); err != nil {
+			t.Errorf("Expected backend to open with migrations applied %v, got %v", applied, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Expected migrations applied %v, got %v", applied, err)
+		}
+	}
+}
+
+func TestStorageBackendPostgres_Update(t *testing.T) {
+	ctx := context.Background()
+	serializationFailure := &pgconn.PgError{Code: kms.POSTGRES_SERIALIZATION_FAILURE, Message: "could not serialize access"}
+
+	scenarios := []struct {
+		name   string
+		expect func(mock pgxmock.PgxPoolIface)
+		update func(tx kms.StorageTransaction) error
+		err    error
+	}{
+		{
+			name: "Record read is updated at the revision read",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(mockSelectRecord).WithArgs("keys", "key-1").WillReturnRows(pgxmock.NewRows([]string{"value", "revision"}).AddRow([]byte("first"), int64(3)))
+				mock.ExpectExec(mockUpdateRecord).WithArgs("keys", "key-1", []byte("second"), int64(3)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				mock.ExpectCommit()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				if _, err := tx.Get("keys", "key-1"); err != nil {
+					return err
+				}
+				return tx.Put("keys", "key-1", []byte("second"))
+			},
+		},
+		{
+			name: "Record read as missing is only inserted",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(mockSelectRecord).WithArgs("keys", "key-1").WillReturnRows(pgxmock.NewRows([]string{"value", "revision"}))
+				mock.ExpectExec(mockInsertRecord).WithArgs("keys", "key-1", []byte("first")).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectCommit()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				if _, err := tx.Get("keys", "key-1"); !errors.Is(err, kms.ErrRecordNotFound) {
+					return err
+				}
+				return tx.Put("keys", "key-1", []byte("first"))
+			},
+		},
+		{
+			name: "Record not read is written whatever its revision",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(mockUpsertRecord).WithArgs("system", "aliases", []byte("{}")).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectCommit()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				return tx.Put("system", "aliases", []byte("{}"))
+			},
+		},
+		{
+			name: "Record changed since read",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(mockSelectRecord).WithArgs("keys", "key-1").WillReturnRows(pgxmock.NewRows([]string{"value", "revision"}).AddRow([]byte("first"), int64(3)))
+				mock.ExpectExec(mockUpdateRecord).WithArgs("keys", "key-1", []byte("second"), int64(3)).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				mock.ExpectRollback()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				if _, err := tx.Get("keys", "key-1"); err != nil {
+					return err
+				}
+				return tx.Put("keys", "key-1", []byte("second"))
+			},
+			err: kms.ErrStorageConflict,
+		},
+		{
+			name: "Record created since read as missing",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(mockSelectRecord).WithArgs("keys", "key-1").WillReturnRows(pgxmock.NewRows([]string{"value", "revision"}))
+				mock.ExpectExec(mockInsertRecord).WithArgs("keys", "key-1", []byte("first")).WillReturnResult(pgxmock.NewResult("INSERT", 0))
+				mock.ExpectRollback()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				if _, err := tx.Get("keys", "key-1"); !errors.Is(err, kms.ErrRecordNotFound) {
+					return err
+				}
+				return tx.Put("keys", "key-1", []byte("first"))
+			},
+			err: kms.ErrStorageConflict,
+		},
+		{
+			name: "Serialization failure when committing",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(mockUpsertRecord).WithArgs("keys", "key-1", []byte("first")).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectCommit().WillReturnError(serializationFailure)
+				mock.ExpectRollback()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				return tx.Put("keys", "key-1", []byte("first"))
+			},
+			err: kms.ErrStorageConflict,
+		},
+		{
+			name: "Serialization failure when reading",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(mockSelectRecord).WithArgs("keys", "key-1").WillReturnError(serializationFailure)
+				mock.ExpectRollback()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				_, err := tx.Get("keys", "key-1")
+				return err
+			},
+			err: kms.ErrStorageConflict,
+		},
+		{
+			name: "Other failures are not conflicts",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(mockUpsertRecord).WithArgs("keys", "key-1", []byte("first")).WillReturnError(&pgconn.PgError{Code: "53100", Message: "disk full"})
+				mock.ExpectRollback()
+			},
+			update: func(tx kms.StorageTransaction) error {
+				return tx.Put("keys", "key-1", []byte("first"))
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			backend, mock := openPostgresMock(t)
+			mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+			scenario.expect(mock)
+
+			err := backend.Update(ctx, scenario.update)
+			switch {
+			case scenario.err != nil && !errors.Is(err, scenario.err):
+				t.Errorf("Expected error %v, got %v", scenario.err, err)
+			case scenario.err == nil && errors.Is(err, kms.ErrStorageConflict):
+				t.Errorf("Expected no conflict, got %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Expected queries were not run: %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageBackendPostgres_ForEach(t *testing.T) {
+	backend, mock := openPostgresMock(t)
+	mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+	mock.ExpectQuery(mockSelectBucket).WithArgs("keys").WillReturnRows(pgxmock.NewRows([]string{"name", "value", "revision"}).
+		AddRow("key-1", []byte("first"), int64(2)).
+		AddRow("key-2", []byte("second"), int64(5)))
+	mock.ExpectExec(mockUpsertRecord).WithArgs("keys", "key-3", []byte("third")).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(mockUpdateRecord).WithArgs("keys", "key-1", []byte("changed"), int64(2)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	// The records written by the transaction replace the stored ones, in the order of their names, and the revisions
+	// read are the ones the writes are checked against.
+	//
+	values := map[string]string{}
+	var names []string
+	err := backend.Update(context.Background(), func(tx kms.StorageTransaction) error {
+		if err := tx.Put("keys", "key-3", []byte("third")); err != nil {
+			return err
+		}
+		if err := tx.Put("keys", "key-1", []byte("changed")); err != nil {
+			return err
+		}
+		return tx.ForEach("keys", func(name string, value []byte) error {
+			names = append(names, name)
+			values[name] = string(value)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+
+	if expected := []string{"key-1", "key-2", "key-3"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected records %v, got %v", expected, names)
+	}
+	if expected := map[string]string{"key-1": "changed", "key-2": "second", "key-3": "third"}; !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected values %v, got %v", expected, values)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expected queries were not run: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Package kmstest holds the fixtures shared by the tests of the KMS and of the APIs serving it.
+package kmstest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	BUFCONN_SIZE = 1024 * 1024
+)
+
+// Auditor - auditor keeping the recorded events in memory.
+type Auditor struct {
+	audit.Auditor
+
+	lock   sync.Mutex
+	events []audit.Event
+}
+
+func (a *Auditor) RecordEvent(event audit.Event) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.events = append(a.events, event)
+	return nil
+}
+
+// Events - returns a copy of the events recorded so far.
+func (a *Auditor) Events() []audit.Event {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return append([]audit.Event{}, a.events...)
+}
+
+// NewService - opens a KMS service backed by a file key store in a temporary directory of the test.
+func NewService(t testing.TB, auditor audit.Auditor) *kms.Service {
+	t.Helper()
+
+	return OpenService(t, kms.NewKeyStoreFile(t.TempDir(), auditor), auditor)
+}
+
+// OpenService - opens a KMS service backed by the key store, failing the test when it cannot.
+func OpenService(t testing.TB, keyStore kms.KeyStore, auditor audit.Auditor) *kms.Service {
+	t.Helper()
+
+	service := kms.NewService(keyStore, auditor)
+	if err := service.Open(); err != nil {
+		t.Fatalf("Failed to open service: %v", err)
+	}
+	return service
+}
+
+// ServeGRPC - serves a gRPC server in process through an in-memory listener until the end of the test, and returns a
+// client connection to it.
+func ServeGRPC(t testing.TB, serveListener func(ctx context.Context, listener net.Listener) error) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(BUFCONN_SIZE)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- serveListener(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
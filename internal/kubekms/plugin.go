@@ -0,0 +1,82 @@
+// Package kubekms serves a KMS key as a Kubernetes KMS v2 encryption provider, letting kube-apiserver encrypt
+// resources at rest with it.
+package kubekms
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"k8s.io/kms/pkg/service"
+)
+
+const (
+	API_VERSION = "v2"
+	HEALTHZ_OK  = "ok"
+
+	CALLER_IDENTITY = "kubernetes:apiserver"
+)
+
+var (
+	// encryptionContext - binds the ciphertexts to the provider, so they cannot be decrypted through the other APIs
+	// without it.
+	encryptionContext = map[string]string{"provider": "kubernetes-kms-v2"}
+
+	_ service.Service = (*Plugin)(nil)
+)
+
+// Plugin - encrypts and decrypts the data encryption keys of kube-apiserver with a single KMS key.
+type Plugin struct {
+	kmsService *kms.Service
+	keyID      string
+}
+
+func NewPlugin(kmsService *kms.Service, keyID string) *Plugin {
+	return &Plugin{
+		kmsService: kmsService,
+		keyID:      keyID,
+	}
+}
+
+// Status - reports the key ID of the key's primary version. It changes when the key rotates, which makes
+// kube-apiserver generate a new data encryption key and re-encrypt resources with the new version.
+func (p *Plugin) Status(ctx context.Context) (*service.StatusResponse, error) {
+	key, err := p.kmsService.KeyStore().GetKey(ctx, p.keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	healthz := HEALTHZ_OK
+	if key.State != kms.KEY_STATE_ENABLED {
+		healthz = fmt.Sprintf("key %s is %s", key.ID, key.State)
+	}
+
+	return &service.StatusResponse{
+		Version: API_VERSION,
+		Healthz: healthz,
+		KeyID:   KeyID(key.ID, key.PrimaryVersion),
+	}, nil
+}
+
+// Encrypt - encrypts a data encryption key with the primary version of the key.
+func (p *Plugin) Encrypt(ctx context.Context, uid string, data []byte) (*service.EncryptResponse, error) {
+	ciphertext, err := p.kmsService.EncryptPlaintext(ctx, p.keyID, data, encryptionContext)
+	if err != nil {
+		return nil, err
+	}
+	return &service.EncryptResponse{
+		Ciphertext: ciphertext.Ciphertext,
+		KeyID:      KeyID(ciphertext.KeyID, ciphertext.KeyVersion),
+	}, nil
+}
+
+// Decrypt - decrypts a data encryption key. The key version is read from the ciphertext, so data encrypted before a
+// rotation stays readable.
+func (p *Plugin) Decrypt(ctx context.Context, uid string, request *service.DecryptRequest) ([]byte, error) {
+	return p.kmsService.Decrypt(ctx, request.Ciphertext, encryptionContext)
+}
+
+// KeyID - key ID reported to kube-apiserver for a version of a key.
+func KeyID(keyID string, version int) string {
+	return fmt.Sprintf("%s:v%d", keyID, version)
+}
@@ -0,0 +1,102 @@
+package kubekms_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+	"github.com/hyperplane-sh/openkms/internal/kubekms"
+	kmsapi "k8s.io/kms/apis/v2"
+)
+
+// newClient - serves the plugin for a new key in process and returns a KMS v2 client connected to it, as
+// kube-apiserver would be.
+func newClient(t *testing.T) (kmsapi.KeyManagementServiceClient, *kms.Service, kms.Key) {
+	t.Helper()
+
+	service := kmstest.NewService(t, nil)
+	key, err := service.KeyStore().CreateKey(context.Background(), kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	server := kubekms.NewServer("", kubekms.NewPlugin(service, key.ID), nil, "TEST")
+	conn := kmstest.ServeGRPC(t, server.ServeListener)
+
+	return kmsapi.NewKeyManagementServiceClient(conn), service, key
+}
+
+func TestPlugin_EncryptDecrypt(t *testing.T) {
+	client, _, key := newClient(t)
+	ctx := context.Background()
+
+	statusResponse, err := client.Status(ctx, &kmsapi.StatusRequest{})
+	if err != nil {
+		t.Fatalf("Expected status to succeed, got %v", err)
+	}
+	if statusResponse.Version != kubekms.API_VERSION || statusResponse.Healthz != kubekms.HEALTHZ_OK || statusResponse.KeyId != kubekms.KeyID(key.ID, 1) {
+		t.Errorf("Unexpected status %+v", statusResponse)
+	}
+
+	encrypted, err := client.Encrypt(ctx, &kmsapi.EncryptRequest{Uid: "uid-1", Plaintext: []byte("data encryption key")})
+	if err != nil {
+		t.Fatalf("Expected encryption to succeed, got %v", err)
+	}
+	if encrypted.KeyId != statusResponse.KeyId {
+		t.Errorf("Expected key ID %q, got %q", statusResponse.KeyId, encrypted.KeyId)
+	}
+
+	decrypted, err := client.Decrypt(ctx, &kmsapi.DecryptRequest{Uid: "uid-2", Ciphertext: encrypted.Ciphertext, KeyId: encrypted.KeyId})
+	if err != nil || string(decrypted.Plaintext) != "data encryption key" {
+		t.Errorf("Expected decryption to succeed, got %q %v", decrypted.GetPlaintext(), err)
+	}
+}
+
+func TestPlugin_Rotation(t *testing.T) {
+	client, service, key := newClient(t)
+	ctx := context.Background()
+
+	before, err := client.Encrypt(ctx, &kmsapi.EncryptRequest{Uid: "uid-1", Plaintext: []byte("before")})
+	if err != nil {
+		t.Fatalf("Expected encryption to succeed, got %v", err)
+	}
+
+	if _, err := service.KeyStore().RotateKey(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+
+	// The reported key ID changes so kube-apiserver re-encrypts, and data from before the rotation stays readable.
+	//
+	statusResponse, err := client.Status(ctx, &kmsapi.StatusRequest{})
+	if err != nil || statusResponse.KeyId != kubekms.KeyID(key.ID, 2) {
+		t.Errorf("Expected key ID %q after rotation, got %+v %v", kubekms.KeyID(key.ID, 2), statusResponse, err)
+	}
+
+	after, err := client.Encrypt(ctx, &kmsapi.EncryptRequest{Uid: "uid-2", Plaintext: []byte("after")})
+	if err != nil || after.KeyId != statusResponse.GetKeyId() {
+		t.Errorf("Expected encryption with key ID %q, got %+v %v", statusResponse.GetKeyId(), after, err)
+	}
+
+	decrypted, err := client.Decrypt(ctx, &kmsapi.DecryptRequest{Uid: "uid-3", Ciphertext: before.Ciphertext, KeyId: before.KeyId})
+	if err != nil || string(decrypted.Plaintext) != "before" {
+		t.Errorf("Expected data from before the rotation to decrypt, got %q %v", decrypted.GetPlaintext(), err)
+	}
+}
+
+func TestPlugin_DisabledKey(t *testing.T) {
+	client, service, key := newClient(t)
+	ctx := context.Background()
+
+	if _, err := service.KeyStore().DisableKey(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to disable key: %v", err)
+	}
+
+	statusResponse, err := client.Status(ctx, &kmsapi.StatusRequest{})
+	if err != nil || statusResponse.Healthz == kubekms.HEALTHZ_OK {
+		t.Errorf("Expected an unhealthy status, got %+v %v", statusResponse, err)
+	}
+	if _, err := client.Encrypt(ctx, &kmsapi.EncryptRequest{Uid: "uid-1", Plaintext: []byte("x")}); err == nil {
+		t.Errorf("Expected encryption with a disabled key to fail")
+	}
+}
@@ -0,0 +1,108 @@
+package kubekms
+
+import (
+	"context"
+	"net"
+	"os"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/grpcapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/unixsocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kmsapi "k8s.io/kms/apis/v2"
+	"k8s.io/kms/pkg/service"
+)
+
+const (
+	CONNECTION_TIMEOUT = 10 * time.Second
+	SHUTDOWN_TIMEOUT   = 10 * time.Second
+)
+
+// Server - serves the plugin over a Unix domain socket, as kube-apiserver expects from KMS v2 providers.
+type Server struct {
+	socketPath string
+	auditor    audit.Auditor
+	auditGroup string
+	grpcServer *grpc.Server
+}
+
+func NewServer(socketPath string, plugin *Plugin, auditor audit.Auditor, auditGroup string) *Server {
+	s := &Server{
+		socketPath: socketPath,
+		auditor:    auditor,
+		auditGroup: auditGroup,
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.ConnectionTimeout(CONNECTION_TIMEOUT),
+		grpc.UnaryInterceptor(s.serveRequest),
+	)
+	kmsapi.RegisterKeyManagementServiceServer(s.grpcServer, service.NewGRPCService(socketPath, CONNECTION_TIMEOUT, plugin))
+
+	return s
+}
+
+// Serve - listens on the socket until the context is done.
+func (s *Server) Serve(ctx context.Context) error {
+	listener, err := unixsocket.Listen(s.socketPath, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(s.socketPath)
+
+	return s.ServeListener(ctx, listener)
+}
+
+// ServeListener - serves the connections accepted by the listener until the context is done, then lets the calls in
+// flight finish for up to the shutdown timeout.
+func (s *Server) ServeListener(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		timer := time.AfterFunc(SHUTDOWN_TIMEOUT, s.grpcServer.Stop)
+		s.grpcServer.GracefulStop()
+		timer.Stop()
+	}()
+
+	err := s.grpcServer.Serve(listener)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// serveRequest - runs a call on behalf of kube-apiserver, under the UID it assigned to the request, and records its
+// outcome.
+func (s *Server) serveRequest(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	uid := ""
+	if withUID, ok := request.(interface{ GetUid() string }); ok {
+		uid = withUID.GetUid()
+	}
+	requestID := kms.ClientRequestID(uid)
+	ctx = kms.WithRequestID(kms.WithCallerIdentity(ctx, CALLER_IDENTITY), requestID)
+
+	labels := map[string]string{
+		"requestId": requestID,
+		"method":    info.FullMethod,
+	}
+
+	response, err := handler(ctx, request)
+	if err != nil {
+		labels["error"] = err.Error()
+		err = grpcapi.StatusError(err)
+	}
+
+	level := audit.LEVEL_INFO
+	if status.Code(err) != codes.OK {
+		level = audit.LEVEL_WARN
+	}
+	labels["status"] = status.Code(err).String()
+
+	if s.auditor != nil {
+		s.auditor.RecordEvent(audit.NewEvent(level, s.auditGroup, audit.TOPIC_KUBERNETES_KMS, "Kubernetes KMS request handled", labels))
+	}
+	return response, err
+}
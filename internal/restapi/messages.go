@@ -0,0 +1,198 @@
+package restapi
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+// ErrorResponse - body of every failed request.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+}
+
+type KeyCreateRequest struct {
+	Spec               string            `json:"spec"`
+	Usage              string            `json:"usage"`
+	Provider           string            `json:"provider"`
+	Description        string            `json:"description"`
+	Tags               map[string]string `json:"tags"`
+	RotationPeriodDays int               `json:"rotationPeriodDays"`
+}
+
+type KeyUpdateRequest struct {
+	Description        *string           `json:"description"`
+	Tags               map[string]string `json:"tags"`
+	RotationPeriodDays *int              `json:"rotationPeriodDays"`
+}
+
+// KeyResponse - key as exposed over the API, never carrying key material.
+type KeyResponse struct {
+	ID                 string            `json:"id"`
+	Spec               string            `json:"spec"`
+	Usage              string            `json:"usage"`
+	Provider           string            `json:"provider"`
+	State              string            `json:"state"`
+	Description        string            `json:"description"`
+	Tags               map[string]string `json:"tags"`
+	PrimaryVersion     int               `json:"primaryVersion"`
+	Versions           []int             `json:"versions"`
+	RotationPeriodDays int               `json:"rotationPeriodDays"`
+	NextRotationAt     *time.Time        `json:"nextRotationAt,omitempty"`
+	DeletionDate       *time.Time        `json:"deletionDate,omitempty"`
+	CreatedAt          time.Time         `json:"createdAt"`
+	UpdatedAt          time.Time         `json:"updatedAt"`
+}
+
+type KeyListResponse struct {
+	Keys []KeyResponse `json:"keys"`
+}
+
+type PublicKeyResponse struct {
+	KeyID      string          `json:"keyId"`
+	KeyVersion int             `json:"keyVersion"`
+	Spec       string          `json:"spec"`
+	Usage      string          `json:"usage"`
+	PEM        string          `json:"pem"`
+	JWK        json.RawMessage `json:"jwk"`
+}
+
+type EncryptRequest struct {
+	KeyID             string            `json:"keyId"`
+	Plaintext         []byte            `json:"plaintext"`
+	EncryptionContext map[string]string `json:"encryptionContext"`
+}
+
+type EncryptResponse struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type DecryptRequest struct {
+	Ciphertext        []byte            `json:"ciphertext"`
+	EncryptionContext map[string]string `json:"encryptionContext"`
+}
+
+type DecryptResponse struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Plaintext  []byte `json:"plaintext"`
+}
+
+type GenerateDataKeyRequest struct {
+	KeyID             string            `json:"keyId"`
+	Spec              string            `json:"spec"`
+	EncryptionContext map[string]string `json:"encryptionContext"`
+	WithoutPlaintext  bool              `json:"withoutPlaintext"`
+}
+
+type GenerateDataKeyResponse struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Plaintext  []byte `json:"plaintext,omitempty"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type SignRequest struct {
+	KeyID     string `json:"keyId"`
+	Digest    []byte `json:"digest"`
+	Algorithm string `json:"algorithm"`
+}
+
+type SignResponse struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Algorithm  string `json:"algorithm"`
+	Signature  []byte `json:"signature"`
+}
+
+type VerifyRequest struct {
+	KeyID     string `json:"keyId"`
+	Digest    []byte `json:"digest"`
+	Signature []byte `json:"signature"`
+	Algorithm string `json:"algorithm"`
+}
+
+type VerifyResponse struct {
+	Valid bool `json:"valid"`
+}
+
+type GenerateMacRequest struct {
+	KeyID     string `json:"keyId"`
+	Message   []byte `json:"message"`
+	Algorithm string `json:"algorithm"`
+}
+
+type GenerateMacResponse struct {
+	KeyID      string `json:"keyId"`
+	KeyVersion int    `json:"keyVersion"`
+	Algorithm  string `json:"algorithm"`
+	Mac        []byte `json:"mac"`
+}
+
+type VerifyMacRequest struct {
+	KeyID     string `json:"keyId"`
+	Message   []byte `json:"message"`
+	Mac       []byte `json:"mac"`
+	Algorithm string `json:"algorithm"`
+}
+
+type VerifyMacResponse struct {
+	Valid bool `json:"valid"`
+}
+
+type AsymmetricEncryptRequest struct {
+	KeyID     string `json:"keyId"`
+	Plaintext []byte `json:"plaintext"`
+	Algorithm string `json:"algorithm"`
+}
+
+type AsymmetricEncryptResponse struct {
+	KeyID      string `json:"keyId"`
+	Algorithm  string `json:"algorithm"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type AsymmetricDecryptRequest struct {
+	KeyID      string `json:"keyId"`
+	Ciphertext []byte `json:"ciphertext"`
+	Algorithm  string `json:"algorithm"`
+}
+
+type AsymmetricDecryptResponse struct {
+	KeyID     string `json:"keyId"`
+	Plaintext []byte `json:"plaintext"`
+}
+
+// newKeyResponse - converts a key into its API representation, leaving the key material out.
+func newKeyResponse(key kms.Key) KeyResponse {
+	versions := make([]int, 0, len(key.Versions))
+	for _, version := range key.Versions {
+		versions = append(versions, version.Version)
+	}
+
+	return KeyResponse{
+		ID:                 key.ID,
+		Spec:               key.Spec,
+		Usage:              key.Usage,
+		Provider:           key.Provider,
+		State:              key.State,
+		Description:        key.Metadata.Description,
+		Tags:               key.Metadata.Tags,
+		PrimaryVersion:     key.PrimaryVersion,
+		Versions:           versions,
+		RotationPeriodDays: int(key.RotationPeriod / (24 * time.Hour)),
+		NextRotationAt:     key.NextRotationAt,
+		DeletionDate:       key.DeletionDate,
+		CreatedAt:          key.CreatedAt,
+		UpdatedAt:          key.UpdatedAt,
+	}
+}
@@ -0,0 +1,412 @@
+package restapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/httpapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+// Key IDs are path parameters; aliases must have their slashes escaped, e.g. /v1/keys/alias%2Fbackups.
+const (
+	HEADER_REQUEST_ID = "X-Request-Id"
+
+	ERROR_CODE_PERMISSION_DENIED = "PERMISSION_DENIED"
+)
+
+var (
+	// httpStatuses - HTTP status answered for every KMS error code.
+	httpStatuses = map[string]int{
+		kms.ERROR_CODE_NOT_FOUND:           fiber.StatusNotFound,
+		kms.ERROR_CODE_ALREADY_EXISTS:      fiber.StatusConflict,
+		kms.ERROR_CODE_INVALID_ARGUMENT:    fiber.StatusBadRequest,
+		kms.ERROR_CODE_FAILED_PRECONDITION: fiber.StatusBadRequest,
+		kms.ERROR_CODE_ABORTED:             fiber.StatusConflict,
+		kms.ERROR_CODE_INTERNAL:            fiber.StatusInternalServerError,
+	}
+)
+
+// Server - serves the KMS as a JSON REST API.
+type Server struct {
+	kmsService *kms.Service
+	app        *fiber.App
+}
+
+func NewServer(kmsService *kms.Service, auditor audit.Auditor, auditGroup string, identities *tlsconfig.IdentityMapper) *Server {
+	s := &Server{
+		kmsService: kmsService,
+	}
+
+	middleware := httpapi.Middleware{
+		RequestIDHeader: HEADER_REQUEST_ID,
+		Identities:      identities,
+		HandleError:     s.handleError,
+		Auditing:        httpapi.Auditing{Auditor: auditor, Group: auditGroup, Topic: audit.TOPIC_REST_API, Message: "REST API request handled"},
+	}
+	s.app = httpapi.NewApp(s.handleError)
+	s.app.Use(middleware.ServeRequest)
+
+	v1 := s.app.Group("/v1")
+	v1.Post("/keys", s.createKey)
+	v1.Get("/keys", s.listKeys)
+	v1.Get("/keys/:keyId", s.describeKey)
+	v1.Patch("/keys/:keyId", s.updateKey)
+	v1.Delete("/keys/:keyId", s.scheduleKeyDeletion)
+	v1.Post("/keys/:keyId/cancel-deletion", s.keyOperation(kms.KeyStore.CancelKeyDeletion))
+	v1.Post("/keys/:keyId/enable", s.keyOperation(kms.KeyStore.EnableKey))
+	v1.Post("/keys/:keyId/disable", s.keyOperation(kms.KeyStore.DisableKey))
+	v1.Post("/keys/:keyId/rotate", s.keyOperation(kms.KeyStore.RotateKey))
+	v1.Get("/keys/:keyId/public-key", s.getPublicKey)
+	v1.Post("/encrypt", s.encrypt)
+	v1.Post("/decrypt", s.decrypt)
+	v1.Post("/generate-data-key", s.generateDataKey)
+	v1.Post("/sign", s.sign)
+	v1.Post("/verify", s.verify)
+	v1.Post("/generate-mac", s.generateMac)
+	v1.Post("/verify-mac", s.verifyMac)
+	v1.Post("/asymmetric-encrypt", s.asymmetricEncrypt)
+	v1.Post("/asymmetric-decrypt", s.asymmetricDecrypt)
+
+	return s
+}
+
+// App - Fiber application serving the API.
+func (s *Server) App() *fiber.App {
+	return s.app
+}
+
+// Serve - listens on the address, over TLS when a configuration is given, until the context is done.
+func (s *Server) Serve(ctx context.Context, address string, tlsConfig *tls.Config) error {
+	return httpapi.Serve(ctx, s.app, address, tlsConfig)
+}
+
+// ServeListener - serves the connections accepted by the listener until the context is done.
+func (s *Server) ServeListener(ctx context.Context, listener net.Listener) error {
+	return httpapi.ServeListener(ctx, s.app, listener)
+}
+
+// handleError - answers an error with the error body shared by every endpoint.
+func (s *Server) handleError(c *fiber.Ctx, err error) error {
+	code := kms.ErrorCode(err)
+	status, message := httpapi.ErrorStatus(err, httpStatuses)
+
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		switch {
+		case status == fiber.StatusNotFound:
+			code = kms.ERROR_CODE_NOT_FOUND
+		case status == fiber.StatusForbidden:
+			code = ERROR_CODE_PERMISSION_DENIED
+		case status < fiber.StatusInternalServerError:
+			code = kms.ERROR_CODE_INVALID_ARGUMENT
+		}
+	}
+
+	requestID, _ := c.Locals(HEADER_REQUEST_ID).(string)
+	return c.Status(status).JSON(ErrorResponse{
+		Error: ErrorBody{
+			Code:      code,
+			Message:   message,
+			RequestID: requestID,
+		},
+	})
+}
+
+func (s *Server) createKey(c *fiber.Ctx) error {
+	var request KeyCreateRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.KeyStore().CreateKey(c.UserContext(), kms.CreateKeyOptions{
+		Spec:           request.Spec,
+		Usage:          request.Usage,
+		Provider:       request.Provider,
+		Metadata:       kms.KeyMetadata{Description: request.Description, Tags: request.Tags},
+		RotationPeriod: days(request.RotationPeriodDays),
+	})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(newKeyResponse(key))
+}
+
+func (s *Server) listKeys(c *fiber.Ctx) error {
+	keys, err := s.kmsService.KeyStore().ListKeys(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	response := KeyListResponse{Keys: make([]KeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, newKeyResponse(key))
+	}
+	return c.JSON(response)
+}
+
+func (s *Server) describeKey(c *fiber.Ctx) error {
+	keyID, err := keyIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), keyID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(newKeyResponse(key))
+}
+
+// updateKey - updates the fields present in the request, leaving the others unchanged.
+func (s *Server) updateKey(c *fiber.Ctx) error {
+	keyID, err := keyIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	var request KeyUpdateRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	options := kms.UpdateKeyOptions{Description: request.Description, Tags: request.Tags}
+	if request.RotationPeriodDays != nil {
+		rotationPeriod := days(*request.RotationPeriodDays)
+		options.RotationPeriod = &rotationPeriod
+	}
+
+	key, err := s.kmsService.UpdateKey(c.UserContext(), keyID, options)
+	if err != nil {
+		return err
+	}
+	return c.JSON(newKeyResponse(key))
+}
+
+// scheduleKeyDeletion - keys are never deleted right away, only scheduled for destruction.
+func (s *Server) scheduleKeyDeletion(c *fiber.Ctx) error {
+	keyID, err := keyIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.ScheduleKeyDeletion(c.UserContext(), keyID, days(c.QueryInt("pendingWindowDays", 0)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(newKeyResponse(key))
+}
+
+// keyOperation - handler applying a key store operation to the key in the path.
+func (s *Server) keyOperation(operation func(keyStore kms.KeyStore, ctx context.Context, keyID string) (kms.Key, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		keyID, err := keyIDParam(c)
+		if err != nil {
+			return err
+		}
+
+		key, err := operation(s.kmsService.KeyStore(), c.UserContext(), keyID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(newKeyResponse(key))
+	}
+}
+
+func (s *Server) getPublicKey(c *fiber.Ctx) error {
+	keyID, err := keyIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := s.kmsService.GetPublicKey(c.UserContext(), keyID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(PublicKeyResponse{
+		KeyID:      publicKey.KeyID,
+		KeyVersion: publicKey.KeyVersion,
+		Spec:       publicKey.Spec,
+		Usage:      publicKey.Usage,
+		PEM:        string(publicKey.PEM),
+		JWK:        publicKey.JWK,
+	})
+}
+
+func (s *Server) encrypt(c *fiber.Ctx) error {
+	var request EncryptRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	ciphertext, err := s.kmsService.EncryptPlaintext(c.UserContext(), request.KeyID, request.Plaintext, request.EncryptionContext)
+	if err != nil {
+		return err
+	}
+	return c.JSON(EncryptResponse{KeyID: ciphertext.KeyID, KeyVersion: ciphertext.KeyVersion, Ciphertext: ciphertext.Ciphertext})
+}
+
+func (s *Server) decrypt(c *fiber.Ctx) error {
+	var request DecryptRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	plaintext, err := s.kmsService.DecryptCiphertext(c.UserContext(), request.Ciphertext, request.EncryptionContext)
+	if err != nil {
+		return err
+	}
+	return c.JSON(DecryptResponse{KeyID: plaintext.KeyID, KeyVersion: plaintext.KeyVersion, Plaintext: plaintext.Plaintext})
+}
+
+func (s *Server) generateDataKey(c *fiber.Ctx) error {
+	var request GenerateDataKeyRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	generate := s.kmsService.GenerateDataKey
+	if request.WithoutPlaintext {
+		generate = s.kmsService.GenerateDataKeyWithoutPlaintext
+	}
+	dataKey, err := generate(c.UserContext(), request.KeyID, request.Spec, request.EncryptionContext)
+	if err != nil {
+		return err
+	}
+	return c.JSON(GenerateDataKeyResponse{
+		KeyID:      dataKey.KeyID,
+		KeyVersion: dataKey.KeyVersion,
+		Plaintext:  dataKey.Plaintext,
+		Ciphertext: dataKey.Ciphertext,
+	})
+}
+
+func (s *Server) sign(c *fiber.Ctx) error {
+	var request SignRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	signature, err := s.kmsService.Sign(c.UserContext(), request.KeyID, request.Digest, request.Algorithm)
+	if err != nil {
+		return err
+	}
+	return c.JSON(SignResponse{
+		KeyID:      signature.KeyID,
+		KeyVersion: signature.KeyVersion,
+		Algorithm:  signature.Algorithm,
+		Signature:  signature.Signature,
+	})
+}
+
+func (s *Server) verify(c *fiber.Ctx) error {
+	var request VerifyRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	valid, err := s.kmsService.Verify(c.UserContext(), request.KeyID, request.Digest, request.Signature, request.Algorithm)
+	if err != nil {
+		return err
+	}
+	return c.JSON(VerifyResponse{Valid: valid})
+}
+
+func (s *Server) generateMac(c *fiber.Ctx) error {
+	var request GenerateMacRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	mac, err := s.kmsService.GenerateMac(c.UserContext(), request.KeyID, request.Message, request.Algorithm)
+	if err != nil {
+		return err
+	}
+	return c.JSON(GenerateMacResponse{
+		KeyID:      mac.KeyID,
+		KeyVersion: mac.KeyVersion,
+		Algorithm:  mac.Algorithm,
+		Mac:        mac.Mac,
+	})
+}
+
+func (s *Server) verifyMac(c *fiber.Ctx) error {
+	var request VerifyMacRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	valid, err := s.kmsService.VerifyMac(c.UserContext(), request.KeyID, request.Message, request.Mac, request.Algorithm)
+	if err != nil {
+		return err
+	}
+	return c.JSON(VerifyMacResponse{Valid: valid})
+}
+
+// asymmetricEncrypt - encrypts with the public key of an RSA key, answering the ID of the key rather than the alias
+// the request may name it by.
+func (s *Server) asymmetricEncrypt(c *fiber.Ctx) error {
+	var request AsymmetricEncryptRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), request.KeyID)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := s.kmsService.AsymmetricEncrypt(c.UserContext(), key.ID, request.Plaintext, request.Algorithm)
+	if err != nil {
+		return err
+	}
+	return c.JSON(AsymmetricEncryptResponse{KeyID: key.ID, Algorithm: request.Algorithm, Ciphertext: ciphertext})
+}
+
+func (s *Server) asymmetricDecrypt(c *fiber.Ctx) error {
+	var request AsymmetricDecryptRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), request.KeyID)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := s.kmsService.AsymmetricDecrypt(c.UserContext(), key.ID, request.Ciphertext, request.Algorithm)
+	if err != nil {
+		return err
+	}
+	return c.JSON(AsymmetricDecryptResponse{KeyID: key.ID, Plaintext: plaintext})
+}
+
+// parseBody - decodes the JSON body of a request.
+func parseBody(c *fiber.Ctx, request any) error {
+	if err := json.Unmarshal(c.Body(), request); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "malformed request body: "+err.Error())
+	}
+	return nil
+}
+
+// keyIDParam - returns the key ID or alias in the path, unescaping the slashes of aliases.
+func keyIDParam(c *fiber.Ctx) (string, error) {
+	keyID, err := url.PathUnescape(c.Params("keyId"))
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "malformed key ID")
+	}
+	return keyID, nil
+}
+
+// days - converts a number of days into a duration.
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
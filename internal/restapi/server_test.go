@@ -0,0 +1,424 @@
+package restapi_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+	"github.com/hyperplane-sh/openkms/internal/restapi"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+// newServer - REST API backed by a file key store in a temporary directory.
+func newServer(t *testing.T, auditor audit.Auditor) *restapi.Server {
+	t.Helper()
+
+	return restapi.NewServer(kmstest.NewService(t, auditor), auditor, "TEST", nil)
+}
+
+// call - sends a request to the API and decodes the response body.
+func call(t *testing.T, server *restapi.Server, method, path string, body any, headers map[string]string, response any) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	request := httptest.NewRequest(method, path, reader)
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	httpResponse, err := server.App().Test(request, -1)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	if response != nil {
+		if err := json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return httpResponse
+}
+
+func TestServer_KeysAndCryptography(t *testing.T) {
+	server := newServer(t, nil)
+
+	var key restapi.KeyResponse
+	response := call(t, server, http.MethodPost, "/v1/keys", restapi.KeyCreateRequest{Description: "payments"}, nil, &key)
+	if response.StatusCode != http.StatusCreated || key.ID == "" || key.State != kms.KEY_STATE_ENABLED {
+		t.Fatalf("Expected key to be created, got %d %+v", response.StatusCode, key)
+	}
+
+	var encrypted restapi.EncryptResponse
+	response = call(t, server, http.MethodPost, "/v1/encrypt", restapi.EncryptRequest{
+		KeyID:             key.ID,
+		Plaintext:         []byte("card number"),
+		EncryptionContext: map[string]string{"tenant": "acme"},
+	}, nil, &encrypted)
+	if response.StatusCode != http.StatusOK || encrypted.KeyID != key.ID || encrypted.KeyVersion != 1 {
+		t.Fatalf("Expected encryption to succeed, got %d %+v", response.StatusCode, encrypted)
+	}
+
+	var decrypted restapi.DecryptResponse
+	response = call(t, server, http.MethodPost, "/v1/decrypt", restapi.DecryptRequest{
+		Ciphertext:        encrypted.Ciphertext,
+		EncryptionContext: map[string]string{"tenant": "acme"},
+	}, nil, &decrypted)
+	if response.StatusCode != http.StatusOK || string(decrypted.Plaintext) != "card number" {
+		t.Errorf("Expected decryption to succeed, got %d %+v", response.StatusCode, decrypted)
+	}
+
+	description := "payments v2"
+	var updated restapi.KeyResponse
+	call(t, server, http.MethodPatch, "/v1/keys/"+key.ID, restapi.KeyUpdateRequest{Description: &description}, nil, &updated)
+	if updated.Description != description {
+		t.Errorf("Expected description %q, got %q", description, updated.Description)
+	}
+
+	var rotated restapi.KeyResponse
+	call(t, server, http.MethodPost, "/v1/keys/"+key.ID+"/rotate", nil, nil, &rotated)
+	if rotated.PrimaryVersion != 2 {
+		t.Errorf("Expected primary version 2, got %d", rotated.PrimaryVersion)
+	}
+
+	var disabled restapi.KeyResponse
+	call(t, server, http.MethodPost, "/v1/keys/"+key.ID+"/disable", nil, nil, &disabled)
+	if disabled.State != kms.KEY_STATE_DISABLED {
+		t.Errorf("Expected state %s, got %s", kms.KEY_STATE_DISABLED, disabled.State)
+	}
+
+	var list restapi.KeyListResponse
+	call(t, server, http.MethodGet, "/v1/keys", nil, nil, &list)
+	if len(list.Keys) != 1 {
+		t.Errorf("Expected 1 key, got %d", len(list.Keys))
+	}
+}
+
+func TestServer_MacAndAsymmetricEncryption(t *testing.T) {
+	server := newServer(t, nil)
+
+	var macKey restapi.KeyResponse
+	call(t, server, http.MethodPost, "/v1/keys", restapi.KeyCreateRequest{Spec: kms.KEY_SPEC_HMAC_256, Usage: kms.KEY_USAGE_GENERATE_VERIFY_MAC}, nil, &macKey)
+
+	var mac restapi.GenerateMacResponse
+	response := call(t, server, http.MethodPost, "/v1/generate-mac", restapi.GenerateMacRequest{
+		KeyID:     macKey.ID,
+		Message:   []byte("invoice 12"),
+		Algorithm: kms.MAC_ALGORITHM_HMAC_SHA_256,
+	}, nil, &mac)
+	if response.StatusCode != http.StatusOK || mac.KeyID != macKey.ID || mac.KeyVersion != 1 || len(mac.Mac) == 0 {
+		t.Fatalf("Expected MAC to be generated, got %d %+v", response.StatusCode, mac)
+	}
+
+	for _, scenario := range []struct {
+		message string
+		valid   bool
+	}{
+		{message: "invoice 12", valid: true},
+		{message: "invoice 13"},
+	} {
+		var verified restapi.VerifyMacResponse
+		call(t, server, http.MethodPost, "/v1/verify-mac", restapi.VerifyMacRequest{
+			KeyID:     macKey.ID,
+			Message:   []byte(scenario.message),
+			Mac:       mac.Mac,
+			Algorithm: kms.MAC_ALGORITHM_HMAC_SHA_256,
+		}, nil, &verified)
+		if verified.Valid != scenario.valid {
+			t.Errorf("Expected MAC of %q valid to be %t, got %t", scenario.message, scenario.valid, verified.Valid)
+		}
+	}
+
+	var rsaKey restapi.KeyResponse
+	call(t, server, http.MethodPost, "/v1/keys", restapi.KeyCreateRequest{Spec: kms.KEY_SPEC_RSA_2048, Usage: kms.KEY_USAGE_ENCRYPT_DECRYPT}, nil, &rsaKey)
+
+	var encrypted restapi.AsymmetricEncryptResponse
+	response = call(t, server, http.MethodPost, "/v1/asymmetric-encrypt", restapi.AsymmetricEncryptRequest{
+		KeyID:     rsaKey.ID,
+		Plaintext: []byte("wrapped key"),
+		Algorithm: kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256,
+	}, nil, &encrypted)
+	if response.StatusCode != http.StatusOK || encrypted.KeyID != rsaKey.ID || len(encrypted.Ciphertext) == 0 {
+		t.Fatalf("Expected asymmetric encryption to succeed, got %d %+v", response.StatusCode, encrypted)
+	}
+
+	var decrypted restapi.AsymmetricDecryptResponse
+	response = call(t, server, http.MethodPost, "/v1/asymmetric-decrypt", restapi.AsymmetricDecryptRequest{
+		KeyID:      rsaKey.ID,
+		Ciphertext: encrypted.Ciphertext,
+		Algorithm:  kms.ENCRYPTION_ALGORITHM_RSAES_OAEP_SHA_256,
+	}, nil, &decrypted)
+	if response.StatusCode != http.StatusOK || string(decrypted.Plaintext) != "wrapped key" {
+		t.Errorf("Expected asymmetric decryption to succeed, got %d %+v", response.StatusCode, decrypted)
+	}
+
+	var body restapi.ErrorResponse
+	response = call(t, server, http.MethodPost, "/v1/generate-mac", restapi.GenerateMacRequest{
+		KeyID:     rsaKey.ID,
+		Message:   []byte("invoice 12"),
+		Algorithm: kms.MAC_ALGORITHM_HMAC_SHA_256,
+	}, nil, &body)
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a MAC with an RSA key, got %d %+v", http.StatusBadRequest, response.StatusCode, body.Error)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	server := newServer(t, nil)
+
+	var key restapi.KeyResponse
+	call(t, server, http.MethodPost, "/v1/keys", restapi.KeyCreateRequest{}, nil, &key)
+	call(t, server, http.MethodPost, "/v1/keys/"+key.ID+"/disable", nil, nil, nil)
+
+	scenarios := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+		code   string
+	}{
+		{
+			name:   "Unknown key",
+			method: http.MethodGet,
+			path:   "/v1/keys/00000000-0000-0000-0000-000000000000",
+			status: http.StatusNotFound,
+			code:   kms.ERROR_CODE_NOT_FOUND,
+		},
+		{
+			name:   "Unknown alias",
+			method: http.MethodGet,
+			path:   "/v1/keys/alias%2Fmissing",
+			status: http.StatusNotFound,
+			code:   kms.ERROR_CODE_NOT_FOUND,
+		},
+		{
+			name:   "Malformed body",
+			method: http.MethodPost,
+			path:   "/v1/encrypt",
+			body:   "not an object",
+			status: http.StatusBadRequest,
+			code:   kms.ERROR_CODE_INVALID_ARGUMENT,
+		},
+		{
+			name:   "Unsupported key spec",
+			method: http.MethodPost,
+			path:   "/v1/keys",
+			body:   restapi.KeyCreateRequest{Spec: "AES_1024"},
+			status: http.StatusBadRequest,
+			code:   kms.ERROR_CODE_INVALID_ARGUMENT,
+		},
+		{
+			name:   "Disabled key",
+			method: http.MethodPost,
+			path:   "/v1/encrypt",
+			body:   restapi.EncryptRequest{KeyID: key.ID, Plaintext: []byte("x")},
+			status: http.StatusBadRequest,
+			code:   kms.ERROR_CODE_FAILED_PRECONDITION,
+		},
+		{
+			name:   "Unknown route",
+			method: http.MethodGet,
+			path:   "/v1/unknown",
+			status: http.StatusNotFound,
+			code:   kms.ERROR_CODE_NOT_FOUND,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			var body restapi.ErrorResponse
+			response := call(t, server, scenario.method, scenario.path, scenario.body, map[string]string{restapi.HEADER_REQUEST_ID: "req-42"}, &body)
+			if response.StatusCode != scenario.status {
+				t.Errorf("Expected status %d, got %d", scenario.status, response.StatusCode)
+			}
+			if body.Error.Code != scenario.code || body.Error.Message == "" {
+				t.Errorf("Expected code %s, got %+v", scenario.code, body.Error)
+			}
+			if body.Error.RequestID != "req-42" || response.Header.Get(restapi.HEADER_REQUEST_ID) != "req-42" {
+				t.Errorf("Expected request ID to be echoed, got %q", body.Error.RequestID)
+			}
+		})
+	}
+}
+
+func TestServer_RequestIDInAuditLabels(t *testing.T) {
+	auditor := &kmstest.Auditor{}
+	server := newServer(t, auditor)
+
+	response := call(t, server, http.MethodPost, "/v1/keys", restapi.KeyCreateRequest{}, map[string]string{restapi.HEADER_REQUEST_ID: "trace-7"}, nil)
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected key to be created, got %d", response.StatusCode)
+	}
+
+	groups := map[string]bool{}
+	for _, event := range auditor.Events() {
+		if event.Labels["requestId"] != "trace-7" {
+			t.Errorf("Expected request ID label on %q, got %v", event.Message, event.Labels)
+		}
+		groups[event.Group] = true
+	}
+	if !groups[kms.KMS_AUDIT_GROUP] || !groups["TEST"] {
+		t.Errorf("Expected events from the KMS and the API, got %v", groups)
+	}
+
+	// Invalid request IDs are replaced rather than trusted.
+	//
+	response = call(t, server, http.MethodGet, "/v1/keys", nil, map[string]string{restapi.HEADER_REQUEST_ID: "bad id\n"}, nil)
+	if requestID := response.Header.Get(restapi.HEADER_REQUEST_ID); requestID == "" || requestID == "bad id\n" {
+		t.Errorf("Expected a generated request ID, got %q", requestID)
+	}
+
+	// Labels recorded for a request are not overwritten by the requests served after it.
+	//
+	for _, event := range auditor.Events() {
+		if event.Group == "TEST" {
+			if event.Labels["requestId"] != "trace-7" || event.Labels["method"] != http.MethodPost || event.Labels["path"] != "/v1/keys" {
+				t.Errorf("Expected labels of the first request, got %v", event.Labels)
+			}
+			break
+		}
+	}
+}
+
+// issueCertificate - creates a certificate from the template, self-signed when no parent is given.
+func issueCertificate(t *testing.T, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCertificate, parentKey := template, any(key)
+	if parent != nil {
+		parentCertificate, parentKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCertificate, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestServer_ClientCertificates(t *testing.T) {
+	ca := issueCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil)
+	serverCertificate := issueCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "openkms"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}, &ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCertificate},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    pool,
+	})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	auditor := &kmstest.Auditor{}
+	server := restapi.NewServer(kmstest.NewService(t, auditor), auditor, "TEST", tlsconfig.NewIdentityMapper(map[string]string{
+		"CN=billing,O=Hyperplane": "service:billing",
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- server.ServeListener(ctx, listener) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	scenarios := []struct {
+		name     string
+		subject  pkix.Name
+		status   int
+		identity string
+	}{
+		{
+			name:     "Mapped subject",
+			subject:  pkix.Name{CommonName: "billing", Organization: []string{"Hyperplane"}},
+			status:   http.StatusCreated,
+			identity: "service:billing",
+		},
+		{
+			name:     "Unmapped subject",
+			subject:  pkix.Name{CommonName: "intruder"},
+			status:   http.StatusForbidden,
+			identity: kms.CALLER_IDENTITY_ANONYMOUS,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			clientCertificate := issueCertificate(t, &x509.Certificate{
+				SerialNumber: big.NewInt(int64(10 + i)),
+				Subject:      scenario.subject,
+				ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			}, &ca)
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      pool,
+				Certificates: []tls.Certificate{clientCertificate},
+			}}}
+
+			response, err := client.Post("https://"+listener.Addr().String()+"/v1/keys", "application/json", bytes.NewReader([]byte("{}")))
+			if err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != scenario.status {
+				t.Errorf("Expected status %d, got %d", scenario.status, response.StatusCode)
+			}
+			if scenario.status == http.StatusForbidden {
+				var body restapi.ErrorResponse
+				json.NewDecoder(response.Body).Decode(&body)
+				if body.Error.Code != restapi.ERROR_CODE_PERMISSION_DENIED {
+					t.Errorf("Expected code %s, got %+v", restapi.ERROR_CODE_PERMISSION_DENIED, body.Error)
+				}
+			}
+
+			events := auditor.Events()
+			event := events[len(events)-1]
+			if event.Labels["callerIdentity"] != scenario.identity || event.Labels["clientSubject"] != scenario.subject.String() {
+				t.Errorf("Expected identity %q and subject %q, got %v", scenario.identity, scenario.subject.String(), event.Labels)
+			}
+		})
+	}
+}
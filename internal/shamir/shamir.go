@@ -0,0 +1,146 @@
+// Package shamir splits a secret into shares with Shamir's secret sharing over GF(2^8), any threshold of which
+// reconstructs the secret while fewer reveal nothing about it.
+//
+// Every byte of the secret is the constant term of its own random polynomial of degree threshold - 1. A share holds
+// the value of every polynomial at the share's x coordinate, followed by that coordinate in its last byte.
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+const (
+	MAX_SHARES = 255
+)
+
+var (
+	ErrInvalidParameters = errors.New("shares must be between the threshold and 255, and the threshold at least 1")
+	ErrEmptySecret       = errors.New("secret is empty")
+	ErrInvalidShares     = errors.New("shares are malformed, duplicated or of different lengths")
+)
+
+// Split - splits the secret into the given number of shares, any threshold of which reconstruct it.
+func Split(secret []byte, shares, threshold int) ([][]byte, error) {
+	if threshold < 1 || shares < threshold || shares > MAX_SHARES {
+		return nil, ErrInvalidParameters
+	}
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+
+	// Coefficients of every polynomial, the constant term being the secret byte.
+	//
+	coefficients := make([]byte, threshold*len(secret))
+	defer clear(coefficients)
+	if _, err := rand.Read(coefficients); err != nil {
+		return nil, err
+	}
+
+	parts := make([][]byte, shares)
+	for i := range parts {
+		x := byte(i + 1)
+		part := make([]byte, len(secret)+1)
+		for j, secretByte := range secret {
+			polynomial := coefficients[j*threshold : (j+1)*threshold]
+			polynomial[0] = secretByte
+			part[j] = evaluate(polynomial, x)
+		}
+		part[len(secret)] = x
+		parts[i] = part
+	}
+	return parts, nil
+}
+
+// Combine - reconstructs the secret from at least a threshold of its shares. Fewer shares, or shares of another
+// secret, reconstruct a wrong secret without error; callers must verify the result.
+func Combine(parts [][]byte) ([]byte, error) {
+	if len(parts) == 0 || len(parts[0]) < 2 {
+		return nil, ErrInvalidShares
+	}
+
+	length := len(parts[0])
+	xs := make([]byte, len(parts))
+	seen := map[byte]bool{}
+	for i, part := range parts {
+		if len(part) != length {
+			return nil, ErrInvalidShares
+		}
+		x := part[length-1]
+		if x == 0 || seen[x] {
+			return nil, fmt.Errorf("%w: duplicated share", ErrInvalidShares)
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, length-1)
+	ys := make([]byte, len(parts))
+	for j := range secret {
+		for i, part := range parts {
+			ys[i] = part[j]
+		}
+		secret[j] = interpolateAtZero(xs, ys)
+	}
+	return secret, nil
+}
+
+// evaluate - evaluates the polynomial at x with Horner's method.
+func evaluate(polynomial []byte, x byte) byte {
+	result := byte(0)
+	for i := len(polynomial) - 1; i >= 0; i-- {
+		result = add(multiply(result, x), polynomial[i])
+	}
+	return result
+}
+
+// interpolateAtZero - value at zero of the polynomial going through the points, by Lagrange interpolation.
+func interpolateAtZero(xs, ys []byte) byte {
+	result := byte(0)
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			// The basis polynomial at zero is the product of x_j / (x_j - x_i), subtraction being addition.
+			//
+			basis = multiply(basis, divide(xs[j], add(xs[j], xs[i])))
+		}
+		result = add(result, multiply(ys[i], basis))
+	}
+	return result
+}
+
+// add - addition in GF(2^8), which is also subtraction.
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+// multiply - multiplication in GF(2^8) modulo the AES polynomial x^8 + x^4 + x^3 + x + 1, without branching on
+// secret values.
+func multiply(a, b byte) byte {
+	result := byte(0)
+	for range 8 {
+		result ^= byte(subtle.ConstantTimeByteEq(b&1, 1)) * a
+		carry := a >> 7
+		a = a<<1 ^ carry*0x1b
+		b >>= 1
+	}
+	return result
+}
+
+// divide - division in GF(2^8), multiplying by the inverse a^254 of the divisor. The divisor must not be zero.
+func divide(a, b byte) byte {
+	inverse := byte(1)
+	power := b
+	for exponent := 254; exponent > 0; exponent >>= 1 {
+		if exponent&1 == 1 {
+			inverse = multiply(inverse, power)
+		}
+		power = multiply(power, power)
+	}
+	return multiply(a, inverse)
+}
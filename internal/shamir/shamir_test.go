@@ -0,0 +1,109 @@
+package shamir_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/shamir"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	scenarios := []struct {
+		name      string
+		shares    int
+		threshold int
+		combine   []int // indexes of the shares combined.
+	}{
+		{"single share", 1, 1, []int{0}},
+		{"threshold of one", 3, 1, []int{2}},
+		{"first shares", 5, 3, []int{0, 1, 2}},
+		{"last shares in disorder", 5, 3, []int{4, 2, 3}},
+		{"more than the threshold", 5, 3, []int{0, 1, 2, 3, 4}},
+		{"all shares required", 4, 4, []int{3, 1, 0, 2}},
+		{"maximum shares", 255, 2, []int{254, 100}},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			parts, err := shamir.Split(secret, scenario.shares, scenario.threshold)
+			if err != nil {
+				t.Fatalf("Expected split to succeed, got %v", err)
+			}
+			if len(parts) != scenario.shares {
+				t.Fatalf("Expected %d shares, got %d", scenario.shares, len(parts))
+			}
+
+			selected := [][]byte{}
+			for _, index := range scenario.combine {
+				selected = append(selected, parts[index])
+			}
+			combined, err := shamir.Combine(selected)
+			if err != nil || !bytes.Equal(combined, secret) {
+				t.Errorf("Expected the secret, got %x %v", combined, err)
+			}
+		})
+	}
+}
+
+func TestCombine_BelowThreshold(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	parts, err := shamir.Split(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("Expected split to succeed, got %v", err)
+	}
+
+	combined, err := shamir.Combine(parts[:2])
+	if err != nil {
+		t.Fatalf("Expected combination to succeed, got %v", err)
+	}
+	if bytes.Equal(combined, secret) {
+		t.Errorf("Expected fewer shares than the threshold not to reconstruct the secret")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	parts, err := shamir.Split([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatalf("Expected split to succeed, got %v", err)
+	}
+
+	splitScenarios := []struct {
+		name      string
+		secret    []byte
+		shares    int
+		threshold int
+		expected  error
+	}{
+		{"threshold above shares", []byte("secret"), 2, 3, shamir.ErrInvalidParameters},
+		{"zero threshold", []byte("secret"), 2, 0, shamir.ErrInvalidParameters},
+		{"too many shares", []byte("secret"), 256, 2, shamir.ErrInvalidParameters},
+		{"empty secret", nil, 3, 2, shamir.ErrEmptySecret},
+	}
+	for _, scenario := range splitScenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if _, err := shamir.Split(scenario.secret, scenario.shares, scenario.threshold); !errors.Is(err, scenario.expected) {
+				t.Errorf("Expected %v, got %v", scenario.expected, err)
+			}
+		})
+	}
+
+	combineScenarios := []struct {
+		name  string
+		parts [][]byte
+	}{
+		{"no shares", nil},
+		{"duplicated share", [][]byte{parts[0], parts[0]}},
+		{"different lengths", [][]byte{parts[0], parts[1][1:]}},
+		{"zero coordinate", [][]byte{append(parts[0][:len(parts[0])-1:len(parts[0])-1], 0)}},
+	}
+	for _, scenario := range combineScenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if _, err := shamir.Combine(scenario.parts); !errors.Is(err, shamir.ErrInvalidShares) {
+				t.Errorf("Expected %v, got %v", shamir.ErrInvalidShares, err)
+			}
+		})
+	}
+}
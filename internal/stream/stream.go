@@ -0,0 +1,336 @@
+// Package stream encrypts arbitrarily large streams locally with a data key, in authenticated chunks.
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Encrypted stream format. All integers are big endian.
+//
+//	header:
+//	+-------+---------+------------+--------------+---------------------------+--------------------+
+//	| magic | version | chunk size | nonce prefix | encrypted data key length | encrypted data key |
+//	| OKMS  | 1 byte  | 4 bytes    | 7 bytes      | 2 bytes                   | n bytes            |
+//	+-------+---------+------------+--------------+---------------------------+--------------------+
+//
+//	followed by chunks, each sealed with AES-256-GCM:
+//	+----------------------------------------+
+//	| ciphertext (chunk size bytes) and tag  |
+//	+----------------------------------------+
+//
+// The encrypted data key is a KMS ciphertext, so decrypting the stream needs the KMS only once, to recover the data
+// key. Every chunk but the last holds exactly chunk size bytes of plaintext; the last one holds the remainder and may
+// be empty. The nonce of a chunk is the nonce prefix, followed by the chunk index (4 bytes) and a final flag (1 byte)
+// set on the last chunk only, and the whole header is authenticated as additional data of every chunk. Reordering
+// chunks changes their index, dropping trailing chunks leaves a last chunk without the final flag and altering the
+// header changes the additional data, so all of them fail authentication.
+const (
+	MAGIC     = "OKMS"
+	FORMAT_V1 = 0x01
+
+	DEFAULT_CHUNK_SIZE = 64 * 1024
+	MAXIMUM_CHUNK_SIZE = 16 * 1024 * 1024
+
+	noncePrefixLength = 7
+	fixedHeaderLength = len(MAGIC) + 1 + 4 + noncePrefixLength + 2
+	dataKeyLength     = 32
+)
+
+var (
+	ErrInvalidHeader  = errors.New("invalid encrypted stream header")
+	ErrAuthentication = errors.New("encrypted stream chunk failed authentication")
+	ErrTruncated      = errors.New("encrypted stream is truncated")
+	ErrTooManyChunks  = errors.New("encrypted stream exceeds the maximum number of chunks")
+)
+
+// Header - header of an encrypted stream.
+type Header struct {
+	Version          byte
+	ChunkSize        int
+	NoncePrefix      []byte
+	EncryptedDataKey []byte
+}
+
+// Marshal - encodes the header into its binary representation.
+func (h Header) Marshal() ([]byte, error) {
+	if h.ChunkSize <= 0 || h.ChunkSize > MAXIMUM_CHUNK_SIZE {
+		return nil, fmt.Errorf("%w: chunk size %d out of range", ErrInvalidHeader, h.ChunkSize)
+	}
+	if len(h.NoncePrefix) != noncePrefixLength {
+		return nil, fmt.Errorf("%w: nonce prefix must be %d bytes", ErrInvalidHeader, noncePrefixLength)
+	}
+	if len(h.EncryptedDataKey) == 0 || len(h.EncryptedDataKey) > 0xffff {
+		return nil, fmt.Errorf("%w: encrypted data key length out of range", ErrInvalidHeader)
+	}
+
+	header := make([]byte, 0, fixedHeaderLength+len(h.EncryptedDataKey))
+	header = append(header, MAGIC...)
+	header = append(header, h.Version)
+	header = binary.BigEndian.AppendUint32(header, uint32(h.ChunkSize))
+	header = append(header, h.NoncePrefix...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(h.EncryptedDataKey)))
+	header = append(header, h.EncryptedDataKey...)
+
+	return header, nil
+}
+
+// ReadHeader - reads the header at the start of an encrypted stream, leaving the reader at its first chunk.
+func ReadHeader(r io.Reader) (Header, error) {
+	fixed := make([]byte, fixedHeaderLength)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return Header{}, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	if string(fixed[:len(MAGIC)]) != MAGIC {
+		return Header{}, fmt.Errorf("%w: not an encrypted stream", ErrInvalidHeader)
+	}
+	offset := len(MAGIC)
+
+	version := fixed[offset]
+	if version != FORMAT_V1 {
+		return Header{}, fmt.Errorf("%w: unsupported format version %d", ErrInvalidHeader, version)
+	}
+	offset++
+
+	chunkSize := int(binary.BigEndian.Uint32(fixed[offset:]))
+	if chunkSize <= 0 || chunkSize > MAXIMUM_CHUNK_SIZE {
+		return Header{}, fmt.Errorf("%w: chunk size %d out of range", ErrInvalidHeader, chunkSize)
+	}
+	offset += 4
+
+	noncePrefix := fixed[offset : offset+noncePrefixLength]
+	offset += noncePrefixLength
+
+	encryptedDataKey := make([]byte, binary.BigEndian.Uint16(fixed[offset:]))
+	if _, err := io.ReadFull(r, encryptedDataKey); err != nil {
+		return Header{}, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	return Header{
+		Version:          version,
+		ChunkSize:        chunkSize,
+		NoncePrefix:      noncePrefix,
+		EncryptedDataKey: encryptedDataKey,
+	}, nil
+}
+
+// Writer - encrypts everything written to it into an encrypted stream. Close must be called to write the final chunk.
+type Writer struct {
+	destination io.Writer
+	aead        cipher.AEAD
+	header      []byte
+	noncePrefix []byte
+	chunkSize   int
+	buffer      []byte
+	index       uint32
+	closed      bool
+}
+
+// NewWriter - writes the header of a new encrypted stream and returns the writer of its chunks.
+func NewWriter(destination io.Writer, dataKey, encryptedDataKey []byte, chunkSize int) (*Writer, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	noncePrefix := make([]byte, noncePrefixLength)
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return nil, err
+	}
+
+	header, err := Header{
+		Version:          FORMAT_V1,
+		ChunkSize:        chunkSize,
+		NoncePrefix:      noncePrefix,
+		EncryptedDataKey: encryptedDataKey,
+	}.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := destination.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		destination: destination,
+		aead:        aead,
+		header:      header,
+		noncePrefix: noncePrefix,
+		chunkSize:   chunkSize,
+		buffer:      make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Write - buffers the plaintext and writes every chunk that is known not to be the last one.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed encrypted stream")
+	}
+
+	written := 0
+	for len(p) > 0 {
+
+		// A full buffer is only sealed once more plaintext arrives, as it would otherwise be the final chunk.
+		//
+		if len(w.buffer) == w.chunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := min(len(p), w.chunkSize-len(w.buffer))
+		w.buffer = append(w.buffer, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close - writes the final chunk. It does not close the destination.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+// seal - encrypts the buffered plaintext as the next chunk.
+func (w *Writer) seal(final bool) error {
+	if !final && w.index == ^uint32(0) {
+		return ErrTooManyChunks
+	}
+
+	chunk := w.aead.Seal(nil, chunkNonce(w.noncePrefix, w.index, final), w.buffer, w.header)
+	if _, err := w.destination.Write(chunk); err != nil {
+		return err
+	}
+
+	w.index++
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// Reader - decrypts the chunks of an encrypted stream. No plaintext is returned from a chunk before it authenticates.
+type Reader struct {
+	source      *bufio.Reader
+	aead        cipher.AEAD
+	header      []byte
+	noncePrefix []byte
+	chunk       []byte
+	plaintext   []byte
+	index       uint32
+	done        bool
+}
+
+// NewReader - returns the reader of the chunks following the header, decrypting them with the plaintext data key.
+func NewReader(source io.Reader, header Header, dataKey []byte) (*Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rawHeader, err := header.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		source:      bufio.NewReaderSize(source, header.ChunkSize+aead.Overhead()+1),
+		aead:        aead,
+		header:      rawHeader,
+		noncePrefix: header.NoncePrefix,
+		chunk:       make([]byte, header.ChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Read - returns decrypted plaintext, io.EOF once the final chunk has been read and authenticated.
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plaintext) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plaintext)
+	r.plaintext = r.plaintext[n:]
+	return n, nil
+}
+
+// open - reads and authenticates the next chunk.
+func (r *Reader) open() error {
+	n, err := io.ReadFull(r.source, r.chunk)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if n < r.aead.Overhead() {
+		return ErrTruncated
+	}
+
+	// A chunk is the final one when the stream ends with it, either because it is short or because nothing follows.
+	//
+	final := n < len(r.chunk)
+	if !final {
+		if _, err := r.source.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plaintext, err := r.aead.Open(nil, chunkNonce(r.noncePrefix, r.index, final), r.chunk[:n], r.header)
+	if err != nil {
+
+		// Tell a stream cut after an intermediate chunk apart from an altered one.
+		//
+		if _, intermediate := r.aead.Open(nil, chunkNonce(r.noncePrefix, r.index, false), r.chunk[:n], r.header); final && intermediate == nil {
+			return ErrTruncated
+		}
+		return fmt.Errorf("%w: chunk %d", ErrAuthentication, r.index)
+	}
+
+	if !final && r.index == ^uint32(0) {
+		return ErrTooManyChunks
+	}
+
+	r.plaintext = plaintext
+	r.index++
+	r.done = final
+	return nil
+}
+
+// chunkNonce - derives the nonce of a chunk from its index and whether it is the final chunk.
+func chunkNonce(noncePrefix []byte, index uint32, final bool) []byte {
+	nonce := bytes.Clone(noncePrefix)
+	nonce = binary.BigEndian.AppendUint32(nonce, index)
+	if final {
+		return append(nonce, 0x01)
+	}
+	return append(nonce, 0x00)
+}
+
+// newAEAD - creates the AES-256-GCM cipher sealing the chunks.
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != dataKeyLength {
+		return nil, fmt.Errorf("data key must be %d bytes, got %d", dataKeyLength, len(dataKey))
+	}
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,172 @@
+package stream_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/stream"
+)
+
+const (
+	chunkSize = 16
+	tagSize   = 16
+)
+
+// encryptStream - encrypts the plaintext into an encrypted stream with small chunks.
+func encryptStream(t *testing.T, dataKey, plaintext []byte) []byte {
+	t.Helper()
+
+	var encrypted bytes.Buffer
+	writer, err := stream.NewWriter(&encrypted, dataKey, []byte("encrypted data key"), chunkSize)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+
+	// Write in uneven pieces so chunk boundaries do not line up with writes.
+	//
+	for remaining := plaintext; len(remaining) > 0; {
+		n := min(len(remaining), 7)
+		if _, err := writer.Write(remaining[:n]); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		remaining = remaining[n:]
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	return encrypted.Bytes()
+}
+
+// decryptStream - decrypts an encrypted stream.
+func decryptStream(dataKey, encrypted []byte) ([]byte, error) {
+	source := bytes.NewReader(encrypted)
+	header, err := stream.ReadHeader(source)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := stream.NewReader(source, header, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+func TestStream_RoundTrip(t *testing.T) {
+	dataKey := make([]byte, 32)
+	rand.Read(dataKey)
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 10*chunkSize + 5} {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+
+		encrypted := encryptStream(t, dataKey, plaintext)
+
+		header, err := stream.ReadHeader(bytes.NewReader(encrypted))
+		if err != nil {
+			t.Fatalf("Failed to read header: %v", err)
+		}
+		if header.ChunkSize != chunkSize || string(header.EncryptedDataKey) != "encrypted data key" {
+			t.Errorf("Unexpected header %+v", header)
+		}
+
+		decrypted, err := decryptStream(dataKey, encrypted)
+		if err != nil {
+			t.Fatalf("Failed to decrypt %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Expected %d bytes to round trip", size)
+		}
+	}
+}
+
+func TestStream_Tampering(t *testing.T) {
+	dataKey := make([]byte, 32)
+	rand.Read(dataKey)
+	plaintext := make([]byte, 3*chunkSize+5)
+	rand.Read(plaintext)
+
+	encrypted := encryptStream(t, dataKey, plaintext)
+	header, _ := stream.ReadHeader(bytes.NewReader(encrypted))
+	rawHeader, _ := header.Marshal()
+	chunks := encrypted[len(rawHeader):]
+	sealedChunk := chunkSize + tagSize
+
+	scenarios := []struct {
+		name     string
+		tamper   func() []byte
+		expected error
+	}{
+		{
+			name: "Reordered chunks",
+			tamper: func() []byte {
+				tampered := bytes.Clone(rawHeader)
+				tampered = append(tampered, chunks[sealedChunk:2*sealedChunk]...)
+				tampered = append(tampered, chunks[:sealedChunk]...)
+				return append(tampered, chunks[2*sealedChunk:]...)
+			},
+			expected: stream.ErrAuthentication,
+		},
+		{
+			name: "Truncated at a chunk boundary",
+			tamper: func() []byte {
+				return bytes.Clone(encrypted[:len(rawHeader)+2*sealedChunk])
+			},
+			expected: stream.ErrTruncated,
+		},
+		{
+			name: "Truncated inside a chunk",
+			tamper: func() []byte {
+				return bytes.Clone(encrypted[:len(encrypted)-3])
+			},
+			expected: stream.ErrAuthentication,
+		},
+		{
+			name: "Trailing data",
+			tamper: func() []byte {
+				return append(bytes.Clone(encrypted), 0x00)
+			},
+			expected: stream.ErrAuthentication,
+		},
+		{
+			name: "Altered header",
+			tamper: func() []byte {
+				tampered := bytes.Clone(encrypted)
+				tampered[len(rawHeader)-1] ^= 0xff
+				return tampered
+			},
+			expected: stream.ErrAuthentication,
+		},
+		{
+			name: "Altered ciphertext",
+			tamper: func() []byte {
+				tampered := bytes.Clone(encrypted)
+				tampered[len(rawHeader)+sealedChunk+1] ^= 0xff
+				return tampered
+			},
+			expected: stream.ErrAuthentication,
+		},
+		{
+			name: "Unsupported version",
+			tamper: func() []byte {
+				tampered := bytes.Clone(encrypted)
+				tampered[len(stream.MAGIC)] = 0x02
+				return tampered
+			},
+			expected: stream.ErrInvalidHeader,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := decryptStream(dataKey, scenario.tamper())
+			if !errors.Is(err, scenario.expected) {
+				t.Errorf("Expected %v, got %v", scenario.expected, err)
+			}
+		})
+	}
+}
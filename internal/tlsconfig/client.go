@@ -0,0 +1,37 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// NewClientConfig - client TLS configuration read from a directory laid out as the server's: ca.crt verifies the
+// server, tls.crt and tls.key, when present, authenticate the client. Without ca.crt the system roots are used.
+func NewClientConfig(directory string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	content, err := os.ReadFile(filepath.Join(directory, CLIENT_CA_FILE))
+	switch {
+	case err == nil:
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(content) {
+			return nil, errors.New("failed to load server CA bundle: no certificate found")
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(filepath.Join(directory, CERTIFICATE_FILE), filepath.Join(directory, KEY_FILE))
+	switch {
+	case err == nil:
+		config.Certificates = []tls.Certificate{certificate}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	return config, nil
+}
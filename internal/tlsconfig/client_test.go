@@ -0,0 +1,66 @@
+package tlsconfig_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+func TestNewClientConfig(t *testing.T) {
+	ca := newIssuer(t)
+	serverDirectory := t.TempDir()
+	writeServerCertificate(t, serverDirectory, ca, 30)
+
+	reloader, err := tlsconfig.NewReloader(serverDirectory, tlsconfig.CLIENT_AUTH_REQUIRE)
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.Config())
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// The client directory holds the server's CA bundle and a client certificate.
+	//
+	certificate, key := ca.issue(t, 31, pkix.Name{CommonName: "secondary"}, x509.ExtKeyUsageClientAuth)
+	clientDirectory := t.TempDir()
+	files := map[string][]byte{
+		tlsconfig.CERTIFICATE_FILE: certificate,
+		tlsconfig.KEY_FILE:         key,
+		tlsconfig.CLIENT_CA_FILE:   ca.pem,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(clientDirectory, name), content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	config, err := tlsconfig.NewClientConfig(clientDirectory)
+	if err != nil {
+		t.Fatalf("Failed to load client configuration: %v", err)
+	}
+	if config.RootCAs == nil || len(config.Certificates) != 1 {
+		t.Fatalf("Expected the CA bundle and the client certificate, got %+v", config)
+	}
+	if _, err := handshake(listener, ca, config.Certificates); err != nil {
+		t.Errorf("Expected the client certificate to be accepted, got %v", err)
+	}
+
+	// Every file is optional, but present files must be valid.
+	//
+	if config, err := tlsconfig.NewClientConfig(t.TempDir()); err != nil || config.RootCAs != nil || len(config.Certificates) != 0 {
+		t.Errorf("Expected an empty directory to use the system roots, got %+v %v", config, err)
+	}
+	if err := os.WriteFile(filepath.Join(clientDirectory, tlsconfig.CLIENT_CA_FILE), []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+	if _, err := tlsconfig.NewClientConfig(clientDirectory); err == nil {
+		t.Errorf("Expected a broken CA bundle to fail")
+	}
+}
@@ -0,0 +1,39 @@
+package tlsconfig
+
+import (
+	"crypto/x509"
+	"errors"
+)
+
+const (
+	CERTIFICATE_IDENTITY_PREFIX = "cert:"
+)
+
+var (
+	ErrUnknownSubject = errors.New("client certificate subject is not mapped to an identity")
+)
+
+// IdentityMapper - maps the subjects of client certificates to the caller identities used for authorization and
+// auditing. Subjects are written as in RFC 2253, e.g. "CN=billing,O=Hyperplane".
+type IdentityMapper struct {
+	identities map[string]string
+}
+
+func NewIdentityMapper(identities map[string]string) *IdentityMapper {
+	return &IdentityMapper{identities: identities}
+}
+
+// Identity - caller identity of the client presenting the certificate. When no subject is mapped, every verified
+// certificate is accepted under its subject; otherwise only the mapped subjects are.
+func (m *IdentityMapper) Identity(certificate *x509.Certificate) (string, error) {
+	subject := certificate.Subject.String()
+	if m == nil || len(m.identities) == 0 {
+		return CERTIFICATE_IDENTITY_PREFIX + subject, nil
+	}
+
+	identity, ok := m.identities[subject]
+	if !ok {
+		return "", ErrUnknownSubject
+	}
+	return identity, nil
+}
@@ -0,0 +1,53 @@
+package tlsconfig_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+func TestIdentityMapper_Identity(t *testing.T) {
+	billing := &x509.Certificate{Subject: pkix.Name{CommonName: "billing", Organization: []string{"Hyperplane"}}}
+	unknown := &x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}}
+
+	scenarios := []struct {
+		name        string
+		identities  map[string]string
+		certificate *x509.Certificate
+		identity    string
+		err         error
+	}{
+		{
+			name:        "Mapped subject",
+			identities:  map[string]string{"CN=billing,O=Hyperplane": "service:billing"},
+			certificate: billing,
+			identity:    "service:billing",
+		},
+		{
+			name:        "Unmapped subject",
+			identities:  map[string]string{"CN=billing,O=Hyperplane": "service:billing"},
+			certificate: unknown,
+			err:         tlsconfig.ErrUnknownSubject,
+		},
+		{
+			name:        "No mapping",
+			certificate: billing,
+			identity:    "cert:CN=billing,O=Hyperplane",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			identity, err := tlsconfig.NewIdentityMapper(scenario.identities).Identity(scenario.certificate)
+			if !errors.Is(err, scenario.err) {
+				t.Errorf("Expected error %v, got %v", scenario.err, err)
+			}
+			if identity != scenario.identity {
+				t.Errorf("Expected identity %q, got %q", scenario.identity, identity)
+			}
+		})
+	}
+}
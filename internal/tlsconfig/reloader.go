@@ -0,0 +1,168 @@
+// Package tlsconfig serves TLS from certificates kept on disk, reloading them when they change.
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Files expected in the certificates directory, named after the keys of Kubernetes TLS secrets so a secret can be
+// mounted as is.
+const (
+	CERTIFICATE_FILE = "tls.crt"
+	KEY_FILE         = "tls.key"
+	CLIENT_CA_FILE   = "ca.crt"
+
+	CLIENT_AUTH_NONE     = "none"
+	CLIENT_AUTH_OPTIONAL = "optional"
+	CLIENT_AUTH_REQUIRE  = "require"
+
+	RELOAD_INTERVAL = 30 * time.Second
+)
+
+var (
+	// clientAuthTypes - client certificate verification applied for every client authentication mode.
+	clientAuthTypes = map[string]tls.ClientAuthType{
+		"":                   tls.NoClientCert,
+		CLIENT_AUTH_NONE:     tls.NoClientCert,
+		CLIENT_AUTH_OPTIONAL: tls.VerifyClientCertIfGiven,
+		CLIENT_AUTH_REQUIRE:  tls.RequireAndVerifyClientCert,
+	}
+)
+
+// Reloader - holds the server certificate and client CA bundle read from a directory, and swaps them for new ones
+// when the files change, so certificates can be renewed without restarting the listener.
+type Reloader struct {
+	directory  string
+	clientAuth tls.ClientAuthType
+
+	lock        sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	fingerprint []byte // digest of the files the current certificates were loaded from.
+}
+
+// NewReloader - loads the certificates in the directory. The client CA bundle is only required when clients are
+// asked for certificates.
+func NewReloader(directory, clientAuth string) (*Reloader, error) {
+	clientAuthType, ok := clientAuthTypes[clientAuth]
+	if !ok {
+		return nil, fmt.Errorf("unsupported client authentication mode %q", clientAuth)
+	}
+
+	r := &Reloader{
+		directory:  directory,
+		clientAuth: clientAuthType,
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload - reads the files again and swaps the certificates if they changed. On failure the current certificates are
+// kept.
+func (r *Reloader) Reload() (bool, error) {
+	files := []string{CERTIFICATE_FILE, KEY_FILE}
+	if r.clientAuth != tls.NoClientCert {
+		files = append(files, CLIENT_CA_FILE)
+	}
+
+	contents := make(map[string][]byte, len(files))
+	digest := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(r.directory, file))
+		if err != nil {
+			return false, err
+		}
+		contents[file] = content
+		digest.Write(content)
+	}
+	fingerprint := digest.Sum(nil)
+
+	r.lock.RLock()
+	unchanged := bytes.Equal(fingerprint, r.fingerprint)
+	r.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.X509KeyPair(contents[CERTIFICATE_FILE], contents[KEY_FILE])
+	if err != nil {
+		return false, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientAuth != tls.NoClientCert {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(contents[CLIENT_CA_FILE]) {
+			return false, errors.New("failed to load client CA bundle: no certificate found")
+		}
+	}
+
+	r.lock.Lock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.fingerprint = fingerprint
+	r.lock.Unlock()
+
+	return true, nil
+}
+
+// Watch - reloads the certificates every interval until the context is done. onReload is called after every reload,
+// and after every failure that differs from the previous one.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, onReload func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastError := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			switch {
+			case err != nil && err.Error() != lastError:
+				lastError = err.Error()
+				onReload(err)
+			case reloaded:
+				lastError = ""
+				onReload(nil)
+			}
+		}
+	}
+}
+
+// RequiresClientCertificates - whether connections are refused unless the client presents a certificate signed by the
+// client CA bundle.
+func (r *Reloader) RequiresClientCertificates() bool {
+	return r.clientAuth == tls.RequireAndVerifyClientCert
+}
+
+// Config - server TLS configuration always using the latest certificates, for every new connection.
+func (r *Reloader) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.lock.RLock()
+			defer r.lock.RUnlock()
+
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.certificate},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    r.clientCAs,
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,237 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+// issuer - certificate authority issuing test certificates.
+type issuer struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         []byte
+}
+
+func newIssuer(t *testing.T) *issuer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+
+	return &issuer{
+		certificate: certificate,
+		key:         key,
+		pem:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue - issues a certificate for the subject, returning its PEM certificate and key.
+func (i *issuer) issue(t *testing.T, serial int64, subject pkix.Name, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, i.certificate, &key.PublicKey, i.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeServerCertificate - writes a server certificate with the given serial number and the CA bundle to the directory.
+func writeServerCertificate(t *testing.T, directory string, ca *issuer, serial int64) {
+	t.Helper()
+
+	certificate, key := ca.issue(t, serial, pkix.Name{CommonName: "openkms"}, x509.ExtKeyUsageServerAuth)
+	files := map[string][]byte{
+		tlsconfig.CERTIFICATE_FILE: certificate,
+		tlsconfig.KEY_FILE:         key,
+		tlsconfig.CLIENT_CA_FILE:   ca.pem,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// handshake - connects to the listener and returns the serial number of the server certificate.
+func handshake(listener net.Listener, ca *issuer, clientCertificates []tls.Certificate) (int64, error) {
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.(*tls.Conn).Handshake()
+		conn.Read(make([]byte, 1))
+		conn.Close()
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: roots, Certificates: clientCertificates})
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// With TLS 1.3 a rejected client certificate is only reported on the first read.
+	//
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		if netError, ok := err.(net.Error); !ok || !netError.Timeout() {
+			return 0, err
+		}
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func TestReloader_ClientCertificates(t *testing.T) {
+	ca := newIssuer(t)
+	directory := t.TempDir()
+	writeServerCertificate(t, directory, ca, 2)
+
+	reloader, err := tlsconfig.NewReloader(directory, tlsconfig.CLIENT_AUTH_REQUIRE)
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+	if !reloader.RequiresClientCertificates() {
+		t.Errorf("Expected client certificates to be required")
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.Config())
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	certificate, key := ca.issue(t, 3, pkix.Name{CommonName: "billing"}, x509.ExtKeyUsageClientAuth)
+	trusted, _ := tls.X509KeyPair(certificate, key)
+	certificate, key = newIssuer(t).issue(t, 4, pkix.Name{CommonName: "billing"}, x509.ExtKeyUsageClientAuth)
+	untrusted, _ := tls.X509KeyPair(certificate, key)
+
+	scenarios := []struct {
+		name         string
+		certificates []tls.Certificate
+		accepted     bool
+	}{
+		{name: "Trusted client certificate", certificates: []tls.Certificate{trusted}, accepted: true},
+		{name: "Untrusted client certificate", certificates: []tls.Certificate{untrusted}},
+		{name: "No client certificate"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := handshake(listener, ca, scenario.certificates)
+			if scenario.accepted && err != nil {
+				t.Errorf("Expected handshake to succeed, got %v", err)
+			}
+			if !scenario.accepted && err == nil {
+				t.Errorf("Expected handshake to fail")
+			}
+		})
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	ca := newIssuer(t)
+	directory := t.TempDir()
+	writeServerCertificate(t, directory, ca, 10)
+
+	reloader, err := tlsconfig.NewReloader(directory, tlsconfig.CLIENT_AUTH_NONE)
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.Config())
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	if reloaded, err := reloader.Reload(); reloaded || err != nil {
+		t.Errorf("Expected unchanged certificates not to be reloaded, got %t %v", reloaded, err)
+	}
+
+	// Renewed certificates are served to new connections without a new listener.
+	//
+	writeServerCertificate(t, directory, ca, 11)
+	if reloaded, err := reloader.Reload(); !reloaded || err != nil {
+		t.Fatalf("Expected certificates to be reloaded, got %t %v", reloaded, err)
+	}
+	if serial, err := handshake(listener, ca, nil); err != nil || serial != 11 {
+		t.Errorf("Expected renewed certificate 11, got %d %v", serial, err)
+	}
+
+	// Broken files are reported and the current certificates kept.
+	//
+	if err := os.WriteFile(filepath.Join(directory, tlsconfig.KEY_FILE), []byte("garbage"), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	if _, err := reloader.Reload(); err == nil {
+		t.Errorf("Expected a broken key to fail reloading")
+	}
+	if serial, err := handshake(listener, ca, nil); err != nil || serial != 11 {
+		t.Errorf("Expected certificate 11 to be kept, got %d %v", serial, err)
+	}
+}
+
+func TestNewReloader_Errors(t *testing.T) {
+	ca := newIssuer(t)
+	directory := t.TempDir()
+	writeServerCertificate(t, directory, ca, 20)
+	os.Remove(filepath.Join(directory, tlsconfig.CLIENT_CA_FILE))
+
+	if _, err := tlsconfig.NewReloader(directory, tlsconfig.CLIENT_AUTH_NONE); err != nil {
+		t.Errorf("Expected the client CA bundle to be optional without client authentication, got %v", err)
+	}
+	if _, err := tlsconfig.NewReloader(directory, tlsconfig.CLIENT_AUTH_REQUIRE); err == nil {
+		t.Errorf("Expected a missing client CA bundle to fail")
+	}
+	if _, err := tlsconfig.NewReloader(directory, "sometimes"); err == nil {
+		t.Errorf("Expected an unsupported client authentication mode to fail")
+	}
+}
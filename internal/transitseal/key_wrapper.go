@@ -0,0 +1,107 @@
+// Package transitseal wraps the root key of a KMS with a key held by another OpenKMS daemon, reached over its REST
+// API, so the KMS can unseal itself without operators submitting unseal shares.
+package transitseal
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hyperplane-sh/openkms/internal/restapi"
+)
+
+const (
+	REQUEST_TIMEOUT = 10 * time.Second
+
+	// ENCRYPTION_CONTEXT_PURPOSE - encryption context binding the wrapped root key to its purpose, so the other
+	// daemon's ciphertexts of other data cannot be passed off as a root key.
+	ENCRYPTION_CONTEXT_PURPOSE = "openkms:purpose"
+	PURPOSE_ROOT_KEY           = "root-key"
+)
+
+// KeyWrapper - wraps and unwraps the root key with the encrypt and decrypt endpoints of another daemon's REST API.
+type KeyWrapper struct {
+	address string
+	keyID   string
+	client  *http.Client
+}
+
+// NewKeyWrapper - constructor for KeyWrapper. The address is the base URL of the REST API, the key ID may be an
+// alias. The API is reached in plain HTTP when the TLS configuration is nil.
+func NewKeyWrapper(address, keyID string, tlsConfig *tls.Config) *KeyWrapper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &KeyWrapper{
+		address: strings.TrimSuffix(address, "/"),
+		keyID:   keyID,
+		client:  &http.Client{Transport: transport, Timeout: REQUEST_TIMEOUT},
+	}
+}
+
+// WrapKey - encrypts the key under the configured key of the other daemon.
+func (kW *KeyWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	var response restapi.EncryptResponse
+	err := kW.call(ctx, "/v1/encrypt", restapi.EncryptRequest{
+		KeyID:             kW.keyID,
+		Plaintext:         key,
+		EncryptionContext: encryptionContext(),
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Ciphertext, nil
+}
+
+// UnwrapKey - decrypts a key wrapped by WrapKey.
+func (kW *KeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var response restapi.DecryptResponse
+	err := kW.call(ctx, "/v1/decrypt", restapi.DecryptRequest{
+		Ciphertext:        wrapped,
+		EncryptionContext: encryptionContext(),
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Plaintext, nil
+}
+
+// call - posts a request to the REST API and decodes its response, turning error bodies into errors.
+func (kW *KeyWrapper) call(ctx context.Context, path string, body, response any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, kW.address+path, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := kW.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("transit seal: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		var errorResponse restapi.ErrorResponse
+		if err := json.NewDecoder(httpResponse.Body).Decode(&errorResponse); err != nil || errorResponse.Error.Code == "" {
+			return fmt.Errorf("transit seal: %s answered %s", kW.address, httpResponse.Status)
+		}
+		return fmt.Errorf("transit seal: %s answered %s: %s", kW.address, errorResponse.Error.Code, errorResponse.Error.Message)
+	}
+
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
+
+// encryptionContext - encryption context of the wrapped root key.
+func encryptionContext() map[string]string {
+	return map[string]string{ENCRYPTION_CONTEXT_PURPOSE: PURPOSE_ROOT_KEY}
+}
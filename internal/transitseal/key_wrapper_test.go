@@ -0,0 +1,130 @@
+package transitseal_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+	"github.com/hyperplane-sh/openkms/internal/restapi"
+	"github.com/hyperplane-sh/openkms/internal/transitseal"
+)
+
+// newPrimary - serves the REST API of a daemon holding the unseal key, returning its address and service.
+func newPrimary(t *testing.T) (string, *kms.Service) {
+	t.Helper()
+
+	service := kmstest.NewService(t, nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- restapi.NewServer(service, nil, "TEST", nil).ServeListener(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return "http://" + listener.Addr().String(), service
+}
+
+// newSecondary - service of a daemon auto-unsealing with the primary, reopening the directory after a restart.
+func newSecondary(t *testing.T, directory, address, keyID string) *kms.Service {
+	t.Helper()
+
+	barrier := kms.NewTransitBarrier(transitseal.NewKeyWrapper(address, keyID, nil))
+	return kmstest.OpenService(t, kms.NewKeyStoreStorage(kms.NewStorageBackendFile(directory), barrier, nil, nil), nil)
+}
+
+func TestKeyWrapper_AutoUnseal(t *testing.T) {
+	ctx := context.Background()
+	address, primary := newPrimary(t)
+	unsealKey, err := primary.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create unseal key: %v", err)
+	}
+	if _, err := primary.KeyStore().CreateAlias(ctx, "alias/unseal", unsealKey.ID); err != nil {
+		t.Fatalf("Failed to create alias: %v", err)
+	}
+
+	// The first unseal generates the root key, later ones unwrap it.
+	//
+	directory := t.TempDir()
+	secondary := newSecondary(t, directory, address, "alias/unseal")
+	if err := secondary.AutoUnseal(ctx); err != nil {
+		t.Fatalf("Expected the first auto-unseal to initialize the seal, got %v", err)
+	}
+	key, err := secondary.KeyStore().CreateKey(ctx, kms.CreateKeyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	ciphertext, err := secondary.Encrypt(ctx, key.ID, []byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	restarted := newSecondary(t, directory, address, "alias/unseal")
+	if !restarted.Sealed() {
+		t.Fatalf("Expected a restarted service to be sealed")
+	}
+	if err := restarted.AutoUnseal(ctx); err != nil {
+		t.Fatalf("Expected auto-unseal to succeed, got %v", err)
+	}
+	if plaintext, err := restarted.Decrypt(ctx, ciphertext, nil); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Expected the plaintext after auto-unseal, got %q %v", plaintext, err)
+	}
+	status, err := restarted.SealStatus(ctx)
+	if err != nil || status.Type != kms.SEAL_TYPE_TRANSIT || !status.Initialized || status.Sealed {
+		t.Errorf("Unexpected seal status %+v %v", status, err)
+	}
+
+	// Unseal shares do not apply to a transit seal.
+	//
+	if _, err := restarted.Initialize(ctx, 3, 2); !errors.Is(err, kms.ErrSealMismatch) {
+		t.Errorf("Expected %v, got %v", kms.ErrSealMismatch, err)
+	}
+
+	// The daemon stays sealed while the unseal key cannot be used.
+	//
+	if _, err := primary.KeyStore().DisableKey(ctx, unsealKey.ID); err != nil {
+		t.Fatalf("Failed to disable unseal key: %v", err)
+	}
+	disabled := newSecondary(t, directory, address, "alias/unseal")
+	if err := disabled.AutoUnseal(ctx); err == nil || !disabled.Sealed() {
+		t.Errorf("Expected auto-unseal to fail with a disabled unseal key, got %v", err)
+	}
+}
+
+func TestKeyWrapper_Errors(t *testing.T) {
+	ctx := context.Background()
+	address, _ := newPrimary(t)
+
+	scenarios := []struct {
+		name    string
+		address string
+		keyID   string
+	}{
+		{"unknown key", address, "alias/missing"},
+		{"unreachable daemon", "http://127.0.0.1:1", "alias/unseal"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			service := newSecondary(t, t.TempDir(), scenario.address, scenario.keyID)
+			if err := service.AutoUnseal(ctx); err == nil {
+				t.Errorf("Expected auto-unseal to fail")
+			}
+			if !service.Sealed() {
+				t.Errorf("Expected the service to stay sealed")
+			}
+			if _, err := service.SealStatus(ctx); err != nil {
+				t.Errorf("Expected the seal status, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package unixsocket listens on Unix domain sockets whose permissions are set before any peer can connect to them.
+package unixsocket
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// Listen - listens on a Unix domain socket with the given permissions. The socket is created inside a private
+// directory next to it and moved into place once its permissions are set, so it is never reachable with the default
+// ones. A socket left behind by a previous run is replaced, any other file is kept and fails the listen.
+func Listen(socketPath string, mode os.FileMode) (*net.UnixListener, error) {
+	if info, err := os.Lstat(socketPath); err == nil && info.Mode()&os.ModeSocket == 0 {
+		return nil, fmt.Errorf("%s exists and is not a socket", socketPath)
+	}
+
+	directory, err := os.MkdirTemp(filepath.Dir(socketPath), ".socket-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(directory)
+
+	// The name is kept short, as the path of a socket is limited to about a hundred bytes.
+	//
+	temporaryPath := filepath.Join(directory, "s")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: temporaryPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	listener.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(temporaryPath, mode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	if err := os.Rename(temporaryPath, socketPath); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
@@ -0,0 +1,101 @@
+package unixsocket_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/unixsocket"
+)
+
+func TestListen(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		mode     os.FileMode
+		existing func(t *testing.T, socketPath string) // file left at the socket path before listening.
+		fails    bool
+	}{
+		{
+			name: "Group socket",
+			mode: 0660,
+		},
+		{
+			name: "Private socket",
+			mode: 0600,
+		},
+		{
+			name: "Stale socket",
+			mode: 0600,
+			existing: func(t *testing.T, socketPath string) {
+				listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+				if err != nil {
+					t.Fatalf("Failed to listen: %v", err)
+				}
+				listener.SetUnlinkOnClose(false)
+				listener.Close()
+			},
+		},
+		{
+			name: "Regular file",
+			mode: 0600,
+			existing: func(t *testing.T, socketPath string) {
+				if err := os.WriteFile(socketPath, []byte("keep"), 0600); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			},
+			fails: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			directory, err := os.MkdirTemp("", "unixsocket")
+			if err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			t.Cleanup(func() { os.RemoveAll(directory) })
+			socketPath := filepath.Join(directory, "openkms.sock")
+			if scenario.existing != nil {
+				scenario.existing(t, socketPath)
+			}
+
+			listener, err := unixsocket.Listen(socketPath, scenario.mode)
+			if scenario.fails {
+				if err == nil {
+					listener.Close()
+					t.Fatalf("Expected an error")
+				}
+				if content, err := os.ReadFile(socketPath); err != nil || string(content) != "keep" {
+					t.Errorf("Expected the file to be kept, got %q %v", content, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to listen: %v", err)
+			}
+			defer listener.Close()
+
+			info, err := os.Lstat(socketPath)
+			if err != nil {
+				t.Fatalf("Failed to stat socket: %v", err)
+			}
+			if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != scenario.mode {
+				t.Errorf("Expected a socket with mode %v, got %v", scenario.mode, info.Mode())
+			}
+
+			// The private directory the socket was created in is removed.
+			//
+			entries, err := os.ReadDir(directory)
+			if err != nil || len(entries) != 1 {
+				t.Errorf("Expected only the socket in the directory, got %d entries %v", len(entries), err)
+			}
+
+			connection, err := net.Dial("unix", socketPath)
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			connection.Close()
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package vaulttransit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hyperplane-sh/openkms/internal/httpapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+)
+
+const (
+	KEY_TYPE_AES256_GCM96 = "aes256-gcm96"
+
+	CIPHERTEXT_PREFIX = "vault:v"
+
+	// ENCRYPTION_CONTEXT_KEY - encryption context entry holding the Vault context, base64 encoded.
+	ENCRYPTION_CONTEXT_KEY = "vault:context"
+
+	DATA_KEY_TYPE_PLAINTEXT = "plaintext"
+	DATA_KEY_TYPE_WRAPPED   = "wrapped"
+)
+
+var (
+	// keyTypes - Vault key type reported for every key spec.
+	keyTypes = map[string]string{
+		kms.KEY_SPEC_SYMMETRIC_DEFAULT: KEY_TYPE_AES256_GCM96,
+		kms.KEY_SPEC_ECC_NIST_P256:     "ecdsa-p256",
+		kms.KEY_SPEC_ECC_NIST_P384:     "ecdsa-p384",
+		kms.KEY_SPEC_ED25519:           "ed25519",
+		kms.KEY_SPEC_RSA_2048:          "rsa-2048",
+		kms.KEY_SPEC_RSA_3072:          "rsa-3072",
+		kms.KEY_SPEC_RSA_4096:          "rsa-4096",
+		kms.KEY_SPEC_HMAC_256:          "hmac",
+		kms.KEY_SPEC_HMAC_384:          "hmac",
+		kms.KEY_SPEC_HMAC_512:          "hmac",
+	}
+
+	// dataKeySpecs - data key spec generated for every number of bits Vault clients may ask for.
+	dataKeySpecs = map[int]string{
+		128: kms.DATA_KEY_SPEC_AES_128,
+		256: kms.DATA_KEY_SPEC_AES_256,
+	}
+)
+
+// createKey - creates an encryption key behind the alias of the name, both in the same transaction. As in Vault,
+// creating an existing key succeeds and leaves it untouched, including when a concurrent request created it first.
+func (s *Server) createKey(c *fiber.Ctx) error {
+	var request KeyCreateRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+	if request.Type != "" && request.Type != KEY_TYPE_AES256_GCM96 {
+		return fiber.NewError(fiber.StatusBadRequest, "unsupported key type "+request.Type)
+	}
+
+	name := c.Params("name")
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), aliasName(name))
+	if kms.ErrorCode(err) == kms.ERROR_CODE_NOT_FOUND {
+		key, err = s.kmsService.KeyStore().CreateKey(c.UserContext(), kms.CreateKeyOptions{
+			Metadata: kms.KeyMetadata{Description: "Vault Transit key " + name},
+			Alias:    aliasName(name),
+		})
+		if errors.Is(err, kms.ErrAliasExists) {
+			key, err = s.kmsService.KeyStore().GetKey(c.UserContext(), aliasName(name))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return respond(c, fiber.StatusOK, newKeyResponse(name, key))
+}
+
+func (s *Server) readKey(c *fiber.Ctx) error {
+	name := c.Params("name")
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), aliasName(name))
+	if err != nil {
+		return err
+	}
+	return respond(c, fiber.StatusOK, newKeyResponse(name, key))
+}
+
+func (s *Server) rotateKey(c *fiber.Ctx) error {
+	name := c.Params("name")
+	key, err := s.kmsService.KeyStore().RotateKey(c.UserContext(), aliasName(name))
+	if err != nil {
+		return err
+	}
+	return respond(c, fiber.StatusOK, newKeyResponse(name, key))
+}
+
+func (s *Server) encrypt(c *fiber.Ctx) error {
+	var request EncryptRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), aliasName(c.Params("name")))
+	if err != nil {
+		return err
+	}
+
+	if request.BatchInput == nil {
+		result, err := s.encryptItem(c.UserContext(), key, request.EncryptItem.Plaintext, request.EncryptItem.Context, request.EncryptItem.KeyVersion)
+		if err != nil {
+			return err
+		}
+		return respond(c, fiber.StatusOK, result)
+	}
+
+	return respondBatch(c, request.BatchInput, func(item EncryptItem) CiphertextResult {
+		result, err := s.encryptItem(c.UserContext(), key, item.Plaintext, item.Context, item.KeyVersion)
+		if err != nil {
+			return CiphertextResult{Error: itemError(err)}
+		}
+		return result
+	})
+}
+
+func (s *Server) decrypt(c *fiber.Ctx) error {
+	var request DecryptRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), aliasName(c.Params("name")))
+	if err != nil {
+		return err
+	}
+
+	if request.BatchInput == nil {
+		plaintext, err := s.decryptItem(c.UserContext(), key, request.DecryptItem)
+		if err != nil {
+			return err
+		}
+		return respond(c, fiber.StatusOK, PlaintextResult{Plaintext: plaintext})
+	}
+
+	return respondBatch(c, request.BatchInput, func(item DecryptItem) PlaintextResult {
+		plaintext, err := s.decryptItem(c.UserContext(), key, item)
+		if err != nil {
+			return PlaintextResult{Error: itemError(err)}
+		}
+		return PlaintextResult{Plaintext: plaintext}
+	})
+}
+
+// rewrap - decrypts ciphertexts of older versions and encrypts them under the latest version of the key, without
+// the plaintexts leaving the server.
+func (s *Server) rewrap(c *fiber.Ctx) error {
+	var request RewrapRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	key, err := s.kmsService.KeyStore().GetKey(c.UserContext(), aliasName(c.Params("name")))
+	if err != nil {
+		return err
+	}
+
+	rewrapItem := func(item DecryptItem) (CiphertextResult, error) {
+		plaintext, err := s.decryptItem(c.UserContext(), key, item)
+		if err != nil {
+			return CiphertextResult{}, err
+		}
+		defer clear(plaintext)
+		return s.encryptItem(c.UserContext(), key, plaintext, item.Context, request.KeyVersion)
+	}
+
+	if request.BatchInput == nil {
+		result, err := rewrapItem(request.DecryptItem)
+		if err != nil {
+			return err
+		}
+		return respond(c, fiber.StatusOK, result)
+	}
+
+	return respondBatch(c, request.BatchInput, func(item DecryptItem) CiphertextResult {
+		result, err := rewrapItem(item)
+		if err != nil {
+			return CiphertextResult{Error: itemError(err)}
+		}
+		return result
+	})
+}
+
+// generateDataKey - generates a data key wrapped under the key, also returned in plaintext for the plaintext type.
+func (s *Server) generateDataKey(c *fiber.Ctx) error {
+	var request DataKeyRequest
+	if err := parseBody(c, &request); err != nil {
+		return err
+	}
+
+	bits := request.Bits
+	if bits == 0 {
+		bits = 256
+	}
+	spec, ok := dataKeySpecs[bits]
+	if !ok {
+		return fiber.NewError(fiber.StatusBadRequest, "bits must be 128 or 256")
+	}
+
+	generate := s.kmsService.GenerateDataKey
+	switch c.Params("type") {
+	case DATA_KEY_TYPE_PLAINTEXT:
+	case DATA_KEY_TYPE_WRAPPED:
+		generate = s.kmsService.GenerateDataKeyWithoutPlaintext
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "data key type must be plaintext or wrapped")
+	}
+
+	dataKey, err := generate(c.UserContext(), aliasName(c.Params("name")), spec, encryptionContext(request.Context))
+	if err != nil {
+		return err
+	}
+	return respond(c, fiber.StatusOK, DataKeyResponse{
+		Plaintext:  dataKey.Plaintext,
+		Ciphertext: encodeCiphertext(dataKey.KeyVersion, dataKey.Ciphertext),
+		KeyVersion: dataKey.KeyVersion,
+	})
+}
+
+// encryptItem - encrypts a plaintext under the latest version of the key, the only one Vault clients may name.
+func (s *Server) encryptItem(ctx context.Context, key kms.Key, plaintext, vaultContext []byte, keyVersion int) (CiphertextResult, error) {
+	if keyVersion != 0 && keyVersion != key.PrimaryVersion {
+		return CiphertextResult{}, fiber.NewError(fiber.StatusBadRequest, "only the latest key version can encrypt")
+	}
+
+	ciphertext, err := s.kmsService.EncryptPlaintext(ctx, key.ID, plaintext, encryptionContext(vaultContext))
+	if err != nil {
+		return CiphertextResult{}, err
+	}
+	return CiphertextResult{Ciphertext: encodeCiphertext(ciphertext.KeyVersion, ciphertext.Ciphertext), KeyVersion: ciphertext.KeyVersion}, nil
+}
+
+// decryptItem - decrypts a Vault ciphertext, which must have been encrypted under the key.
+func (s *Server) decryptItem(ctx context.Context, key kms.Key, item DecryptItem) ([]byte, error) {
+	ciphertext, err := decodeCiphertext(key, item.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return s.kmsService.Decrypt(ctx, ciphertext, encryptionContext(item.Context))
+}
+
+// newKeyResponse - converts a key into its Vault representation.
+func newKeyResponse(name string, key kms.Key) KeyResponse {
+	response := KeyResponse{
+		Name:                 name,
+		Type:                 keyTypes[key.Spec],
+		LatestVersion:        key.PrimaryVersion,
+		MinDecryptionVersion: 1,
+		SupportsEncryption:   key.Spec == kms.KEY_SPEC_SYMMETRIC_DEFAULT,
+		SupportsDecryption:   key.Spec == kms.KEY_SPEC_SYMMETRIC_DEFAULT,
+		SupportsSigning:      key.Usage == kms.KEY_USAGE_SIGN_VERIFY,
+		Keys:                 make(map[string]int64, len(key.Versions)),
+	}
+	for _, version := range key.Versions {
+		response.Keys[strconv.Itoa(version.Version)] = version.CreatedAt.Unix()
+	}
+	return response
+}
+
+// aliasName - alias of the OpenKMS key behind a Vault key name.
+func aliasName(name string) string {
+	return kms.ALIAS_PREFIX + name
+}
+
+// encryptionContext - encryption context binding a ciphertext to the Vault context, if any.
+func encryptionContext(vaultContext []byte) map[string]string {
+	if len(vaultContext) == 0 {
+		return nil
+	}
+	return map[string]string{ENCRYPTION_CONTEXT_KEY: base64.StdEncoding.EncodeToString(vaultContext)}
+}
+
+// encodeCiphertext - formats a ciphertext envelope as a Vault ciphertext, vault:v<version>:<base64 envelope>.
+func encodeCiphertext(keyVersion int, ciphertext []byte) string {
+	return CIPHERTEXT_PREFIX + strconv.Itoa(keyVersion) + ":" + base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+// decodeCiphertext - returns the envelope of a Vault ciphertext, making sure its version prefix and key are the ones
+// of its header.
+func decodeCiphertext(key kms.Key, ciphertext string) ([]byte, error) {
+	versionAndEnvelope, ok := strings.CutPrefix(ciphertext, CIPHERTEXT_PREFIX)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s prefix", kms.ErrInvalidCiphertext, CIPHERTEXT_PREFIX)
+	}
+
+	version, encoded, ok := strings.Cut(versionAndEnvelope, ":")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing key version", kms.ErrInvalidCiphertext)
+	}
+	keyVersion, err := strconv.Atoi(version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed key version", kms.ErrInvalidCiphertext)
+	}
+
+	envelope, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed encoding", kms.ErrInvalidCiphertext)
+	}
+
+	header, _, _, err := kms.ParseCiphertext(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if header.KeyID != key.ID || header.KeyVersion != keyVersion {
+		return nil, fmt.Errorf("%w: not encrypted under this key version", kms.ErrInvalidCiphertext)
+	}
+	return envelope, nil
+}
+
+// respondBatch - runs every item of a batch, answering a bad request when any of them failed as Vault does.
+func respondBatch[I any, R interface{ failed() bool }](c *fiber.Ctx, items []I, run func(item I) R) error {
+	response := BatchResponse[R]{BatchResults: make([]R, 0, len(items))}
+	status := fiber.StatusOK
+	for _, item := range items {
+		result := run(item)
+		if result.failed() {
+			status = fiber.StatusBadRequest
+		}
+		response.BatchResults = append(response.BatchResults, result)
+	}
+	return respond(c, status, response)
+}
+
+// itemError - message of the error of a batch item.
+func itemError(err error) string {
+	_, message := httpapi.ErrorStatus(err, httpStatuses)
+	return message
+}
+
+// parseBody - decodes the JSON body of a request, when it has one.
+func parseBody(c *fiber.Ctx, request any) error {
+	if len(c.Body()) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(c.Body(), request); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "malformed request body: "+err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package vaulttransit
+
+// Request and response shapes of the Vault Transit secrets engine. Field names follow Vault, plaintexts and
+// contexts are base64 encoded.
+
+// Response - envelope of every successful response.
+type Response struct {
+	RequestID     string   `json:"request_id"`
+	LeaseID       string   `json:"lease_id"`
+	Renewable     bool     `json:"renewable"`
+	LeaseDuration int      `json:"lease_duration"`
+	Data          any      `json:"data"`
+	Warnings      []string `json:"warnings"`
+}
+
+// ErrorResponse - body of every failed request.
+type ErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+type KeyCreateRequest struct {
+	Type string `json:"type"`
+}
+
+type KeyResponse struct {
+	Name                 string           `json:"name"`
+	Type                 string           `json:"type"`
+	LatestVersion        int              `json:"latest_version"`
+	MinDecryptionVersion int              `json:"min_decryption_version"`
+	MinEncryptionVersion int              `json:"min_encryption_version"`
+	DeletionAllowed      bool             `json:"deletion_allowed"`
+	Derived              bool             `json:"derived"`
+	Exportable           bool             `json:"exportable"`
+	SupportsEncryption   bool             `json:"supports_encryption"`
+	SupportsDecryption   bool             `json:"supports_decryption"`
+	SupportsDerivation   bool             `json:"supports_derivation"`
+	SupportsSigning      bool             `json:"supports_signing"`
+	Keys                 map[string]int64 `json:"keys"` // creation time of every version, in seconds since the epoch.
+}
+
+type EncryptItem struct {
+	Plaintext  []byte `json:"plaintext"`
+	Context    []byte `json:"context"`
+	KeyVersion int    `json:"key_version"`
+}
+
+type EncryptRequest struct {
+	EncryptItem
+	BatchInput []EncryptItem `json:"batch_input"`
+}
+
+type DecryptItem struct {
+	Ciphertext string `json:"ciphertext"`
+	Context    []byte `json:"context"`
+}
+
+type DecryptRequest struct {
+	DecryptItem
+	BatchInput []DecryptItem `json:"batch_input"`
+}
+
+type RewrapRequest struct {
+	DecryptItem
+	KeyVersion int           `json:"key_version"`
+	BatchInput []DecryptItem `json:"batch_input"`
+}
+
+type CiphertextResult struct {
+	Ciphertext string `json:"ciphertext,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (r CiphertextResult) failed() bool {
+	return r.Error != ""
+}
+
+type PlaintextResult struct {
+	Plaintext []byte `json:"plaintext,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (r PlaintextResult) failed() bool {
+	return r.Error != ""
+}
+
+type BatchResponse[R any] struct {
+	BatchResults []R `json:"batch_results"`
+}
+
+type DataKeyRequest struct {
+	Context []byte `json:"context"`
+	Bits    int    `json:"bits"`
+}
+
+type DataKeyResponse struct {
+	Plaintext  []byte `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext"`
+	KeyVersion int    `json:"key_version"`
+}
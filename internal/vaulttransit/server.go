@@ -0,0 +1,97 @@
+// Package vaulttransit serves the KMS over the HTTP API of the HashiCorp Vault Transit secrets engine, so services
+// written against Vault can use OpenKMS by changing its address. A Vault key name maps to the OpenKMS key behind the
+// alias alias/<name>, and Vault key versions are OpenKMS key versions. Vault tokens are ignored, clients are only
+// authenticated by their TLS certificates, so the daemon refuses to serve the API unless the listener requires them.
+package vaulttransit
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hyperplane-sh/openkms/internal/audit"
+	"github.com/hyperplane-sh/openkms/internal/httpapi"
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/tlsconfig"
+)
+
+const (
+	HEADER_REQUEST_ID = "X-Request-Id"
+	MOUNT_PATH        = "/v1/transit"
+)
+
+var (
+	// httpStatuses - HTTP status answered for every KMS error code.
+	httpStatuses = map[string]int{
+		kms.ERROR_CODE_NOT_FOUND:           fiber.StatusNotFound,
+		kms.ERROR_CODE_ALREADY_EXISTS:      fiber.StatusBadRequest,
+		kms.ERROR_CODE_INVALID_ARGUMENT:    fiber.StatusBadRequest,
+		kms.ERROR_CODE_FAILED_PRECONDITION: fiber.StatusBadRequest,
+		kms.ERROR_CODE_ABORTED:             fiber.StatusServiceUnavailable, // retried by Vault clients.
+		kms.ERROR_CODE_INTERNAL:            fiber.StatusInternalServerError,
+	}
+)
+
+// Server - serves the KMS as the Vault Transit secrets engine.
+type Server struct {
+	kmsService *kms.Service
+	app        *fiber.App
+}
+
+func NewServer(kmsService *kms.Service, auditor audit.Auditor, auditGroup string, identities *tlsconfig.IdentityMapper) *Server {
+	s := &Server{
+		kmsService: kmsService,
+	}
+
+	middleware := httpapi.Middleware{
+		RequestIDHeader: HEADER_REQUEST_ID,
+		Identities:      identities,
+		HandleError:     s.handleError,
+		Auditing:        httpapi.Auditing{Auditor: auditor, Group: auditGroup, Topic: audit.TOPIC_VAULT_API, Message: "Vault Transit API request handled"},
+	}
+	s.app = httpapi.NewApp(s.handleError)
+	s.app.Use(middleware.ServeRequest)
+
+	// Vault clients write with either POST or PUT.
+	//
+	transit := s.app.Group(MOUNT_PATH)
+	for _, method := range []string{fiber.MethodPost, fiber.MethodPut} {
+		transit.Add(method, "/keys/:name", s.createKey)
+		transit.Add(method, "/keys/:name/rotate", s.rotateKey)
+		transit.Add(method, "/encrypt/:name", s.encrypt)
+		transit.Add(method, "/decrypt/:name", s.decrypt)
+		transit.Add(method, "/rewrap/:name", s.rewrap)
+		transit.Add(method, "/datakey/:type/:name", s.generateDataKey)
+	}
+	transit.Get("/keys/:name", s.readKey)
+
+	return s
+}
+
+// App - Fiber application serving the API.
+func (s *Server) App() *fiber.App {
+	return s.app
+}
+
+// Serve - listens on the address, over TLS when a configuration is given, until the context is done.
+func (s *Server) Serve(ctx context.Context, address string, tlsConfig *tls.Config) error {
+	return httpapi.Serve(ctx, s.app, address, tlsConfig)
+}
+
+// ServeListener - serves the connections accepted by the listener until the context is done.
+func (s *Server) ServeListener(ctx context.Context, listener net.Listener) error {
+	return httpapi.ServeListener(ctx, s.app, listener)
+}
+
+// handleError - answers an error with the error body of Vault.
+func (s *Server) handleError(c *fiber.Ctx, err error) error {
+	status, message := httpapi.ErrorStatus(err, httpStatuses)
+	return c.Status(status).JSON(ErrorResponse{Errors: []string{message}})
+}
+
+// respond - answers the data wrapped in the response envelope of Vault.
+func respond(c *fiber.Ctx, status int, data any) error {
+	requestID, _ := c.Locals(HEADER_REQUEST_ID).(string)
+	return c.Status(status).JSON(Response{RequestID: requestID, Data: data})
+}
@@ -0,0 +1,267 @@
+package vaulttransit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyperplane-sh/openkms/internal/kms"
+	"github.com/hyperplane-sh/openkms/internal/kmstest"
+	"github.com/hyperplane-sh/openkms/internal/vaulttransit"
+)
+
+// newServer - Vault Transit API backed by a file key store in a temporary directory.
+func newServer(t *testing.T) *vaulttransit.Server {
+	t.Helper()
+
+	return vaulttransit.NewServer(kmstest.NewService(t, nil), nil, "TEST", nil)
+}
+
+// call - sends a request to the API as a Vault client would and decodes the data of the response, returning its
+// status and errors.
+func call(t *testing.T, server *vaulttransit.Server, method, path string, body any, data any) (int, []string) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	request := httptest.NewRequest(method, path, reader)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Vault-Token", "ignored")
+
+	httpResponse, err := server.App().Test(request, -1)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer httpResponse.Body.Close()
+
+	content, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+
+	var errorResponse vaulttransit.ErrorResponse
+	if err := json.Unmarshal(content, &errorResponse); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if data != nil {
+		if err := json.Unmarshal(content, &vaulttransit.Response{Data: data}); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return httpResponse.StatusCode, errorResponse.Errors
+}
+
+func TestServer_EncryptRotateRewrap(t *testing.T) {
+	server := newServer(t)
+
+	var key vaulttransit.KeyResponse
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/keys/orders", nil, &key); status != http.StatusOK {
+		t.Fatalf("Expected key to be created, got %d %v", status, errors)
+	}
+	if key.Name != "orders" || key.Type != vaulttransit.KEY_TYPE_AES256_GCM96 || key.LatestVersion != 1 || len(key.Keys) != 1 {
+		t.Errorf("Unexpected key %+v", key)
+	}
+
+	vaultContext := []byte("tenant-1")
+	var encrypted vaulttransit.CiphertextResult
+	if status, errors := call(t, server, http.MethodPut, "/v1/transit/encrypt/orders", vaulttransit.EncryptItem{Plaintext: []byte("secret"), Context: vaultContext}, &encrypted); status != http.StatusOK {
+		t.Fatalf("Expected encryption to succeed, got %d %v", status, errors)
+	}
+	if !strings.HasPrefix(encrypted.Ciphertext, "vault:v1:") || encrypted.KeyVersion != 1 {
+		t.Errorf("Expected a vault:v1: ciphertext, got %+v", encrypted)
+	}
+
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/keys/orders/rotate", nil, &key); status != http.StatusOK || key.LatestVersion != 2 || len(key.Keys) != 2 {
+		t.Fatalf("Expected key to be rotated, got %d %v %+v", status, errors, key)
+	}
+
+	// Ciphertexts of older versions stay readable and rewrap moves them to the latest version.
+	//
+	var rewrapped vaulttransit.CiphertextResult
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/rewrap/orders", vaulttransit.DecryptItem{Ciphertext: encrypted.Ciphertext, Context: vaultContext}, &rewrapped); status != http.StatusOK {
+		t.Fatalf("Expected rewrap to succeed, got %d %v", status, errors)
+	}
+	if !strings.HasPrefix(rewrapped.Ciphertext, "vault:v2:") || rewrapped.KeyVersion != 2 {
+		t.Errorf("Expected a vault:v2: ciphertext, got %+v", rewrapped)
+	}
+
+	for _, ciphertext := range []string{encrypted.Ciphertext, rewrapped.Ciphertext} {
+		var decrypted vaulttransit.PlaintextResult
+		if status, errors := call(t, server, http.MethodPost, "/v1/transit/decrypt/orders", vaulttransit.DecryptItem{Ciphertext: ciphertext, Context: vaultContext}, &decrypted); status != http.StatusOK || string(decrypted.Plaintext) != "secret" {
+			t.Errorf("Expected %q to decrypt, got %d %v %q", ciphertext, status, errors, decrypted.Plaintext)
+		}
+	}
+
+	if status, _ := call(t, server, http.MethodPost, "/v1/transit/decrypt/orders", vaulttransit.DecryptItem{Ciphertext: encrypted.Ciphertext}, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected decryption without the context to fail, got %d", status)
+	}
+
+	// Creating an existing key leaves it untouched.
+	//
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/keys/orders", nil, &key); status != http.StatusOK || key.LatestVersion != 2 {
+		t.Errorf("Expected the existing key, got %d %v %+v", status, errors, key)
+	}
+}
+
+// gatedKeyStore - key store answering the first lookups only once all of them were made, so every client finds the
+// key missing before any creates it.
+type gatedKeyStore struct {
+	kms.KeyStore
+	lock    sync.Mutex
+	waiting int
+	open    chan struct{}
+}
+
+func (gK *gatedKeyStore) GetKey(ctx context.Context, keyID string) (kms.Key, error) {
+	key, err := gK.KeyStore.GetKey(ctx, keyID)
+
+	gK.lock.Lock()
+	if gK.waiting > 0 {
+		if gK.waiting--; gK.waiting == 0 {
+			close(gK.open)
+		}
+	}
+	gK.lock.Unlock()
+
+	<-gK.open
+	return key, err
+}
+
+func TestServer_ConcurrentCreate(t *testing.T) {
+	const clients = 8
+	keyStore := &gatedKeyStore{KeyStore: kms.NewKeyStoreFile(t.TempDir(), nil), waiting: clients, open: make(chan struct{})}
+	service := kmstest.OpenService(t, keyStore, nil)
+	server := vaulttransit.NewServer(service, nil, "TEST", nil)
+
+	// Vault clients creating the same key together all get it, and a single key is created behind its alias.
+	//
+	statuses := make(chan int, clients)
+	var waitGroup sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			response, err := server.App().Test(httptest.NewRequest(http.MethodPost, "/v1/transit/keys/orders", nil), -1)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			response.Body.Close()
+			statuses <- response.StatusCode
+		}()
+	}
+	waitGroup.Wait()
+	close(statuses)
+
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("Expected every creation to succeed, got %d", status)
+		}
+	}
+	keys, err := service.KeyStore().ListKeys(context.Background())
+	if err != nil || len(keys) != 1 {
+		t.Errorf("Expected 1 key, got %d %v", len(keys), err)
+	}
+}
+
+func TestServer_Batch(t *testing.T) {
+	server := newServer(t)
+	call(t, server, http.MethodPost, "/v1/transit/keys/batch", nil, nil)
+
+	var encrypted vaulttransit.BatchResponse[vaulttransit.CiphertextResult]
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/encrypt/batch", vaulttransit.EncryptRequest{
+		BatchInput: []vaulttransit.EncryptItem{{Plaintext: []byte("one")}, {Plaintext: []byte("two")}},
+	}, &encrypted); status != http.StatusOK || len(encrypted.BatchResults) != 2 {
+		t.Fatalf("Expected batch encryption to succeed, got %d %v %+v", status, errors, encrypted)
+	}
+
+	// A failed item fails the request, the other items are still answered.
+	//
+	var decrypted vaulttransit.BatchResponse[vaulttransit.PlaintextResult]
+	status, _ := call(t, server, http.MethodPost, "/v1/transit/decrypt/batch", vaulttransit.DecryptRequest{
+		BatchInput: []vaulttransit.DecryptItem{
+			{Ciphertext: encrypted.BatchResults[0].Ciphertext},
+			{Ciphertext: "vault:v1:garbage"},
+			{Ciphertext: encrypted.BatchResults[1].Ciphertext},
+		},
+	}, &decrypted)
+	if status != http.StatusBadRequest || len(decrypted.BatchResults) != 3 {
+		t.Fatalf("Expected a partially failed batch, got %d %+v", status, decrypted)
+	}
+	if string(decrypted.BatchResults[0].Plaintext) != "one" || decrypted.BatchResults[1].Error == "" || string(decrypted.BatchResults[2].Plaintext) != "two" {
+		t.Errorf("Unexpected batch results %+v", decrypted.BatchResults)
+	}
+}
+
+func TestServer_DataKey(t *testing.T) {
+	server := newServer(t)
+	call(t, server, http.MethodPost, "/v1/transit/keys/data", nil, nil)
+
+	var dataKey vaulttransit.DataKeyResponse
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/datakey/plaintext/data", vaulttransit.DataKeyRequest{Bits: 128}, &dataKey); status != http.StatusOK || len(dataKey.Plaintext) != 16 {
+		t.Fatalf("Expected a 16 byte data key, got %d %v %+v", status, errors, dataKey)
+	}
+
+	var decrypted vaulttransit.PlaintextResult
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/decrypt/data", vaulttransit.DecryptItem{Ciphertext: dataKey.Ciphertext}, &decrypted); status != http.StatusOK || !bytes.Equal(decrypted.Plaintext, dataKey.Plaintext) {
+		t.Errorf("Expected data key to decrypt, got %d %v", status, errors)
+	}
+
+	var wrapped vaulttransit.DataKeyResponse
+	if status, errors := call(t, server, http.MethodPost, "/v1/transit/datakey/wrapped/data", nil, &wrapped); status != http.StatusOK || wrapped.Plaintext != nil || wrapped.Ciphertext == "" {
+		t.Errorf("Expected only a wrapped data key, got %d %v %+v", status, errors, wrapped)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	server := newServer(t)
+	call(t, server, http.MethodPost, "/v1/transit/keys/first", nil, nil)
+	call(t, server, http.MethodPost, "/v1/transit/keys/second", nil, nil)
+
+	var encrypted vaulttransit.CiphertextResult
+	call(t, server, http.MethodPost, "/v1/transit/encrypt/first", vaulttransit.EncryptItem{Plaintext: []byte("secret")}, &encrypted)
+	envelope := strings.TrimPrefix(encrypted.Ciphertext, "vault:v1:")
+
+	scenarios := []struct {
+		name           string
+		method         string
+		path           string
+		body           any
+		expectedStatus int
+	}{
+		{"unknown key", http.MethodGet, "/v1/transit/keys/missing", nil, http.StatusNotFound},
+		{"unsupported key type", http.MethodPost, "/v1/transit/keys/signing", vaulttransit.KeyCreateRequest{Type: "ecdsa-p256"}, http.StatusBadRequest},
+		{"encrypt with unknown key", http.MethodPost, "/v1/transit/encrypt/missing", vaulttransit.EncryptItem{Plaintext: []byte("x")}, http.StatusNotFound},
+		{"encrypt with older version", http.MethodPost, "/v1/transit/encrypt/first", vaulttransit.EncryptItem{Plaintext: []byte("x"), KeyVersion: 2}, http.StatusBadRequest},
+		{"missing prefix", http.MethodPost, "/v1/transit/decrypt/first", vaulttransit.DecryptItem{Ciphertext: envelope}, http.StatusBadRequest},
+		{"mismatched version", http.MethodPost, "/v1/transit/decrypt/first", vaulttransit.DecryptItem{Ciphertext: "vault:v2:" + envelope}, http.StatusBadRequest},
+		{"other key", http.MethodPost, "/v1/transit/decrypt/second", vaulttransit.DecryptItem{Ciphertext: encrypted.Ciphertext}, http.StatusBadRequest},
+		{"data key bits", http.MethodPost, "/v1/transit/datakey/plaintext/first", vaulttransit.DataKeyRequest{Bits: 512}, http.StatusBadRequest},
+		{"data key type", http.MethodPost, "/v1/transit/datakey/exported/first", nil, http.StatusBadRequest},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			status, errors := call(t, server, scenario.method, scenario.path, scenario.body, nil)
+			if status != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, status)
+			}
+			if len(errors) == 0 {
+				t.Errorf("Expected errors in the response")
+			}
+		})
+	}
+}